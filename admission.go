@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// printWebhookConfigurations prints how many admission webhooks are registered in the cluster
+func printWebhookConfigurations(clientset *kubernetes.Clientset) {
+	mutating, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error listing mutating webhook configurations: %v\n", err)
+		return
+	}
+	validating, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error listing validating webhook configurations: %v\n", err)
+		return
+	}
+
+	mutatingWebhooks, validatingWebhooks := 0, 0
+	for _, configuration := range mutating.Items {
+		mutatingWebhooks += len(configuration.Webhooks)
+	}
+	for _, configuration := range validating.Items {
+		validatingWebhooks += len(configuration.Webhooks)
+	}
+	fmt.Printf("Found %d mutating and %d validating admission webhooks\n", mutatingWebhooks, validatingWebhooks)
+}
+
+// benchmarkCreateUpdate creates and then updates a ConfigMap per iteration in the namespace,
+// recording both under "create ConfigMap (<variant>)" and "update ConfigMap (<variant>)". With
+// dryRun, only the create is issued as a dry run, which runs admission but skips storage.
+func benchmarkCreateUpdate(clientset *kubernetes.Clientset, namespace, variant, seedSet string, extraLabels map[string]string, dryRun bool, iterations int, results *BenchmarkResults) {
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	createName := fmt.Sprintf("create ConfigMap (%s)", variant)
+	updateName := fmt.Sprintf("update ConfigMap (%s)", variant)
+
+	createOptions := metav1.CreateOptions{}
+	if dryRun {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(createName) {
+			continue
+		}
+		objectLabels := seedLabels(seedSet)
+		for key, value := range extraLabels {
+			objectLabels[key] = value
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+				Labels: objectLabels,
+			},
+			Data: map[string]string{"iteration": fmt.Sprintf("%d", i)},
+		}
+
+		startTime := time.Now()
+		created, err := configMaps.Create(context.TODO(), configMap, createOptions)
+		recordIteration(createName, i+1, iterations, startTime, time.Since(startTime), err, results)
+		if err != nil || dryRun {
+			results.recordOutcome(createName, err)
+			continue
+		}
+
+		created.Data["updated"] = "true"
+		startTime = time.Now()
+		_, err = configMaps.Update(context.TODO(), created, metav1.UpdateOptions{})
+		recordIteration(updateName, i+1, iterations, startTime, time.Since(startTime), err, results)
+		results.recordOutcome(createName, err)
+	}
+}
+
+// benchmarkAdmission compares creating and updating ConfigMaps in a namespace where admission
+// webhooks apply with the same operations in the seed namespace, and with dry-run creates in the
+// webhook namespace. The difference of the median latencies is reported as admission overhead.
+func benchmarkAdmission(clientset *kubernetes.Clientset, webhookNamespace, baselineNamespace string, webhookLabels map[string]string, iterations int, results *BenchmarkResults) {
+	const seedSet = "admission"
+	// Dry-run creates are named apart, as the objects of the webhook variant still exist
+	const dryRunSeedSet = "admission-dry-run"
+
+	fmt.Printf("\n--- Admission webhook overhead benchmark (webhooks in %s, baseline in %s) ---\n", webhookNamespace, baselineNamespace)
+	printWebhookConfigurations(clientset)
+
+	for _, namespace := range []string{webhookNamespace, baselineNamespace} {
+		defer func() {
+			if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+				fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+			}
+		}()
+	}
+
+	benchmarkCreateUpdate(clientset, webhookNamespace, "webhooks", seedSet, webhookLabels, false, iterations, results)
+	benchmarkCreateUpdate(clientset, webhookNamespace, "webhooks, dry-run", dryRunSeedSet, webhookLabels, true, iterations, results)
+	benchmarkCreateUpdate(clientset, baselineNamespace, "no webhooks", seedSet, nil, false, iterations, results)
+
+	stats := results.CalculateStats()
+	for _, verb := range []string{"create", "update"} {
+		withWebhooks, ok := stats[fmt.Sprintf("%s ConfigMap (webhooks)", verb)]
+		if !ok {
+			continue
+		}
+		baseline, ok := stats[fmt.Sprintf("%s ConfigMap (no webhooks)", verb)]
+		if !ok {
+			continue
+		}
+		overhead := durationMs(withWebhooks["median"] - baseline["median"])
+		fmt.Printf("Admission overhead of %s: %.1f ms\n", verb, overhead)
+		results.SetMetric(fmt.Sprintf("admission overhead %s (ms)", verb), overhead)
+	}
+}
+
+// parseLabels parses a label set such as "team=a,tier=web"
+func parseLabels(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	set, err := labels.ConvertSelectorToLabelsMap(value)
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// apiServicesResource is the resource of the aggregation layer's APIService registrations
+var apiServicesResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// aggregatedAPI is an API group version served by an extension apiserver
+type aggregatedAPI struct {
+	group     string
+	version   string
+	service   string
+	available bool
+}
+
+// findAggregatedAPIs returns all APIServices backed by a service, i.e. not served locally by
+// the kube-apiserver
+func findAggregatedAPIs(apiServices *unstructured.UnstructuredList) []aggregatedAPI {
+	var apis []aggregatedAPI
+	for _, apiService := range apiServices.Items {
+		serviceName, found, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+		if !found {
+			continue
+		}
+		serviceNamespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+		group, _, _ := unstructured.NestedString(apiService.Object, "spec", "group")
+		version, _, _ := unstructured.NestedString(apiService.Object, "spec", "version")
+
+		available := false
+		conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+		for _, condition := range conditions {
+			fields, ok := condition.(map[string]interface{})
+			if ok && fields["type"] == "Available" && fields["status"] == "True" {
+				available = true
+			}
+		}
+
+		apis = append(apis, aggregatedAPI{
+			group:     group,
+			version:   version,
+			service:   serviceNamespace + "/" + serviceName,
+			available: available,
+		})
+	}
+	return apis
+}
+
+// benchmarkAggregatedAPIs lists the registered APIServices and benchmarks a discovery request
+// through every aggregated apiserver separately, surfacing slow extension apiservers
+func benchmarkAggregatedAPIs(config *rest.Config, clientset *kubernetes.Clientset, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- Aggregation layer benchmark ---")
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating dynamic client: %v\n", err)
+		return
+	}
+
+	var apiServices *unstructured.UnstructuredList
+	runBenchmark("list APIServices", iterations, func(ctx context.Context) error {
+		list, err := dynamicClient.Resource(apiServicesResource).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		apiServices = list
+		return nil
+	}, results)
+	if apiServices == nil {
+		return
+	}
+
+	apis := findAggregatedAPIs(apiServices)
+	fmt.Printf("Found %d APIServices, %d of them aggregated\n", len(apiServices.Items), len(apis))
+
+	for _, api := range apis {
+		if !api.available {
+			fmt.Printf("Warning: aggregated API %s/%s served by %s is not available\n", api.group, api.version, api.service)
+		}
+
+		name := fmt.Sprintf("discover %s/%s (aggregated, %s)", api.group, api.version, api.service)
+		runBenchmark(name, iterations, func(ctx context.Context) error {
+			_, err := clientset.Discovery().RESTClient().Get().AbsPath("/apis", api.group, api.version).DoRaw(ctx)
+			return err
+		}, results)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// APIGroupSummary rolls up the operations against one API group version
+type APIGroupSummary struct {
+	GroupVersion     string  `json:"group_version"`
+	Operations       int     `json:"operations"`
+	Count            int     `json:"count"`
+	MedianMs         float64 `json:"median_ms"`
+	P95Ms            float64 `json:"p95_ms"`
+	SlowestOperation string  `json:"slowest_operation"`
+}
+
+// derivedSuffixes mark operations derived from other operations' samples, which are left out of
+// the roll-up so no sample is counted twice
+var derivedSuffixes = []string{" (network)", " (decode)", " (corrected)", combinedOperation("")}
+
+// operationGroupVersion returns the API group version an operation is made against, or false if
+// it is unknown or the operation is derived from another one
+func operationGroupVersion(operation string, catalog map[string]string) (string, bool) {
+	for _, suffix := range derivedSuffixes {
+		if strings.HasSuffix(operation, suffix) {
+			return "", false
+		}
+	}
+
+	// Discovery of a single group version names it in brackets
+	if groupVersion, ok := strings.CutPrefix(operation, "discovery resources ["); ok {
+		return strings.TrimSuffix(groupVersion, "]"), true
+	}
+
+	// Strip the namespace or cluster-wide marker of namespaced operations
+	base := strings.TrimSuffix(operation, " (cluster-wide)")
+	if i := strings.LastIndex(base, " ["); i >= 0 && strings.HasSuffix(base, "]") {
+		base = base[:i]
+	}
+	groupVersion, ok := catalog[base]
+	if !ok || groupVersion == "-" {
+		return "", false
+	}
+	return groupVersion, true
+}
+
+// displayGroupVersion names the core group explicitly
+func displayGroupVersion(groupVersion string) string {
+	if !strings.Contains(groupVersion, "/") {
+		return "core/" + groupVersion
+	}
+	return groupVersion
+}
+
+// APIGroupSummaries aggregates the samples of all operations per API group version, in group
+// version order
+func (br *BenchmarkResults) APIGroupSummaries() []APIGroupSummary {
+	catalog := make(map[string]string)
+	for _, op := range operationCatalog() {
+		catalog[op.name] = op.groupVersion
+	}
+
+	stats := br.CalculateStats()
+	combined := make(map[string]*Histogram)
+	combinedDigests := make(map[string]*TDigest)
+	summaries := make(map[string]*APIGroupSummary)
+	slowest := make(map[string]time.Duration)
+	for _, op := range sortedOperations(stats) {
+		groupVersion, ok := operationGroupVersion(op, catalog)
+		if !ok {
+			continue
+		}
+		groupVersion = displayGroupVersion(groupVersion)
+
+		summary, ok := summaries[groupVersion]
+		if !ok {
+			summary = &APIGroupSummary{GroupVersion: groupVersion}
+			summaries[groupVersion] = summary
+			combined[groupVersion] = NewHistogram()
+			combinedDigests[groupVersion] = NewTDigest()
+		}
+		summary.Operations++
+		summary.Count += br.Count(op)
+		if histogram, ok := br.Histograms[op]; ok {
+			combined[groupVersion].Merge(histogram)
+		}
+		if digest, ok := br.Digests[op]; ok {
+			combinedDigests[groupVersion].Merge(digest)
+		}
+		if stats[op]["median"] > slowest[groupVersion] {
+			slowest[groupVersion] = stats[op]["median"]
+			summary.SlowestOperation = op
+		}
+	}
+
+	rollup := make([]APIGroupSummary, 0, len(summaries))
+	for _, groupVersion := range sortedKeys(summaries) {
+		summary := summaries[groupVersion]
+		if histogram := combined[groupVersion]; histogram.Total > 0 {
+			summary.MedianMs = durationMs(histogram.Quantile(0.5))
+			summary.P95Ms = durationMs(histogram.Quantile(0.95))
+		} else if digest := combinedDigests[groupVersion]; digest.Total > 0 {
+			summary.MedianMs = durationMs(digest.Quantile(0.5))
+			summary.P95Ms = durationMs(digest.Quantile(0.95))
+		}
+		rollup = append(rollup, *summary)
+	}
+	return rollup
+}
+
+// PrintAPIGroupSummaries prints the roll-up of the operations per API group version
+func (br *BenchmarkResults) PrintAPIGroupSummaries() {
+	rollup := br.APIGroupSummaries()
+	if len(rollup) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- API Group Summary ---")
+	table := NewTable("Group Version", "Operations", "Count", "Median", "P95", "Slowest Operation")
+	for _, summary := range rollup {
+		color := ""
+		if summary.P95Ms > durationMs(slowThreshold) {
+			color = colorRed
+		}
+		table.AddCells(
+			Cell{Text: summary.GroupVersion, Color: color},
+			Cell{Text: fmt.Sprintf("%d", summary.Operations)},
+			Cell{Text: fmt.Sprintf("%d", summary.Count)},
+			Cell{Text: formatMs(summary.MedianMs)},
+			Cell{Text: formatMs(summary.P95Ms), Color: color},
+			Cell{Text: summary.SlowestOperation},
+		)
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// credentialRefreshes tracks when the credentials of an exec plugin or auth provider were
+// refreshed during the run; nil when the kubeconfig uses static credentials
+var credentialRefreshes *credentialTracker
+
+// credentialTracker detects credential refreshes by watching the Authorization header that the
+// exec plugin or auth provider sets on every request
+type credentialTracker struct {
+	mu        sync.Mutex
+	current   [sha256.Size]byte
+	seen      bool
+	refreshes []time.Time
+
+	// Number of iterations during which the credentials were refreshed
+	affected atomic.Int64
+}
+
+// trackCredentialRefreshes installs a credential tracker on configs whose credentials are
+// obtained, and possibly refreshed mid-run, by an exec plugin or auth provider
+func trackCredentialRefreshes(config *rest.Config) *credentialTracker {
+	if config.ExecProvider == nil && config.AuthProvider == nil {
+		return nil
+	}
+	tracker := &credentialTracker{}
+	// The exec and auth provider round trippers wrap the transport set here, so it sees the
+	// header they set. Refreshes of client certificates returned by exec plugins happen in the
+	// TLS handshake instead and are not detected.
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &credentialTransport{next: rt, tracker: tracker}
+	})
+	return tracker
+}
+
+// observe records the credentials of a request, noting a refresh when they changed
+func (t *credentialTracker) observe(header string) {
+	if header == "" {
+		return
+	}
+	sum := sha256.Sum256([]byte(header))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seen && sum != t.current {
+		t.refreshes = append(t.refreshes, time.Now())
+	}
+	t.current = sum
+	t.seen = true
+}
+
+// Refreshes returns the number of credential refreshes observed
+func (t *credentialTracker) Refreshes() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.refreshes)
+}
+
+// During reports whether the credentials were refreshed between start and end
+func (t *credentialTracker) During(start, end time.Time) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, refresh := range t.refreshes {
+		if !refresh.Before(start) && !refresh.After(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordIteration counts the iteration as affected when the credentials were refreshed while
+// it ran, since the request then waited for the exec plugin or auth provider
+func (t *credentialTracker) RecordIteration(start time.Time, duration time.Duration) {
+	if t.During(start, start.Add(duration)) {
+		t.affected.Add(1)
+	}
+}
+
+// SetMetrics records the number of refreshes and affected iterations as metrics
+func (t *credentialTracker) SetMetrics(results *BenchmarkResults) {
+	if t == nil {
+		return
+	}
+	results.SetMetric("credential refreshes", float64(t.Refreshes()))
+	results.SetMetric("iterations affected by re-authentication", float64(t.affected.Load()))
+}
+
+// credentialTransport passes the Authorization header of every request to the tracker
+type credentialTransport struct {
+	next    http.RoundTripper
+	tracker *credentialTracker
+}
+
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.tracker.observe(req.Header.Get("Authorization"))
+	return t.next.RoundTrip(req)
+}
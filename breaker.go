@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// skippedAfterErrors is the status of operations stopped by the circuit breaker
+const skippedAfterErrors = "SKIPPED-after-errors"
+
+// maxConsecutiveFailures is the number of consecutive failed iterations after which the
+// remaining iterations of an operation are skipped, 0 to never skip
+var maxConsecutiveFailures int
+
+// SkippedOperation is an operation whose remaining iterations were skipped by the circuit
+// breaker, e.g. because it targets a broken aggregated API
+type SkippedOperation struct {
+	Operation           string `json:"operation"`
+	Status              string `json:"status"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	SkippedIterations   int    `json:"skipped_iterations"`
+}
+
+// recordOutcome tracks the consecutive failures of the operation and trips its circuit breaker
+// once they reach maxConsecutiveFailures. Iteration loops record an outcome per iteration and
+// check skipIteration before starting the next one; multi-step iterations are tracked under
+// their end-to-end operation.
+func (br *BenchmarkResults) recordOutcome(operation string, err error) {
+	if maxConsecutiveFailures <= 0 {
+		return
+	}
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if err == nil {
+		delete(br.failureStreaks, operation)
+		return
+	}
+	br.failureStreaks[operation]++
+	if _, tripped := br.Skipped[operation]; tripped || br.failureStreaks[operation] < maxConsecutiveFailures {
+		return
+	}
+	br.Skipped[operation] = &SkippedOperation{
+		Operation:           operation,
+		Status:              skippedAfterErrors,
+		ConsecutiveFailures: br.failureStreaks[operation],
+	}
+	fmt.Printf("Warning: skipping the remaining iterations of %s after %d consecutive failures\n", operation, br.failureStreaks[operation])
+}
+
+// skipIteration reports whether the circuit breaker of the operation tripped or the run time
+// budget is exhausted, counting the iteration as skipped if so
+func (br *BenchmarkResults) skipIteration(operation string) bool {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if skipped, tripped := br.Skipped[operation]; tripped {
+		skipped.SkippedIterations++
+		return true
+	}
+	return br.skipOverBudget(operation)
+}
+
+// SkippedOperations returns the operations stopped by the circuit breaker ordered by name
+func (br *BenchmarkResults) SkippedOperations() []SkippedOperation {
+	skipped := make([]SkippedOperation, 0, len(br.Skipped))
+	for _, op := range br.Skipped {
+		skipped = append(skipped, *op)
+	}
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Operation < skipped[j].Operation
+	})
+	return skipped
+}
+
+// PrintSkipped prints the operations stopped by the circuit breaker, if any
+func (br *BenchmarkResults) PrintSkipped() {
+	skipped := br.SkippedOperations()
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Skipped Operations ---")
+	table := NewTable("Operation", "Status", "Consecutive Failures", "Skipped Iterations")
+	for _, op := range skipped {
+		table.AddCells(
+			Cell{Text: op.Operation, Color: colorRed},
+			Cell{Text: op.Status},
+			Cell{Text: fmt.Sprintf("%d", op.ConsecutiveFailures)},
+			Cell{Text: fmt.Sprintf("%d", op.SkippedIterations)})
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// runDeadline is when the run time budget set by --max-runtime is exhausted; zero without a
+// budget
+var runDeadline time.Time
+
+// budgetWarning announces the exhausted budget once
+var budgetWarning sync.Once
+
+// IncompleteOperation is an operation, or a whole benchmark, that was cut short because the run
+// time budget was exhausted
+type IncompleteOperation struct {
+	Operation         string `json:"operation"`
+	SkippedIterations int    `json:"skipped_iterations,omitempty"`
+	NotStarted        bool   `json:"not_started,omitempty"`
+}
+
+// budgetExhausted reports whether the run time budget is exhausted. In-flight iterations are
+// allowed to complete, but no new ones are started.
+func budgetExhausted() bool {
+	if runDeadline.IsZero() || time.Now().Before(runDeadline) {
+		return false
+	}
+	budgetWarning.Do(func() {
+		fmt.Println("Warning: run time budget exhausted, skipping the remaining operations")
+	})
+	return true
+}
+
+// skipOverBudget counts an iteration of the operation as skipped if the run time budget is
+// exhausted. The caller must hold br.mu.
+func (br *BenchmarkResults) skipOverBudget(operation string) bool {
+	if !budgetExhausted() {
+		return false
+	}
+	incomplete, ok := br.Incomplete[operation]
+	if !ok {
+		incomplete = &IncompleteOperation{Operation: operation}
+		br.Incomplete[operation] = incomplete
+	}
+	incomplete.SkippedIterations++
+	return true
+}
+
+// withinBudget reports whether the benchmark may start, recording it as not started if the
+// run time budget is exhausted
+func (br *BenchmarkResults) withinBudget(benchmark string) bool {
+	if !budgetExhausted() {
+		return true
+	}
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	br.Incomplete[benchmark] = &IncompleteOperation{Operation: benchmark, NotStarted: true}
+	return false
+}
+
+// IncompleteOperations returns the operations cut short by the run time budget ordered by name
+func (br *BenchmarkResults) IncompleteOperations() []IncompleteOperation {
+	incomplete := make([]IncompleteOperation, 0, len(br.Incomplete))
+	for _, op := range br.Incomplete {
+		incomplete = append(incomplete, *op)
+	}
+	sort.Slice(incomplete, func(i, j int) bool {
+		return incomplete[i].Operation < incomplete[j].Operation
+	})
+	return incomplete
+}
+
+// PrintIncomplete prints the operations cut short by the run time budget, if any
+func (br *BenchmarkResults) PrintIncomplete() {
+	incomplete := br.IncompleteOperations()
+	if len(incomplete) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Incomplete Operations (run time budget exhausted) ---")
+	table := NewTable("Operation", "Skipped")
+	for _, op := range incomplete {
+		skipped := fmt.Sprintf("%d iterations", op.SkippedIterations)
+		if op.NotStarted {
+			skipped = "not started"
+		}
+		table.AddCells(Cell{Text: op.Operation, Color: colorRed}, Cell{Text: skipped})
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// benchmarkBulkCreate creates count small ConfigMaps as fast as the client allows using the
+// given number of workers and reports the sustained creates per second together with the
+// latency distribution of the individual creates. The client-side rate limit applies, so raise
+// --qps and --burst to measure the apiserver rather than the client.
+func benchmarkBulkCreate(clientset *kubernetes.Clientset, namespace string, count, workers int, results *BenchmarkResults) {
+	const seedSet = "bulk-create"
+	name := fmt.Sprintf("bulk create ConfigMap (%d workers)", workers)
+
+	fmt.Printf("\n--- Bulk create benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	fmt.Printf("Creating %d ConfigMaps with %d workers...\n", count, workers)
+	elapsed := runConcurrently(name, count, workers, func(ctx context.Context, i int) error {
+		_, err := configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+				Labels: seedLabels(seedSet),
+			},
+			Data: map[string]string{"index": fmt.Sprintf("%d", i)},
+		}, metav1.CreateOptions{})
+		return err
+	}, results)
+
+	created := results.Count(name)
+	throughput := float64(created) / elapsed.Seconds()
+	fmt.Printf("Created %d of %d ConfigMaps in %v (%.1f creates/s)\n", created, count, elapsed, throughput)
+	results.SetMetric(fmt.Sprintf("bulk create throughput %d workers (creates/s)", workers), throughput)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// cancelObjectSize is the payload of every ConfigMap seeded for the cancellation benchmark, so
+// the LIST response takes long enough to be cancelled while it is being received
+const cancelObjectSize = 10 * 1024
+
+// parseCancelPoints parses a comma-separated list of positive durations after which a LIST is cancelled
+func parseCancelPoints(value string) ([]time.Duration, error) {
+	var points []time.Duration
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		point, err := time.ParseDuration(field)
+		if err != nil || point <= 0 {
+			return nil, fmt.Errorf("invalid cancellation point %q", field)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// getNamespaceTraced reads the namespace and reports whether the request reused a pooled connection
+func getNamespaceTraced(clientset *kubernetes.Clientset, namespace string) (bool, error) {
+	var reused bool
+	ctx := httptrace.WithClientTrace(context.TODO(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
+		},
+	})
+	_, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	return reused, err
+}
+
+// benchmarkCancellation seeds large ConfigMaps, then per iteration and cancellation point starts
+// a LIST of all of them and cancels it after the given time. It records how long the LIST call
+// takes to return after the cancellation, which is how quickly the client frees the request,
+// and the latency of a GET issued right afterwards compared to one issued before the LIST. The
+// share of follow-up GETs that could reuse a pooled connection is recorded as a metric, as
+// cancelling a request on HTTP/1.1 closes its connection.
+func benchmarkCancellation(clientset *kubernetes.Clientset, namespace string, objects int, points []time.Duration, iterations int, results *BenchmarkResults) {
+	const seedSet = "cancel"
+
+	fmt.Printf("\n--- Request cancellation benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	fmt.Printf("Seeding %d ConfigMaps of %dKB\n", objects, cancelObjectSize/1024)
+	if _, err := seedConfigMaps(clientset, namespace, seedSet, objects, cancelObjectSize); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	for _, point := range points {
+		returnName := fmt.Sprintf("LIST cancelled after %v (time to return)", point)
+		beforeName := fmt.Sprintf("GET Namespace (before LIST cancelled after %v)", point)
+		afterName := fmt.Sprintf("GET Namespace (after LIST cancelled after %v)", point)
+
+		completed, cancelled, reused := 0, 0, 0
+		for i := 0; i < iterations; i++ {
+			if results.skipIteration(returnName) {
+				continue
+			}
+			startTime := time.Now()
+			_, err := getNamespaceTraced(clientset, namespace)
+			recordIteration(beforeName, i+1, iterations, startTime, time.Since(startTime), err, results)
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			var cancelledAt atomic.Pointer[time.Time]
+			timer := time.AfterFunc(point, func() {
+				now := time.Now()
+				cancelledAt.Store(&now)
+				cancel()
+			})
+			_, err = configMaps.List(ctx, metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+			returnedAt := time.Now()
+			timer.Stop()
+			cancel()
+
+			if err == nil || cancelledAt.Load() == nil {
+				// The LIST finished or failed before it could be cancelled
+				completed++
+				if err != nil {
+					recordIteration(returnName, i+1, iterations, returnedAt, 0, err, results)
+				}
+				results.recordOutcome(returnName, err)
+				continue
+			}
+			recordIteration(returnName, i+1, iterations, *cancelledAt.Load(), returnedAt.Sub(*cancelledAt.Load()), nil, results)
+			cancelled++
+
+			startTime = time.Now()
+			wasReused, err := getNamespaceTraced(clientset, namespace)
+			recordIteration(afterName, i+1, iterations, startTime, time.Since(startTime), err, results)
+			results.recordOutcome(returnName, err)
+			if err == nil && wasReused {
+				reused++
+			}
+		}
+
+		if completed > 0 {
+			fmt.Printf("%d of %d LISTs completed before being cancelled after %v\n", completed, completed+cancelled, point)
+		}
+		if cancelled > 0 {
+			results.SetMetric(fmt.Sprintf("connection reused after LIST cancelled after %v (%%)", point), float64(reused)/float64(cancelled)*100)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpoint is the on-disk state of an interrupted run, from which it can be resumed. Operations
+// cut short by the run time budget are not part of it, as the resumed run retries them.
+type checkpoint struct {
+	RunID         string                            `json:"run_id"`
+	StartTime     time.Time                         `json:"start_time"`
+	Histograms    map[string]*Histogram             `json:"histograms"`
+	Digests       map[string]*TDigest               `json:"digests,omitempty"`
+	Results       map[string][]time.Duration        `json:"results"`
+	Namespaces    map[string]map[string]bool        `json:"namespaces"`
+	Sizes         map[string]*SizeStats             `json:"sizes"`
+	Timeline      map[string][]TimelinePoint        `json:"timeline"`
+	Events        []TimelineEvent                   `json:"events,omitempty"`
+	Metrics       map[string]float64                `json:"metrics"`
+	Errors        map[string]*ErrorSummary          `json:"errors"`
+	Timeouts      map[string]*Histogram             `json:"timeouts,omitempty"`
+	Throttled     map[string]time.Duration          `json:"throttled,omitempty"`
+	Connections   map[string]*ConnectionCounts      `json:"connections,omitempty"`
+	Skipped       map[string]*SkippedOperation      `json:"skipped,omitempty"`
+	Workers       map[string]map[int]*WorkerSamples `json:"workers,omitempty"`
+	Ramp          []RampPoint                       `json:"ramp,omitempty"`
+	Saturation    *SaturationAnalysis               `json:"saturation,omitempty"`
+	PageSizes     []PageSizeRecommendation          `json:"page_sizes,omitempty"`
+	ManagedFields []ManagedFieldsImpact             `json:"managed_fields,omitempty"`
+}
+
+// WriteCheckpoint atomically writes the samples collected so far to path
+func (br *BenchmarkResults) WriteCheckpoint(path string, metadata RunMetadata) error {
+	br.mu.Lock()
+	data, err := json.Marshal(checkpoint{
+		RunID:         metadata.RunID,
+		StartTime:     metadata.StartTime,
+		Histograms:    br.Histograms,
+		Digests:       br.Digests,
+		Results:       br.Results,
+		Namespaces:    br.Namespaces,
+		Sizes:         br.Sizes,
+		Timeline:      br.Timeline,
+		Events:        br.Events,
+		Metrics:       br.Metrics,
+		Errors:        br.Errors,
+		Timeouts:      br.Timeouts,
+		Throttled:     br.Throttled,
+		Connections:   br.Connections,
+		Skipped:       br.Skipped,
+		Workers:       br.Workers,
+		Ramp:          br.Ramp,
+		Saturation:    br.Saturation,
+		PageSizes:     br.PageSizes,
+		ManagedFields: br.ManagedFields,
+	})
+	br.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding checkpoint: %v", err)
+	}
+
+	// Write to a temporary file first, so an interruption never leaves a truncated checkpoint
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error creating checkpoint: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint restores the samples of an interrupted run from path and remembers how many
+// iterations of every operation already completed, so that they are skipped. It returns the run
+// ID and start time of the interrupted run.
+func (br *BenchmarkResults) LoadCheckpoint(path string) (string, time.Time, error) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	// Decoding into the existing maps restores the samples in place
+	state := checkpoint{
+		Histograms:  br.Histograms,
+		Digests:     br.Digests,
+		Results:     br.Results,
+		Namespaces:  br.Namespaces,
+		Sizes:       br.Sizes,
+		Timeline:    br.Timeline,
+		Metrics:     br.Metrics,
+		Errors:      br.Errors,
+		Timeouts:    br.Timeouts,
+		Throttled:   br.Throttled,
+		Connections: br.Connections,
+		Skipped:     br.Skipped,
+		Workers:     br.Workers,
+	}
+	if err := readJSONFile(path, &state); err != nil {
+		return "", time.Time{}, err
+	}
+	br.Events = state.Events
+	br.Ramp = state.Ramp
+	br.Saturation = state.Saturation
+	br.PageSizes = state.PageSizes
+	br.ManagedFields = state.ManagedFields
+
+	for op, histogram := range br.Histograms {
+		br.Resumed[op] += int(histogram.Total)
+	}
+	for op, digest := range br.Digests {
+		br.Resumed[op] += int(digest.Total)
+	}
+	for _, summary := range br.Errors {
+		br.Resumed[summary.Operation] += summary.Count
+	}
+	// The breaker of skipped operations stays tripped, so their skipped iterations are not counted twice
+	for op, skipped := range br.Skipped {
+		br.Resumed[op] += skipped.SkippedIterations
+	}
+	return state.RunID, state.StartTime, nil
+}
+
+// replaceRestored replaces the entry with the same key as entry, restored from a checkpoint by a
+// benchmark that runs again when resuming, or appends entry
+func replaceRestored[T any, K comparable](entries []T, entry T, key func(T) K) []T {
+	for i := range entries {
+		if key(entries[i]) == key(entry) {
+			entries[i] = entry
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
+
+// ResumedIterations returns how many iterations of the operation were restored from a checkpoint
+func (br *BenchmarkResults) ResumedIterations(operation string) int {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	return br.Resumed[operation]
+}
+
+// checkpointPeriodically writes a checkpoint to path every interval until stop is closed
+func checkpointPeriodically(path string, interval time.Duration, metadata RunMetadata, br *BenchmarkResults, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := br.WriteCheckpoint(path, metadata); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
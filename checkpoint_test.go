@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	br := NewBenchmarkResults()
+	for i := 1; i <= 3; i++ {
+		br.Add("list pods", time.Duration(i)*time.Millisecond)
+	}
+	br.AddError("list pods", fmt.Errorf("connection refused"), time.Now())
+	br.AddTimeout("get nodes", context.DeadlineExceeded, 5*time.Second)
+	br.AddThrottled("list pods", 200*time.Millisecond)
+	br.AddConnections("list pods", ConnectionCounts{New: 1, Reused: 2})
+	br.AddWorkerSample("ramp", 1, 3*time.Millisecond, nil)
+	br.Skipped["list flaky"] = &SkippedOperation{Operation: "list flaky", Status: skippedAfterErrors, ConsecutiveFailures: 5, SkippedIterations: 4}
+	br.Incomplete["watch benchmark"] = &IncompleteOperation{Operation: "watch benchmark", NotStarted: true}
+	br.Ramp = []RampPoint{{Concurrency: 4, Requests: 100, P50Ms: 2}}
+	br.PageSizes = []PageSizeRecommendation{{Resource: "pods", Limit: 500}}
+	br.ManagedFields = []ManagedFieldsImpact{{Operation: "list pods (managedFields)", Bytes: 1000}}
+
+	path := filepath.Join(t.TempDir(), "bench.checkpoint")
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := br.WriteCheckpoint(path, RunMetadata{RunID: "run-1", StartTime: startTime}); err != nil {
+		t.Fatalf("writing checkpoint: %v", err)
+	}
+
+	restored := NewBenchmarkResults()
+	runID, restoredStart, err := restored.LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loading checkpoint: %v", err)
+	}
+	if runID != "run-1" || !restoredStart.Equal(startTime) {
+		t.Errorf("run ID, start time = %q, %v, want run-1, %v", runID, restoredStart, startTime)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "samples", got: restored.Count("list pods"), want: 3},
+		{name: "resumed iterations", got: restored.ResumedIterations("list pods"), want: 4},
+		{name: "resumed iterations of skipped operation", got: restored.ResumedIterations("list flaky"), want: 4},
+		{name: "timeouts", got: restored.Timeouts["get nodes"].Total, want: int64(1)},
+		{name: "throttled", got: restored.Throttled["list pods"], want: 200 * time.Millisecond},
+		{name: "connections", got: restored.ConnectionCounts("list pods"), want: ConnectionCounts{New: 1, Reused: 2}},
+		{name: "workers", got: len(restored.Workers["ramp"][1].Durations), want: 1},
+		{name: "skipped", got: restored.Skipped["list flaky"].SkippedIterations, want: 4},
+		{name: "incomplete not restored", got: len(restored.Incomplete), want: 0},
+		{name: "ramp", got: len(restored.Ramp), want: 1},
+		{name: "page sizes", got: len(restored.PageSizes), want: 1},
+		{name: "managedFields", got: len(restored.ManagedFields), want: 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestReplaceRestored(t *testing.T) {
+	concurrency := func(p RampPoint) int { return p.Concurrency }
+	restored := []RampPoint{{Concurrency: 1, P50Ms: 1}, {Concurrency: 4, P50Ms: 2}}
+
+	tests := []struct {
+		name  string
+		entry RampPoint
+		want  []RampPoint
+	}{
+		{
+			name:  "replaces the restored entry",
+			entry: RampPoint{Concurrency: 4, P50Ms: 3},
+			want:  []RampPoint{{Concurrency: 1, P50Ms: 1}, {Concurrency: 4, P50Ms: 3}},
+		},
+		{
+			name:  "appends a new entry",
+			entry: RampPoint{Concurrency: 8, P50Ms: 5},
+			want:  []RampPoint{{Concurrency: 1, P50Ms: 1}, {Concurrency: 4, P50Ms: 2}, {Concurrency: 8, P50Ms: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := replaceRestored(append([]RampPoint(nil), restored...), tt.entry, concurrency)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("replaceRestored() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
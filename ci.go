@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// exitBenchmarkFailed is the exit status of strict runs with failed iterations or regressions,
+// distinguishing them from invalid settings and setup errors, which exit with 1
+const exitBenchmarkFailed = 2
+
+// ciSettings are applied by --ci: quiet output, the run artifacts and a JUnit report in
+// k8s-api-bench-results, a failing exit status on errors or regressions against --baseline, and
+// no run at all against a degraded cluster
+var ciSettings = map[string]interface{}{
+	"summary-top":  float64(20),
+	"no-color":     true,
+	"out-dir":      "k8s-api-bench-results",
+	"junit-output": "k8s-api-bench-results/junit.xml",
+	"strict":       true,
+	"health-gate":  healthGateRefuse,
+}
+
+// findRegressions returns the operations of current whose median is slower than in baseline by
+// more than threshold percent, with their change in percent
+func findRegressions(baseline, current Summary, threshold float64) map[string]float64 {
+	baselineMedians := make(map[string]float64)
+	for _, op := range baseline.Operations {
+		baselineMedians[op.Operation] = op.MedianMs
+	}
+	regressions := make(map[string]float64)
+	for _, op := range current.Operations {
+		old, ok := baselineMedians[op.Operation]
+		if !ok {
+			continue
+		}
+		if change := percentChange(old, op.MedianMs); change > threshold {
+			regressions[op.Operation] = change
+		}
+	}
+	return regressions
+}
+
+// junitTestSuite is the root element of a JUnit XML report
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      float64         `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is an operation of the run, its time being the median latency in seconds
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure describes why an operation failed: errors during the run or a regression
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// operationResult is the outcome of an operation for test reports, failed when its iterations
+// failed or its median regressed against the baseline
+type operationResult struct {
+	name     string
+	medianMs float64
+	p95Ms    float64
+	failure  string
+	details  []string
+}
+
+// operationResults returns the outcome of every operation of the summary, followed by the
+// operations whose iterations all failed, which have no statistics. Operations stopped by the
+// circuit breaker have failed iterations, so they are always failures.
+func operationResults(summary Summary, regressions map[string]float64) []operationResult {
+	errorCounts := make(map[string]int)
+	for _, e := range summary.Errors {
+		errorCounts[e.Operation] += e.Count
+	}
+
+	skippedOps := make(map[string]SkippedOperation)
+	for _, op := range summary.Skipped {
+		skippedOps[op.Operation] = op
+	}
+
+	var results []operationResult
+	seen := make(map[string]bool)
+	add := func(result operationResult) {
+		seen[result.name] = true
+		var reasons []string
+		if change, regressed := regressions[result.name]; regressed {
+			reasons = append(reasons, fmt.Sprintf("median regressed by %.1f%% against the baseline", change))
+		}
+		if count := errorCounts[result.name]; count > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d iterations failed", count))
+			for _, e := range summary.Errors {
+				if e.Operation == result.name {
+					result.details = append(result.details, fmt.Sprintf("%s (%dx): %s", e.Class, e.Count, e.Message))
+				}
+			}
+		}
+		if skipped, ok := skippedOps[result.name]; ok {
+			reasons = append(reasons, fmt.Sprintf("%s (%d iterations not run)", skipped.Status, skipped.SkippedIterations))
+		}
+		result.failure = strings.Join(reasons, "; ")
+		results = append(results, result)
+	}
+	for _, op := range summary.Operations {
+		add(operationResult{name: op.Operation, medianMs: op.MedianMs, p95Ms: op.P95Ms})
+	}
+	var failedOnly []string
+	for name := range errorCounts {
+		if !seen[name] {
+			failedOnly = append(failedOnly, name)
+		}
+	}
+	sort.Strings(failedOnly)
+	for _, name := range failedOnly {
+		add(operationResult{name: name})
+	}
+	return results
+}
+
+// writeJUnit writes every operation of the summary as a test case to path. Operations with
+// failed iterations or a regression against the baseline are reported as failures.
+func writeJUnit(path string, summary Summary, regressions map[string]float64) error {
+	suite := junitTestSuite{
+		Name: "k8s-api-bench",
+		Time: summary.Metadata.EndTime.Sub(summary.Metadata.StartTime).Seconds(),
+	}
+	for _, result := range operationResults(summary, regressions) {
+		testCase := junitTestCase{Name: result.name, ClassName: "k8s-api-bench", Time: result.medianMs / 1000}
+		if result.failure != "" {
+			testCase.Failure = &junitFailure{Message: result.failure}
+			for _, detail := range result.details {
+				testCase.Failure.Text += detail + "\n"
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JUnit report: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating JUnit report directory: %v", err)
+	}
+	if err := os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0o644); err != nil {
+		return fmt.Errorf("error writing JUnit report: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestOperationResultsFailure(t *testing.T) {
+	summary := Summary{
+		Operations: []OperationSummary{
+			{Operation: "list pods"},
+			{Operation: "list nodes"},
+			{Operation: "list flaky"},
+			{Operation: "list broken"},
+		},
+		Errors: []ErrorSummary{
+			{Operation: "list flaky", Class: "timeout", Count: 3, Message: "context deadline exceeded"},
+			{Operation: "list broken", Class: "server error", Count: 5, Message: "service unavailable"},
+			{Operation: "list gone", Class: "not found", Count: 2, Message: "not found"},
+		},
+		Skipped: []SkippedOperation{
+			{Operation: "list nodes", Status: skippedAfterErrors, SkippedIterations: 4},
+			{Operation: "list broken", Status: skippedAfterErrors, SkippedIterations: 5},
+		},
+	}
+	regressions := map[string]float64{"list flaky": 25, "list broken": 50}
+
+	want := map[string]string{
+		"list pods":   "",
+		"list nodes":  "SKIPPED-after-errors (4 iterations not run)",
+		"list flaky":  "median regressed by 25.0% against the baseline; 3 iterations failed",
+		"list broken": "median regressed by 50.0% against the baseline; 5 iterations failed; SKIPPED-after-errors (5 iterations not run)",
+		"list gone":   "2 iterations failed",
+	}
+	results := operationResults(summary, regressions)
+	if len(results) != len(want) {
+		t.Fatalf("operationResults() returned %d results, want %d", len(results), len(want))
+	}
+	for _, result := range results {
+		if result.failure != want[result.name] {
+			t.Errorf("failure of %s = %q, want %q", result.name, result.failure, want[result.name])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+// leftoverResources returns all resources that can be listed and deleted, preferring one
+// version per group
+func leftoverResources(discoveryClient discovery.DiscoveryInterface) ([]schema.GroupVersionResource, error) {
+	lists, err := discoveryClient.ServerPreferredResources()
+	if err != nil && len(lists) == 0 {
+		return nil, err
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, lists)
+
+	var resources []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			resources = append(resources, gv.WithResource(resource.Name))
+		}
+	}
+	return resources, nil
+}
+
+// runCleanup implements the cleanup subcommand, which deletes objects left behind by crashed
+// runs. Persisted results are kept unless -include-results is given.
+func runCleanup(args []string) int {
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	kubeconfig := fs.String("kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Path to the kubeconfig file")
+	namespace := fs.String("n", metav1.NamespaceAll, "Only clean up this namespace (default: all namespaces and cluster-scoped objects)")
+	dryRun := fs.Bool("dry-run", false, "Only print the objects that would be deleted")
+	includeResults := fs.Bool("include-results", false, "Also delete run summaries persisted with --results-configmap-namespace")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s cleanup [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		fmt.Printf("Error building kubeconfig: %v\n", err)
+		return 1
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating discovery client: %v\n", err)
+		return 1
+	}
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating metadata client: %v\n", err)
+		return 1
+	}
+
+	resources, err := leftoverResources(discoveryClient)
+	if err != nil {
+		fmt.Printf("Error discovering resources: %v\n", err)
+		return 1
+	}
+
+	selector := fmt.Sprintf("%s=%s", createdByLabel, createdByValue)
+	if !*includeResults {
+		selector += fmt.Sprintf(",%s!=true", resultLabel)
+	}
+
+	deleted, failed := 0, 0
+	for _, resource := range resources {
+		list, err := metadataClient.Resource(resource).Namespace(*namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			// Cluster-scoped resources cannot be listed in a namespace, and some are not readable
+			continue
+		}
+		for _, item := range list.Items {
+			description := fmt.Sprintf("%s %s", resource.GroupResource(), item.Name)
+			if item.Namespace != "" {
+				description = fmt.Sprintf("%s %s/%s", resource.GroupResource(), item.Namespace, item.Name)
+			}
+			if *dryRun {
+				fmt.Printf("Would delete %s\n", description)
+				deleted++
+				continue
+			}
+			err := metadataClient.Resource(resource).Namespace(item.Namespace).Delete(context.TODO(), item.Name, metav1.DeleteOptions{})
+			if err != nil {
+				fmt.Printf("Error deleting %s: %v\n", description, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Deleted %s\n", description)
+			deleted++
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("%d leftover objects found\n", deleted)
+		return 0
+	}
+	fmt.Printf("%d leftover objects deleted, %d failed\n", deleted, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
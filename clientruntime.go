@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// runtimeSampleInterval is how often the heap size is sampled for its peak
+	runtimeSampleInterval = time.Second
+
+	// busyClientUtilization is the share of its CPU time above which the client itself likely
+	// adds latency, as goroutines wait to be scheduled and responses wait to be decoded
+	busyClientUtilization = 0.8
+)
+
+// Runtime metrics read during the run, as estimated by the Go runtime
+const (
+	metricTotalCPU  = "/cpu/classes/total:cpu-seconds"
+	metricIdleCPU   = "/cpu/classes/idle:cpu-seconds"
+	metricGCCPU     = "/cpu/classes/gc/total:cpu-seconds"
+	metricHeapBytes = "/memory/classes/heap/objects:bytes"
+)
+
+// ClientRuntime is the Go runtime activity of the benchmarking client during the run, to verify
+// that the measured latencies are not inflated by the client itself
+type ClientRuntime struct {
+	CPUSeconds   float64 `json:"cpu_seconds"`
+	GCCPUSeconds float64 `json:"gc_cpu_seconds"`
+	// CPUUtilization is the share of the CPU time available to GOMAXPROCS the client used
+	CPUUtilization  float64 `json:"cpu_utilization"`
+	GCCycles        uint32  `json:"gc_cycles"`
+	GCPauseTotalMs  float64 `json:"gc_pause_total_ms"`
+	GCPauseMaxMs    float64 `json:"gc_pause_max_ms"`
+	HeapGrowthBytes int64   `json:"heap_growth_bytes"`
+	PeakHeapBytes   uint64  `json:"peak_heap_bytes"`
+}
+
+// CPUPercent returns the CPU utilization in percent
+func (r *ClientRuntime) CPUPercent() float64 {
+	return r.CPUUtilization * 100
+}
+
+// runtimeMonitor observes the client's Go runtime from its start until it is stopped
+type runtimeMonitor struct {
+	startSamples []metrics.Sample
+	startMem     runtime.MemStats
+	peakHeap     atomic.Uint64
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// readRuntimeMetrics reads the CPU and heap runtime metrics
+func readRuntimeMetrics() []metrics.Sample {
+	samples := []metrics.Sample{{Name: metricTotalCPU}, {Name: metricIdleCPU}, {Name: metricGCCPU}, {Name: metricHeapBytes}}
+	metrics.Read(samples)
+	return samples
+}
+
+// runtimeValue returns the value of the named sample as float64, 0 if it is unsupported
+func runtimeValue(samples []metrics.Sample, name string) float64 {
+	for _, sample := range samples {
+		if sample.Name != name {
+			continue
+		}
+		switch sample.Value.Kind() {
+		case metrics.KindFloat64:
+			return sample.Value.Float64()
+		case metrics.KindUint64:
+			return float64(sample.Value.Uint64())
+		}
+	}
+	return 0
+}
+
+// startRuntimeMonitor starts observing the client's Go runtime, sampling the heap size in the
+// background until Stop is called
+func startRuntimeMonitor() *runtimeMonitor {
+	m := &runtimeMonitor{
+		startSamples: readRuntimeMetrics(),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	runtime.ReadMemStats(&m.startMem)
+	m.peakHeap.Store(uint64(runtimeValue(m.startSamples, metricHeapBytes)))
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(runtimeSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				heap := uint64(runtimeValue(readRuntimeMetrics(), metricHeapBytes))
+				if heap > m.peakHeap.Load() {
+					m.peakHeap.Store(heap)
+				}
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+// Stop stops observing and returns the runtime activity since the start
+func (m *runtimeMonitor) Stop() *ClientRuntime {
+	close(m.stop)
+	<-m.done
+
+	samples := readRuntimeMetrics()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	delta := func(name string) float64 {
+		return runtimeValue(samples, name) - runtimeValue(m.startSamples, name)
+	}
+	total := delta(metricTotalCPU)
+	used := total - delta(metricIdleCPU)
+	clientRuntime := &ClientRuntime{
+		CPUSeconds:      used,
+		GCCPUSeconds:    delta(metricGCCPU),
+		GCCycles:        mem.NumGC - m.startMem.NumGC,
+		GCPauseTotalMs:  float64(mem.PauseTotalNs-m.startMem.PauseTotalNs) / float64(time.Millisecond),
+		HeapGrowthBytes: int64(mem.HeapAlloc) - int64(m.startMem.HeapAlloc),
+		PeakHeapBytes:   max(m.peakHeap.Load(), mem.HeapAlloc),
+	}
+	if total > 0 {
+		clientRuntime.CPUUtilization = used / total
+	}
+	// PauseNs holds the pauses of the most recent cycles in a circular buffer
+	for cycle := max(m.startMem.NumGC, mem.NumGC-min(mem.NumGC, uint32(len(mem.PauseNs)))); cycle < mem.NumGC; cycle++ {
+		pause := float64(mem.PauseNs[cycle%uint32(len(mem.PauseNs))]) / float64(time.Millisecond)
+		clientRuntime.GCPauseMaxMs = max(clientRuntime.GCPauseMaxMs, pause)
+	}
+	return clientRuntime
+}
+
+// PrintClientRuntime prints the runtime activity of the client during the run, warning if the
+// client was busy enough to inflate the measured latencies
+func (br *BenchmarkResults) PrintClientRuntime() {
+	if br.Runtime == nil {
+		return
+	}
+	r := br.Runtime
+
+	fmt.Println("\n--- Client Runtime ---")
+	table := NewTable("Metric", "Value")
+	table.AddRow("CPU time", fmt.Sprintf("%.2fs (%.0f%% of GOMAXPROCS %d)", r.CPUSeconds, r.CPUPercent(), runtime.GOMAXPROCS(0)))
+	table.AddRow("GC CPU time", fmt.Sprintf("%.2fs", r.GCCPUSeconds))
+	table.AddRow("GC cycles", fmt.Sprintf("%d", r.GCCycles))
+	table.AddRow("GC pauses", fmt.Sprintf("%s total, %s max", formatMs(r.GCPauseTotalMs), formatMs(r.GCPauseMaxMs)))
+	growth := "+" + formatBytes(r.HeapGrowthBytes)
+	if r.HeapGrowthBytes < 0 {
+		growth = "-" + formatBytes(-r.HeapGrowthBytes)
+	}
+	table.AddRow("Heap growth", growth)
+	table.AddRow("Peak heap", formatBytes(int64(r.PeakHeapBytes)))
+	table.Render(os.Stdout)
+
+	if r.CPUUtilization >= busyClientUtilization {
+		fmt.Printf("Warning: the client used %.0f%% of its CPU time, so the measured latencies may include client-side delays; lower the concurrency or raise GOMAXPROCS\n", r.CPUPercent())
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// ClusterInfo describes the benchmarked cluster, making archived reports self-describing
+type ClusterInfo struct {
+	ServerVersion string `json:"server_version"`
+	Nodes         int    `json:"nodes"`
+	Namespaces    int    `json:"namespaces"`
+	Pods          int    `json:"pods"`
+}
+
+// countObjects counts the objects of a core resource across all namespaces using a
+// metadata-only list served from the apiserver cache
+func countObjects(clientset *kubernetes.Clientset, resource string) (int, error) {
+	body, err := clientset.CoreV1().RESTClient().Get().
+		Resource(resource).
+		SetHeader("Accept", metadataOnlyRepresentation.accept).
+		VersionedParams(&metav1.ListOptions{ResourceVersion: "0"}, scheme.ParameterCodec).
+		DoRaw(context.TODO())
+	if err != nil {
+		return 0, err
+	}
+
+	list := &metav1.PartialObjectMetadataList{}
+	if err := runtime.DecodeInto(metadataOnlyRepresentation.decoder, body, list); err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// captureClusterInfo collects the server version and object counts of the cluster
+func captureClusterInfo(clientset *kubernetes.Clientset) (ClusterInfo, error) {
+	var info ClusterInfo
+
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return info, fmt.Errorf("error getting server version: %v", err)
+	}
+	info.ServerVersion = serverVersion.GitVersion
+
+	counts := []struct {
+		resource string
+		count    *int
+	}{
+		{"nodes", &info.Nodes},
+		{"namespaces", &info.Namespaces},
+		{"pods", &info.Pods},
+	}
+	for _, c := range counts {
+		if *c.count, err = countObjects(clientset, c.resource); err != nil {
+			return info, fmt.Errorf("error counting %s: %v", c.resource, err)
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statsColumn is a column of the statistics table, which rows can also be sorted by
+type statsColumn struct {
+	header string
+
+	// value returns the sort key of the operation's row
+	value func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64
+
+	// cell renders the operation's value, colored with color where the column highlights slow
+	// operations
+	cell func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell
+}
+
+// durationColumn is a column showing one of the calculated statistics
+func durationColumn(header, key string, highlight bool) statsColumn {
+	return statsColumn{
+		header: header,
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			return float64(stat[key])
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			if !highlight {
+				color = ""
+			}
+			return Cell{Text: formatDuration(stat[key]), Color: color}
+		},
+	}
+}
+
+// statsColumns are the available columns of the statistics table by name
+var statsColumns = map[string]statsColumn{
+	"count": {
+		header: "Count",
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			return float64(br.Count(op))
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			return Cell{Text: fmt.Sprintf("%d", br.Count(op))}
+		},
+	},
+	"min": durationColumn("Min", "min", false),
+	"max": durationColumn("Max", "max", false),
+	"avg": durationColumn("Avg", "avg", false),
+	"p50": durationColumn("Median", "median", false),
+	"p95": durationColumn("P95", "p95", true),
+	"p99": durationColumn("P99", "p99", false),
+	"err%": {
+		header: "Err%",
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			return br.ErrorRate(op)
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			return Cell{Text: fmt.Sprintf("%.1f%%", br.ErrorRate(op))}
+		},
+	},
+	"trend": {
+		header: "Trend",
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			return percentChange(historyMedians[op], durationMs(stat["median"]))
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			return trendCell(op, stat)
+		},
+	},
+	"throttled": {
+		header: "Throttled",
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			return float64(br.ThrottledTime(op))
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			if waited := br.ThrottledTime(op); waited > 0 {
+				return Cell{Text: formatDuration(waited)}
+			}
+			return Cell{}
+		},
+	},
+	"reuse": {
+		header: "Conn Reuse",
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			counts := br.ConnectionCounts(op)
+			if counts.New+counts.Reused == 0 {
+				return 0
+			}
+			return float64(counts.Reused) / float64(counts.New+counts.Reused)
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			return Cell{Text: br.ConnectionCounts(op).String()}
+		},
+	},
+	"size": {
+		header: "Avg Size",
+		value: func(br *BenchmarkResults, op string, stat map[string]time.Duration) float64 {
+			avgSize, _ := br.AvgSize(op)
+			return float64(avgSize)
+		},
+		cell: func(br *BenchmarkResults, op string, stat map[string]time.Duration, color string) Cell {
+			if avgSize, ok := br.AvgSize(op); ok {
+				return Cell{Text: formatBytes(avgSize)}
+			}
+			return Cell{}
+		},
+	},
+}
+
+// defaultColumns are shown unless --columns is given. The size column is added when response
+// sizes were recorded, the throttled column when the apiserver throttled requests, the reuse
+// column when more than one connection was established, the trend column when a history was
+// loaded.
+var defaultColumns = []string{"min", "max", "avg", "p50", "p95", "p99"}
+
+// Columns of the statistics table and the metric rows are sorted by, set by --columns,
+// --sort-by and --desc
+var (
+	selectedColumns []string
+	sortBy          = "name"
+	sortDescending  bool
+)
+
+// columnAliases maps alternative column names to the canonical ones
+var columnAliases = map[string]string{
+	"median": "p50",
+	"errors": "err%",
+}
+
+// canonicalColumn returns the canonical name of a column, or an error for unknown columns
+func canonicalColumn(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := columnAliases[name]; ok {
+		name = alias
+	}
+	if _, ok := statsColumns[name]; !ok {
+		return "", fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(sortedKeys(statsColumns), ", "))
+	}
+	return name, nil
+}
+
+// parseColumns parses a comma-separated list of column names
+func parseColumns(value string) ([]string, error) {
+	var columns []string
+	for _, field := range strings.Split(value, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		column, err := canonicalColumn(field)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// parseSortBy validates the metric rows are sorted by, which is a column name or "name"
+func parseSortBy(value string) (string, error) {
+	if strings.ToLower(strings.TrimSpace(value)) == "name" {
+		return "name", nil
+	}
+	return canonicalColumn(value)
+}
+
+// ErrorRate returns the percentage of failed iterations of the operation
+func (br *BenchmarkResults) ErrorRate(operation string) float64 {
+	failed := 0
+	for _, summary := range br.ErrorSummaries() {
+		if summary.Operation == operation {
+			failed += summary.Count
+		}
+	}
+	total := br.Count(operation) + failed
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) / float64(total) * 100
+}
+
+// sortRows orders the operations by the sortBy metric, breaking ties by name
+func (br *BenchmarkResults) sortRows(operations []string, stats map[string]map[string]time.Duration) {
+	if sortBy == "name" {
+		if sortDescending {
+			sort.Sort(sort.Reverse(sort.StringSlice(operations)))
+		}
+		return
+	}
+	column := statsColumns[sortBy]
+	sort.SliceStable(operations, func(i, j int) bool {
+		a := column.value(br, operations[i], stats[operations[i]])
+		b := column.value(br, operations[j], stats[operations[j]])
+		if sortDescending {
+			return a > b
+		}
+		return a < b
+	})
+}
+
+// summaryTop limits the statistics table to this many of the slowest operations and suppresses
+// per-iteration output, 0 to print everything
+var summaryTop int
+
+// slowestOperations returns the summaryTop slowest operations, ranked by the sortBy column or by
+// p95 when sorting by name
+func (br *BenchmarkResults) slowestOperations(operations []string, stats map[string]map[string]time.Duration) []string {
+	column := statsColumns["p95"]
+	if sortBy != "name" {
+		column = statsColumns[sortBy]
+	}
+	sort.SliceStable(operations, func(i, j int) bool {
+		return column.value(br, operations[i], stats[operations[i]]) > column.value(br, operations[j], stats[operations[j]])
+	})
+	return operations[:min(summaryTop, len(operations))]
+}
+
+// printTotals prints how many operations, iterations and errors the run had overall. The
+// aggregates over all namespaces are left out, their iterations are already counted per
+// namespace.
+func (br *BenchmarkResults) printTotals(shown, operations int) {
+	iterations, failed := 0, 0
+	for _, op := range append(sortedKeys(br.Histograms), sortedKeys(br.Digests)...) {
+		if !strings.HasSuffix(op, combinedOperation("")) {
+			iterations += br.Count(op)
+		}
+	}
+	for _, summary := range br.ErrorSummaries() {
+		failed += summary.Count
+	}
+	fmt.Printf("Showing the %d slowest of %d operations. Totals: %d successful iterations, %d errors\n",
+		shown, operations, iterations, failed)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// completeResourceNames reproduces the requests of "kubectl get <resource> <TAB>" in a fresh
+// kubectl process: discovery from the warm disk cache to resolve the resource, the namespace
+// list, and the list of the resource in the namespace projected to the object names. The time
+// of every step is recorded under "<name> (<step>)".
+func completeResourceNames(ctx context.Context, config *rest.Config, clientset *kubernetes.Clientset, cacheDir, resource, namespace, name string, results *BenchmarkResults) ([]string, error) {
+	startTime := time.Now()
+	if err := cachedServerGroupsAndResources(config, cacheDir); err != nil {
+		return nil, fmt.Errorf("error discovering resources: %v", err)
+	}
+	results.Add(name+" (discovery)", time.Since(startTime))
+
+	startTime = time.Now()
+	if _, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err != nil {
+		return nil, fmt.Errorf("error listing namespaces: %v", err)
+	}
+	results.Add(name+" (namespaces)", time.Since(startTime))
+
+	lr := listableResources[resource]
+	list := lr.newList()
+	startTime = time.Now()
+	err := lr.client(clientset).Get().
+		Namespace(namespace).
+		Resource(resource).
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec).
+		Do(ctx).
+		Into(list)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %v", resource, err)
+	}
+	// kubectl prints the names with a go-template over the full objects
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, accessor.GetName())
+	}
+	results.Add(name+" (list names)", time.Since(startTime))
+	return names, nil
+}
+
+// benchmarkKubectlCompletion measures the whole request sequence of kubectl shell completion of
+// object names for every resource, recorded as "kubectl completion <resource>", next to the
+// latency of its discovery, namespace list and name list steps
+func benchmarkKubectlCompletion(config *rest.Config, clientset *kubernetes.Clientset, resources []string, namespace string, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- kubectl completion ---")
+
+	cacheDir, err := os.MkdirTemp("", "k8s-api-bench-completion-")
+	if err != nil {
+		fmt.Printf("Error creating cache directory: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(cacheDir)
+
+	// Completion runs in a shell where kubectl has been used before, so its discovery cache is warm
+	cacheDir = filepath.Join(cacheDir, "cache")
+	if err := cachedServerGroupsAndResources(config, cacheDir); err != nil {
+		fmt.Printf("Error warming discovery cache: %v\n", err)
+		return
+	}
+
+	for _, resource := range resources {
+		name := "kubectl completion " + resource
+		var completions int
+		runBenchmark(name, iterations, func(ctx context.Context) error {
+			names, err := completeResourceNames(ctx, config, clientset, cacheDir, resource, namespace, name, results)
+			completions = len(names)
+			return err
+		}, results)
+		fmt.Printf("Completed %d %s names in %s\n", completions, resource, namespace)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WorkerSamples holds the latencies and error count of a single worker of a concurrent operation
+type WorkerSamples struct {
+	Durations []time.Duration
+	Errors    int
+}
+
+// WorkerStats summarizes the samples of a single worker of a concurrent operation
+type WorkerStats struct {
+	Worker   int     `json:"worker"`
+	Count    int     `json:"count"`
+	Errors   int     `json:"errors"`
+	MedianMs float64 `json:"median_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	MaxMs    float64 `json:"max_ms"`
+}
+
+// AddWorkerSample records the outcome of a task run by the given worker of a concurrent operation
+func (br *BenchmarkResults) AddWorkerSample(operation string, worker int, duration time.Duration, err error) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	if br.Workers[operation] == nil {
+		br.Workers[operation] = make(map[int]*WorkerSamples)
+	}
+	samples, ok := br.Workers[operation][worker]
+	if !ok {
+		samples = &WorkerSamples{}
+		br.Workers[operation][worker] = samples
+	}
+	if err != nil {
+		samples.Errors++
+		return
+	}
+	samples.Durations = append(samples.Durations, duration)
+}
+
+// WorkerStats summarizes, per concurrent operation, the samples of every worker in worker order
+func (br *BenchmarkResults) WorkerStats() map[string][]WorkerStats {
+	breakdown := make(map[string][]WorkerStats)
+	for operation, workers := range br.Workers {
+		var stats []WorkerStats
+		for worker, samples := range workers {
+			entry := WorkerStats{Worker: worker, Count: len(samples.Durations), Errors: samples.Errors}
+			if len(samples.Durations) > 0 {
+				stat := durationStats(samples.Durations)
+				entry.MedianMs = durationMs(stat["median"])
+				entry.P95Ms = durationMs(stat["p95"])
+				entry.MaxMs = durationMs(stat["max"])
+			}
+			stats = append(stats, entry)
+		}
+		sort.Slice(stats, func(i, j int) bool {
+			return stats[i].Worker < stats[j].Worker
+		})
+		breakdown[operation] = stats
+	}
+	return breakdown
+}
+
+// PrintWorkerStats prints the per-worker breakdown of every concurrent operation, making skew
+// caused by a single slow connection or throttled worker visible
+func (br *BenchmarkResults) PrintWorkerStats() {
+	breakdown := br.WorkerStats()
+	if len(breakdown) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Per-Worker Statistics ---")
+	table := NewTable("Operation", "Worker", "Count", "Errors", "Median", "P95", "Max")
+	for _, operation := range sortedKeys(breakdown) {
+		for _, entry := range breakdown[operation] {
+			table.AddRow(operation, fmt.Sprintf("%d", entry.Worker), fmt.Sprintf("%d", entry.Count),
+				fmt.Sprintf("%d", entry.Errors), formatMs(entry.MedianMs),
+				formatMs(entry.P95Ms), formatMs(entry.MaxMs))
+		}
+	}
+	table.Render(os.Stdout)
+}
+
+// runConcurrently runs the tasks 0 to count-1 on the given number of workers, recording the
+// latency of every successful task and the error of every failed one under name, and returns
+// the wall-clock time until all tasks completed. Every task is also attributed to the worker
+// that ran it, and the time its requests spent backing off from 429 responses and the
+// connections they were sent over to the operation.
+func runConcurrently(name string, count, workers int, task func(ctx context.Context, i int) error, results *BenchmarkResults) time.Duration {
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+
+	ctx, throttled := withThrottleCounter(context.Background())
+	ctx, connected := withConnectionCounter(ctx)
+	startTime := time.Now()
+	for w := 0; w < workers; w++ {
+		worker := w + 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				// Tasks restored from a checkpoint have already been measured
+				if i < results.ResumedIterations(name) || results.skipIteration(name) {
+					continue
+				}
+				taskStart := time.Now()
+				err := task(ctx, i)
+				duration := time.Since(taskStart)
+				results.AddWorkerSample(name, worker, duration, err)
+				credentialRefreshes.RecordIteration(taskStart, duration)
+				results.recordOutcome(name, err)
+				if err != nil {
+					results.AddError(name, err, taskStart)
+					results.AddTimeout(name, err, duration)
+					continue
+				}
+				results.Add(name, duration)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+	results.AddThrottled(name, time.Duration(throttled.Load()))
+	results.AddConnections(name, connected.Counts())
+	return time.Since(startTime)
+}
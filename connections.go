@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+
+	"k8s.io/client-go/rest"
+)
+
+// connections observes the connections requests are sent over during the run
+var connections = &connectionTracker{}
+
+// connectionTracker counts the requests sent over a newly established connection and over a
+// reused one. Load balancers closing idle connections sooner than the client expects force new
+// TCP and TLS handshakes, which shows as bimodal latency.
+type connectionTracker struct {
+	established   atomic.Int64
+	reused        atomic.Int64
+	tlsHandshakes atomic.Int64
+}
+
+// ConnectionCounts is the number of requests of an operation by connection reuse
+type ConnectionCounts struct {
+	New    int64 `json:"new"`
+	Reused int64 `json:"reused"`
+}
+
+// connectionCounter counts the requests of an iteration by connection reuse
+type connectionCounter struct {
+	established atomic.Int64
+	reused      atomic.Int64
+}
+
+// connectionCounterKey is the context key of the connectionCounter of an iteration
+type connectionCounterKey struct{}
+
+// withConnectionCounter returns a context whose requests are counted by connection reuse on the
+// returned counter, so that they are attributed to the iteration that sent them even while
+// other operations run concurrently
+func withConnectionCounter(ctx context.Context) (context.Context, *connectionCounter) {
+	counter := &connectionCounter{}
+	return context.WithValue(ctx, connectionCounterKey{}, counter), counter
+}
+
+// Counts returns the number of requests sent over new and reused connections
+func (c *connectionCounter) Counts() ConnectionCounts {
+	return ConnectionCounts{New: c.established.Load(), Reused: c.reused.Load()}
+}
+
+// trackConnections installs the tracker on the config's transport
+func trackConnections(config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &connectionTransport{next: rt, tracker: connections}
+	})
+}
+
+// Counts returns the number of requests sent over new and reused connections so far
+func (t *connectionTracker) Counts() ConnectionCounts {
+	return ConnectionCounts{New: t.established.Load(), Reused: t.reused.Load()}
+}
+
+// connectionTransport traces which connection every request gets, counting it run-wide and on
+// the counter of the request's context, if any
+type connectionTransport struct {
+	next    http.RoundTripper
+	tracker *connectionTracker
+}
+
+func (t *connectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	counter, _ := req.Context().Value(connectionCounterKey{}).(*connectionCounter)
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.tracker.reused.Add(1)
+			} else {
+				t.tracker.established.Add(1)
+			}
+			if counter == nil {
+				return
+			}
+			if info.Reused {
+				counter.reused.Add(1)
+			} else {
+				counter.established.Add(1)
+			}
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				t.tracker.tlsHandshakes.Add(1)
+			}
+		},
+	}
+	return t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// String formats the counts as the share of reused connections and the new connections
+func (c ConnectionCounts) String() string {
+	total := c.New + c.Reused
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%% (%d new)", float64(c.Reused)*100/float64(total), c.New)
+}
+
+// AddConnections records the requests of the operation by connection reuse
+func (br *BenchmarkResults) AddConnections(operation string, counts ConnectionCounts) {
+	if counts.New <= 0 && counts.Reused <= 0 {
+		return
+	}
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	total, ok := br.Connections[operation]
+	if !ok {
+		total = &ConnectionCounts{}
+		br.Connections[operation] = total
+	}
+	total.New += counts.New
+	total.Reused += counts.Reused
+}
+
+// ConnectionCounts returns the requests of the operation by connection reuse
+func (br *BenchmarkResults) ConnectionCounts(operation string) ConnectionCounts {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if counts, ok := br.Connections[operation]; ok {
+		return *counts
+	}
+	return ConnectionCounts{}
+}
+
+// SetMetrics records the number of requests over new and reused connections and of TLS
+// handshakes
+func (t *connectionTracker) SetMetrics(results *BenchmarkResults) {
+	counts := t.Counts()
+	if counts.New+counts.Reused == 0 {
+		return
+	}
+	results.SetMetric("connections established", float64(counts.New))
+	results.SetMetric("connections reused", float64(counts.Reused))
+	results.SetMetric("TLS handshakes", float64(t.tlsHandshakes.Load()))
+}
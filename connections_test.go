@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConnectionTransportAttribution(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	// Both operations share the run-wide tracker, but only one of them keeps its connection alive
+	tracker := &connectionTracker{}
+	keepAlive := &connectionTransport{next: &http.Transport{}, tracker: tracker}
+	noKeepAlive := &connectionTransport{next: &http.Transport{DisableKeepAlives: true}, tracker: tracker}
+	defer keepAlive.next.(*http.Transport).CloseIdleConnections()
+
+	const requests = 20
+	send := func(ctx context.Context, transport http.RoundTripper) {
+		for i := 0; i < requests; i++ {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Errorf("RoundTrip() error = %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+
+	reusingCtx, reusing := withConnectionCounter(context.Background())
+	reconnectingCtx, reconnecting := withConnectionCounter(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		send(reusingCtx, keepAlive)
+	}()
+	go func() {
+		defer wg.Done()
+		send(reconnectingCtx, noKeepAlive)
+	}()
+	wg.Wait()
+
+	tests := []struct {
+		name string
+		got  ConnectionCounts
+		want ConnectionCounts
+	}{
+		{name: "keep-alive operation", got: reusing.Counts(), want: ConnectionCounts{New: 1, Reused: requests - 1}},
+		{name: "operation without keep-alive", got: reconnecting.Counts(), want: ConnectionCounts{New: requests}},
+		{name: "run-wide", got: tracker.Counts(), want: ConnectionCounts{New: requests + 1, Reused: requests - 1}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s counts = %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+}
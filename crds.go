@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// storageVersion returns the version the CRD's objects are persisted in
+func storageVersion(crd apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+// benchmarkCRDConversion lists the objects of every CRD that uses a conversion webhook, once at
+// the storage version and once at every other served version, and reports the conversion
+// overhead per object derived from the median latencies
+func benchmarkCRDConversion(config *rest.Config, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- CRD conversion webhook benchmark ---")
+
+	apiextensionsClient, err := apiextensionsclientset.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating apiextensions client: %v\n", err)
+		return
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating dynamic client: %v\n", err)
+		return
+	}
+
+	crds, err := apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error listing CRDs: %v\n", err)
+		return
+	}
+
+	found := 0
+	for _, crd := range crds.Items {
+		if crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy != apiextensionsv1.WebhookConverter {
+			continue
+		}
+		storage := storageVersion(crd)
+		found++
+
+		// Benchmark the storage version first, it serves as the baseline without conversion
+		versions := []string{storage}
+		for _, version := range crd.Spec.Versions {
+			if version.Served && version.Name != storage {
+				versions = append(versions, version.Name)
+			}
+		}
+
+		objects := 0
+		names := make(map[string]string)
+		for _, version := range versions {
+			gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: version, Resource: crd.Spec.Names.Plural}
+			kind := "converted"
+			if version == storage {
+				kind = "storage"
+			}
+			name := fmt.Sprintf("list %s (%s, %s)", gvr.GroupResource(), version, kind)
+			names[version] = name
+
+			runBenchmark(name, iterations, func(ctx context.Context) error {
+				list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+				if err != nil {
+					return err
+				}
+				objects = len(list.Items)
+				return nil
+			}, results)
+		}
+
+		if objects == 0 {
+			fmt.Printf("No %s objects found, skipping conversion overhead calculation\n", crd.Name)
+			continue
+		}
+
+		stats := results.CalculateStats()
+		baseline, ok := stats[names[storage]]
+		if !ok {
+			continue
+		}
+		for _, version := range versions[1:] {
+			converted, ok := stats[names[version]]
+			if !ok {
+				continue
+			}
+			overhead := durationMs(converted["median"]-baseline["median"]) / float64(objects)
+			fmt.Printf("Conversion overhead of %s from %s to %s: %.3f ms per object\n", crd.Name, storage, version, overhead)
+			results.SetMetric(fmt.Sprintf("conversion overhead %s %s->%s (ms/object)", crd.Name, storage, version), overhead)
+		}
+	}
+
+	if found == 0 {
+		fmt.Println("No CRDs with conversion webhooks found")
+	}
+}
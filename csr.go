@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// csrLifecycleName is the operation of the end-to-end CSR issuance
+const csrLifecycleName = "CSR lifecycle (create to certificate)"
+
+// generateCSR creates a PEM encoded certificate request for a new ECDSA key
+func generateCSR() ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "k8s-api-bench"},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// waitForCertificate waits until the watched CSR has been issued a certificate
+func waitForCertificate(watcher watch.Interface) error {
+	timeout := time.After(watchEventTimeout)
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch closed while waiting for certificate")
+			}
+			if csr, ok := event.Object.(*certificatesv1.CertificateSigningRequest); ok && len(csr.Status.Certificate) > 0 {
+				return nil
+			}
+		case <-timeout:
+			return fmt.Errorf("timed out after %v waiting for certificate", watchEventTimeout)
+		}
+	}
+}
+
+// csrLifecycle creates, approves and waits for the issuance of a single CSR, recording every
+// stage as well as the end-to-end time. The CSR is deleted afterwards. The error of the first
+// failed stage is returned.
+func csrLifecycle(clientset *kubernetes.Clientset, iteration, iterations int, results *BenchmarkResults) error {
+	csrs := clientset.CertificatesV1().CertificateSigningRequests()
+
+	request, err := generateCSR()
+	if err != nil {
+		fmt.Printf("Error generating certificate request: %v\n", err)
+		return err
+	}
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "k8s-api-bench-",
+			Labels:       map[string]string{createdByLabel: createdByValue},
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    request,
+			SignerName: certificatesv1.KubeAPIServerClientSignerName,
+			Usages:     []certificatesv1.KeyUsage{certificatesv1.UsageClientAuth},
+		},
+	}
+
+	startTime := time.Now()
+	created, err := csrs.Create(context.TODO(), csr, metav1.CreateOptions{})
+	recordIteration("create CSR", iteration, iterations, startTime, time.Since(startTime), err, results)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := csrs.Delete(context.TODO(), created.Name, metav1.DeleteOptions{}); err != nil {
+			fmt.Printf("Error deleting CSR %s: %v\n", created.Name, err)
+		}
+	}()
+
+	watcher, err := csrs.Watch(context.TODO(), metav1.ListOptions{
+		FieldSelector:   "metadata.name=" + created.Name,
+		ResourceVersion: created.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return err
+	}
+	defer watcher.Stop()
+
+	created.Status.Conditions = append(created.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:    certificatesv1.CertificateApproved,
+		Status:  corev1.ConditionTrue,
+		Reason:  "K8sAPIBench",
+		Message: "Approved by k8s-api-bench",
+	})
+	approveTime := time.Now()
+	_, err = csrs.UpdateApproval(context.TODO(), created.Name, created, metav1.UpdateOptions{})
+	recordIteration("approve CSR", iteration, iterations, approveTime, time.Since(approveTime), err, results)
+	if err != nil {
+		return err
+	}
+
+	err = waitForCertificate(watcher)
+	issuedTime := time.Now()
+	recordIteration("issue CSR certificate (after approval)", iteration, iterations, approveTime, issuedTime.Sub(approveTime), err, results)
+	recordIteration(csrLifecycleName, iteration, iterations, startTime, issuedTime.Sub(startTime), err, results)
+	return err
+}
+
+// benchmarkCSRLifecycle measures the end-to-end issuance latency of CertificateSigningRequests
+// signed by the kube-apiserver-client signer, relevant for node bootstrap and cert-manager-heavy
+// clusters
+func benchmarkCSRLifecycle(clientset *kubernetes.Clientset, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- CertificateSigningRequest lifecycle benchmark ---")
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(csrLifecycleName) {
+			continue
+		}
+		results.recordOutcome(csrLifecycleName, csrLifecycle(clientset, i+1, iterations, results))
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// requestLogLevel is the verbosity at which every API request is logged, matching kubectl -v=6
+const requestLogLevel = 6
+
+// verboseTransport logs method, URL, status code, content length and duration of every request
+type verboseTransport struct {
+	next http.RoundTripper
+}
+
+// newVerboseTransport wraps rt so that all requests passing through it are logged to stderr
+func newVerboseTransport(rt http.RoundTripper) http.RoundTripper {
+	return &verboseTransport{next: rt}
+}
+
+func (t *verboseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	startTime := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s %s error after %v: %v\n",
+			startTime.Format("15:04:05.000"), req.Method, req.URL, time.Since(startTime), err)
+		return nil, err
+	}
+
+	// The body is usually streamed, so the request is logged once it has been fully read
+	resp.Body = &loggingBody{
+		ReadCloser: resp.Body,
+		req:        req,
+		status:     resp.Status,
+		startTime:  startTime,
+		headerTime: time.Since(startTime),
+	}
+	return resp, nil
+}
+
+// loggingBody counts the bytes read from a response body and logs the request when it is closed
+type loggingBody struct {
+	io.ReadCloser
+	req        *http.Request
+	status     string
+	startTime  time.Time
+	headerTime time.Duration
+	bytes      int64
+	once       sync.Once
+}
+
+func (b *loggingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	return n, err
+}
+
+func (b *loggingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		fmt.Fprintf(os.Stderr, "%s %s %s %s %d bytes in %v (headers after %v)\n",
+			b.startTime.Format("15:04:05.000"), b.req.Method, b.req.URL, b.status, b.bytes,
+			time.Since(b.startTime), b.headerTime)
+	})
+	return err
+}
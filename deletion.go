@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// benchmarkFinalizer is a finalizer no controller acts on, removed by the benchmark itself
+const benchmarkFinalizer = "k8s-api-bench/benchmark"
+
+// removeFinalizersPatch clears all finalizers of an object
+var removeFinalizersPatch = []byte(`{"metadata":{"finalizers":null}}`)
+
+// deleteSeededConfigMapsWithFinalizers removes the finalizers of all ConfigMaps of the seed set
+// left over by failed iterations and deletes them
+func deleteSeededConfigMapsWithFinalizers(clientset *kubernetes.Clientset, namespace, seedSet string) error {
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	list, err := configMaps.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+	if err != nil {
+		return err
+	}
+	for _, configMap := range list.Items {
+		if len(configMap.Finalizers) == 0 {
+			continue
+		}
+		if _, err := configMaps.Patch(context.TODO(), configMap.Name, types.MergePatchType, removeFinalizersPatch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+	}
+	return deleteSeededConfigMaps(clientset, namespace, seedSet)
+}
+
+// benchmarkFinalizers compares how long ConfigMaps take to disappear after deletion without a
+// finalizer with how long they take after their finalizer is removed, quantifying the overhead
+// of the finalizer deletion path
+func benchmarkFinalizers(clientset *kubernetes.Clientset, namespace string, iterations int, results *BenchmarkResults) {
+	const seedSet = "finalizers"
+	const plainName = "delete to gone (no finalizer)"
+	const finalizedName = "finalizer removal to gone"
+	const totalName = "delete to gone (with finalizer removal)"
+
+	fmt.Printf("\n--- Finalizer deletion benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMapsWithFinalizers(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	list, err := configMaps.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+	if err != nil {
+		fmt.Printf("Error listing ConfigMaps: %v\n", err)
+		return
+	}
+	watcher, err := configMaps.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:   seedSelector(seedSet),
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer watcher.Stop()
+
+	create := func(name string, finalizers []string) error {
+		_, err := configMaps.Create(context.TODO(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Labels:     seedLabels(seedSet),
+				Finalizers: finalizers,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	}
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(totalName) {
+			continue
+		}
+
+		// Without finalizer, the object is removed by the Delete call itself
+		plain := fmt.Sprintf("k8s-api-bench-%s-plain-%d", seedSet, i)
+		if err := create(plain, nil); err != nil {
+			recordIteration(plainName, i+1, iterations, time.Now(), 0, err, results)
+		} else {
+			startTime := time.Now()
+			err := configMaps.Delete(context.TODO(), plain, metav1.DeleteOptions{})
+			if err == nil {
+				var goneTime time.Time
+				goneTime, err = waitForEvent(watcher, watch.Deleted, plain)
+				recordIteration(plainName, i+1, iterations, startTime, goneTime.Sub(startTime), err, results)
+			} else {
+				recordIteration(plainName, i+1, iterations, startTime, 0, err, results)
+			}
+		}
+
+		// With finalizer, Delete only marks the object and removing the finalizer completes it
+		finalized := fmt.Sprintf("k8s-api-bench-%s-finalized-%d", seedSet, i)
+		if err := create(finalized, []string{benchmarkFinalizer}); err != nil {
+			recordIteration(totalName, i+1, iterations, time.Now(), 0, err, results)
+			results.recordOutcome(totalName, err)
+			continue
+		}
+		startTime := time.Now()
+		if err := configMaps.Delete(context.TODO(), finalized, metav1.DeleteOptions{}); err != nil {
+			recordIteration(totalName, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(totalName, err)
+			continue
+		}
+		removeTime := time.Now()
+		_, err := configMaps.Patch(context.TODO(), finalized, types.MergePatchType, removeFinalizersPatch, metav1.PatchOptions{})
+		if err != nil {
+			recordIteration(totalName, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(totalName, err)
+			continue
+		}
+		goneTime, err := waitForEvent(watcher, watch.Deleted, finalized)
+		recordIteration(finalizedName, i+1, iterations, removeTime, goneTime.Sub(removeTime), err, results)
+		recordIteration(totalName, i+1, iterations, startTime, goneTime.Sub(startTime), err, results)
+		results.recordOutcome(totalName, err)
+	}
+}
+
+// propagationPolicies are the deletion propagation policies compared by the benchmark
+var propagationPolicies = []metav1.DeletionPropagation{
+	metav1.DeletePropagationForeground,
+	metav1.DeletePropagationBackground,
+	metav1.DeletePropagationOrphan,
+}
+
+// waitForOwnedReplicaSet waits until the watch delivers the ADDED event of a ReplicaSet owned
+// by the object with the given UID and returns the ReplicaSet's name
+func waitForOwnedReplicaSet(watcher watch.Interface, owner types.UID) (string, error) {
+	timeout := time.After(watchEventTimeout)
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return "", fmt.Errorf("watch closed while waiting for ReplicaSet")
+			}
+			if event.Type == watch.Error {
+				return "", fmt.Errorf("watch error: %v", event.Object)
+			}
+			replicaSet, ok := event.Object.(*appsv1.ReplicaSet)
+			if !ok || event.Type != watch.Added {
+				continue
+			}
+			for _, ref := range replicaSet.OwnerReferences {
+				if ref.UID == owner {
+					return replicaSet.Name, nil
+				}
+			}
+		case <-timeout:
+			return "", fmt.Errorf("timed out after %v waiting for ReplicaSet", watchEventTimeout)
+		}
+	}
+}
+
+// deleteSeededReplicaSets removes all ReplicaSets of the given seed set from the namespace,
+// including those orphaned by their Deployment
+func deleteSeededReplicaSets(clientset *kubernetes.Clientset, namespace, seedSet string) error {
+	return clientset.AppsV1().ReplicaSets(namespace).DeleteCollection(context.TODO(),
+		metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+}
+
+// deletePropagation deletes a seeded Deployment with the given propagation policy and returns
+// the time until the deletion is complete: the Deployment and its ReplicaSet are gone for
+// Foreground and Background, and the Deployment is gone for Orphan. The orphaned ReplicaSet
+// is deleted afterwards so the next Deployment does not adopt it.
+func deletePropagation(clientset *kubernetes.Clientset, namespace, seedSet string, policy metav1.DeletionPropagation, deploymentWatcher, replicaSetWatcher watch.Interface) (time.Duration, error) {
+	deployment, err := seedDeployment(clientset, namespace, seedSet, 0)
+	if err != nil {
+		return 0, err
+	}
+	replicaSet, err := waitForOwnedReplicaSet(replicaSetWatcher, deployment.UID)
+	if err != nil {
+		return 0, err
+	}
+
+	startTime := time.Now()
+	err = clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), deployment.Name, metav1.DeleteOptions{PropagationPolicy: &policy})
+	if err != nil {
+		return 0, err
+	}
+	deploymentGone, err := waitForEvent(deploymentWatcher, watch.Deleted, deployment.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if policy == metav1.DeletePropagationOrphan {
+		err := clientset.AppsV1().ReplicaSets(namespace).Delete(context.TODO(), replicaSet, metav1.DeleteOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("error deleting orphaned ReplicaSet: %v", err)
+		}
+		if _, err := waitForEvent(replicaSetWatcher, watch.Deleted, replicaSet); err != nil {
+			return 0, err
+		}
+		return deploymentGone.Sub(startTime), nil
+	}
+
+	replicaSetGone, err := waitForEvent(replicaSetWatcher, watch.Deleted, replicaSet)
+	if err != nil {
+		return 0, err
+	}
+	if replicaSetGone.After(deploymentGone) {
+		return replicaSetGone.Sub(startTime), nil
+	}
+	return deploymentGone.Sub(startTime), nil
+}
+
+// benchmarkDeletionPropagation deletes seeded Deployments with Foreground, Background and Orphan
+// propagation and measures the time until each deletion is complete. The Deployments have no
+// replicas, so only the Deployment and its ReplicaSet are involved.
+func benchmarkDeletionPropagation(clientset *kubernetes.Clientset, namespace string, iterations int, results *BenchmarkResults) {
+	const seedSet = "propagation"
+
+	fmt.Printf("\n--- Deletion propagation benchmark in namespace %s ---\n", namespace)
+	deployments := clientset.AppsV1().Deployments(namespace)
+	replicaSets := clientset.AppsV1().ReplicaSets(namespace)
+	defer func() {
+		if err := deleteSeededDeployments(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded Deployments: %v\n", err)
+		}
+		if err := deleteSeededReplicaSets(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ReplicaSets: %v\n", err)
+		}
+	}()
+
+	listOptions := metav1.ListOptions{LabelSelector: seedSelector(seedSet)}
+	deploymentList, err := deployments.List(context.TODO(), listOptions)
+	if err != nil {
+		fmt.Printf("Error listing Deployments: %v\n", err)
+		return
+	}
+	replicaSetList, err := replicaSets.List(context.TODO(), listOptions)
+	if err != nil {
+		fmt.Printf("Error listing ReplicaSets: %v\n", err)
+		return
+	}
+	deploymentWatcher, err := deployments.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:   listOptions.LabelSelector,
+		ResourceVersion: deploymentList.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer deploymentWatcher.Stop()
+	replicaSetWatcher, err := replicaSets.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:   listOptions.LabelSelector,
+		ResourceVersion: replicaSetList.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer replicaSetWatcher.Stop()
+
+	for _, policy := range propagationPolicies {
+		name := fmt.Sprintf("delete Deployment (%s)", strings.ToLower(string(policy)))
+		for i := 0; i < iterations; i++ {
+			if results.skipIteration(name) {
+				continue
+			}
+			startTime := time.Now()
+			duration, err := deletePropagation(clientset, namespace, seedSet, policy, deploymentWatcher, replicaSetWatcher)
+			recordIteration(name, i+1, iterations, startTime, duration, err, results)
+			results.recordOutcome(name, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// loadSummary reads a summary JSON file, or the summary.json of a run directory
+func loadSummary(path string) (Summary, error) {
+	var summary Summary
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return summary, err
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, "summary.json")
+	}
+
+	err = readJSONFile(path, &summary)
+	return summary, err
+}
+
+// percentChange returns the relative change from old to new in percent
+func percentChange(old, new float64) float64 {
+	if old == 0 {
+		return 0
+	}
+	return (new - old) / old * 100
+}
+
+// changeCell formats a relative change, colored red for regressions and green for improvements
+// beyond the threshold
+func changeCell(change, threshold float64) Cell {
+	cell := Cell{Text: fmt.Sprintf("%+.1f%%", change)}
+	switch {
+	case change > threshold:
+		cell.Color = colorRed
+	case change < -threshold:
+		cell.Color = colorGreen
+	}
+	return cell
+}
+
+// runDiff implements the diff subcommand, which compares the per-operation latencies of two
+// result files
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	threshold := fs.Float64("threshold", 10, "Relative change in percent above which an operation is highlighted as regression or improvement")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+	fs.StringVar(&durationUnit, "unit", durationUnit, "Unit durations are shown in: auto, us, ms or s")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s diff [flags] <old.json|old-run-dir> <new.json|new-run-dir>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+	if !validDurationUnit(durationUnit) {
+		fmt.Printf("Error: unknown unit %q (expected auto, us, ms or s)\n", durationUnit)
+		return 1
+	}
+
+	oldSummary, err := loadSummary(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Error loading %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	newSummary, err := loadSummary(fs.Arg(1))
+	if err != nil {
+		fmt.Printf("Error loading %s: %v\n", fs.Arg(1), err)
+		return 1
+	}
+
+	if *noColor {
+		colorEnabled = false
+	}
+
+	printDiff(oldSummary, newSummary, *threshold)
+	return 0
+}
+
+// printDiff prints the median and P95 deltas of every operation found in either summary
+func printDiff(oldSummary, newSummary Summary, threshold float64) {
+	oldOps := make(map[string]OperationSummary)
+	newOps := make(map[string]OperationSummary)
+	names := make(map[string]bool)
+	for _, op := range oldSummary.Operations {
+		oldOps[op.Operation] = op
+		names[op.Operation] = true
+	}
+	for _, op := range newSummary.Operations {
+		newOps[op.Operation] = op
+		names[op.Operation] = true
+	}
+
+	operations := make([]string, 0, len(names))
+	for op := range names {
+		operations = append(operations, op)
+	}
+	sort.Strings(operations)
+
+	fmt.Println("\n--- Benchmark Diff ---")
+	table := NewTable("Operation", "Old Median", "New Median", "Change", "Old P95", "New P95", "Change")
+	for _, name := range operations {
+		oldOp, inOld := oldOps[name]
+		newOp, inNew := newOps[name]
+		if !inOld || !inNew {
+			only := "only in new"
+			if !inNew {
+				only = "only in old"
+			}
+			table.AddRow(name, only)
+			continue
+		}
+
+		table.AddCells(
+			Cell{Text: name},
+			Cell{Text: formatMs(oldOp.MedianMs)},
+			Cell{Text: formatMs(newOp.MedianMs)},
+			changeCell(percentChange(oldOp.MedianMs, newOp.MedianMs), threshold),
+			Cell{Text: formatMs(oldOp.P95Ms)},
+			Cell{Text: formatMs(newOp.P95Ms)},
+			changeCell(percentChange(oldOp.P95Ms, newOp.P95Ms), threshold))
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/disk"
+	"k8s.io/client-go/rest"
+)
+
+// discoveryCacheTTL matches the TTL kubectl uses for its discovery cache
+const discoveryCacheTTL = 6 * time.Hour
+
+// cachedServerGroupsAndResources creates a disk-cached discovery client on the given cache
+// directory, the way every kubectl invocation does, and fetches all groups and resources
+func cachedServerGroupsAndResources(config *rest.Config, cacheDir string) error {
+	client, err := disk.NewCachedDiscoveryClientForConfig(rest.CopyConfig(config),
+		filepath.Join(cacheDir, "discovery"), filepath.Join(cacheDir, "http"), discoveryCacheTTL)
+	if err != nil {
+		return err
+	}
+	_, _, err = client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return err
+	}
+	return nil
+}
+
+// benchmarkDiscoveryCache compares discovery with a cold disk cache, a fresh cache directory per
+// iteration, with discovery from a warm disk cache, reproducing the difference between a user's
+// first and subsequent kubectl invocations
+func benchmarkDiscoveryCache(config *rest.Config, iterations int, results *BenchmarkResults) {
+	const coldName = "discovery (cold disk cache)"
+	const warmName = "discovery (warm disk cache)"
+
+	fmt.Println("\n--- Discovery cache benchmark ---")
+
+	baseDir, err := os.MkdirTemp("", "k8s-api-bench-discovery-")
+	if err != nil {
+		fmt.Printf("Error creating cache directory: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(baseDir)
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(coldName) {
+			continue
+		}
+		cacheDir := filepath.Join(baseDir, fmt.Sprintf("cold-%d", i))
+		startTime := time.Now()
+		err := cachedServerGroupsAndResources(config, cacheDir)
+		recordIteration(coldName, i+1, iterations, startTime, time.Since(startTime), err, results)
+		results.recordOutcome(coldName, err)
+	}
+
+	warmDir := filepath.Join(baseDir, "warm")
+	if err := cachedServerGroupsAndResources(config, warmDir); err != nil {
+		fmt.Printf("Error warming discovery cache: %v\n", err)
+		return
+	}
+	runBenchmark(warmName, iterations, func(context.Context) error {
+		return cachedServerGroupsAndResources(config, warmDir)
+	}, results)
+}
+
+// apiResources fetches the preferred version of every resource through the discovery client,
+// the way "kubectl api-resources" does, and returns the number of resources
+func apiResources(client discovery.DiscoveryInterface) (int, error) {
+	lists, err := client.ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return 0, err
+	}
+	count := 0
+	for _, list := range lists {
+		count += len(list.APIResources)
+	}
+	return count, nil
+}
+
+// benchmarkAPIResources reproduces "kubectl api-resources" in a fresh process, once without a
+// discovery cache and once with a kubectl-style disk cache populated beforehand, recorded as
+// "kubectl api-resources (no cache)" and "kubectl api-resources (disk cache)". The number of API
+// groups and resources is recorded as metrics, since discovery without cache grows with them.
+func benchmarkAPIResources(config *rest.Config, iterations int, results *BenchmarkResults) {
+	const uncachedName = "kubectl api-resources (no cache)"
+	const cachedName = "kubectl api-resources (disk cache)"
+
+	fmt.Println("\n--- kubectl api-resources ---")
+
+	var resources int
+	runBenchmark(uncachedName, iterations, func(context.Context) error {
+		client, err := discovery.NewDiscoveryClientForConfig(rest.CopyConfig(config))
+		if err != nil {
+			return err
+		}
+		resources, err = apiResources(client)
+		return err
+	}, results)
+
+	cacheDir, err := os.MkdirTemp("", "k8s-api-bench-api-resources-")
+	if err != nil {
+		fmt.Printf("Error creating cache directory: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(cacheDir)
+	newCachedClient := func() (discovery.CachedDiscoveryInterface, error) {
+		return disk.NewCachedDiscoveryClientForConfig(rest.CopyConfig(config),
+			filepath.Join(cacheDir, "discovery"), filepath.Join(cacheDir, "http"), discoveryCacheTTL)
+	}
+	client, err := newCachedClient()
+	if err == nil {
+		_, err = apiResources(client)
+	}
+	if err != nil {
+		fmt.Printf("Error warming discovery cache: %v\n", err)
+		return
+	}
+	runBenchmark(cachedName, iterations, func(context.Context) error {
+		client, err := newCachedClient()
+		if err != nil {
+			return err
+		}
+		_, err = apiResources(client)
+		return err
+	}, results)
+
+	groups, err := client.ServerGroups()
+	if err == nil {
+		results.SetMetric("api-resources groups", float64(len(groups.Groups)))
+	}
+	results.SetMetric("api-resources resources", float64(resources))
+	fmt.Printf("Found %d API resources\n", resources)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// slowestGroupVersionsShown is the number of group versions listed in the discovery ranking
+const slowestGroupVersionsShown = 5
+
+// discoveryGroupOperation returns the name of the resource fetch of a group version
+func discoveryGroupOperation(groupVersion string) string {
+	return fmt.Sprintf("discovery resources [%s]", groupVersion)
+}
+
+// preferredResources reduces the resources of all group versions to one entry per group and
+// resource, taken from the group's preferred version where it serves the resource, the way
+// ServerPreferredResources does
+func preferredResources(groups *metav1.APIGroupList, resources map[string]*metav1.APIResourceList) []*metav1.APIResourceList {
+	var preferred []*metav1.APIResourceList
+	for _, group := range groups.Groups {
+		versions := []string{group.PreferredVersion.GroupVersion}
+		for _, version := range group.Versions {
+			if version.GroupVersion != group.PreferredVersion.GroupVersion {
+				versions = append(versions, version.GroupVersion)
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, groupVersion := range versions {
+			list, ok := resources[groupVersion]
+			if !ok {
+				continue
+			}
+			filtered := &metav1.APIResourceList{GroupVersion: groupVersion}
+			for _, resource := range list.APIResources {
+				// Subresources are part of their resource's entry
+				if strings.Contains(resource.Name, "/") || seen[resource.Name] {
+					continue
+				}
+				seen[resource.Name] = true
+				filtered.APIResources = append(filtered.APIResources, resource)
+			}
+			if len(filtered.APIResources) > 0 {
+				preferred = append(preferred, filtered)
+			}
+		}
+	}
+	return preferred
+}
+
+// benchmarkDiscoveryStages times the stages of discovery separately: fetching the API groups,
+// fetching the resources of every group version and filtering them down to the preferred
+// resources. Group versions are fetched one after the other, so the latency of every group's
+// discovery endpoint is attributed to it, and the slowest ones are ranked.
+func benchmarkDiscoveryStages(clientset *kubernetes.Clientset, iterations int, results *BenchmarkResults) {
+	const groupsName = "discovery stage: ServerGroups"
+	const fetchName = "discovery stage: resources of all group versions"
+	const filterName = "discovery stage: preferred-resource filtering"
+
+	fmt.Println("\n--- Discovery stages benchmark ---")
+	discoveryClient := clientset.Discovery()
+
+	var groupVersions []string
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(groupsName) {
+			continue
+		}
+		startTime := time.Now()
+		groups, err := discoveryClient.ServerGroups()
+		recordIteration(groupsName, i+1, iterations, startTime, time.Since(startTime), err, results)
+		results.recordOutcome(groupsName, err)
+		if err != nil {
+			continue
+		}
+
+		fetchStart := time.Now()
+		resources := make(map[string]*metav1.APIResourceList)
+		failed := 0
+		groupVersions = groupVersions[:0]
+		for _, group := range groups.Groups {
+			for _, version := range group.Versions {
+				groupVersions = append(groupVersions, version.GroupVersion)
+				measureTime(discoveryGroupOperation(version.GroupVersion), i+1, iterations, func(context.Context) error {
+					list, err := discoveryClient.ServerResourcesForGroupVersion(version.GroupVersion)
+					if err != nil {
+						failed++
+						return err
+					}
+					resources[version.GroupVersion] = list
+					return nil
+				}, results)
+			}
+		}
+		if failed > 0 {
+			err = fmt.Errorf("%d group versions could not be discovered", failed)
+		}
+		recordIteration(fetchName, i+1, iterations, fetchStart, time.Since(fetchStart), err, results)
+
+		filterStart := time.Now()
+		preferred := preferredResources(groups, resources)
+		recordIteration(filterName, i+1, iterations, filterStart, time.Since(filterStart), nil, results)
+		if i == 0 {
+			fmt.Printf("Discovered %d group versions, %d preferred resource lists\n", len(resources), len(preferred))
+		}
+	}
+
+	// Rank the group versions by median fetch latency to point at slow discovery endpoints
+	stats := results.CalculateStats()
+	sort.SliceStable(groupVersions, func(i, j int) bool {
+		return stats[discoveryGroupOperation(groupVersions[i])]["median"] > stats[discoveryGroupOperation(groupVersions[j])]["median"]
+	})
+	if len(groupVersions) == 0 {
+		return
+	}
+	fmt.Println("\n--- Slowest Discovery Endpoints ---")
+	table := NewTable("Rank", "Group Version", "Median", "P95")
+	for i, groupVersion := range groupVersions[:min(slowestGroupVersionsShown, len(groupVersions))] {
+		stat := stats[discoveryGroupOperation(groupVersion)]
+		table.AddRow(fmt.Sprintf("%d", i+1), groupVersion, formatDuration(stat["median"]), formatDuration(stat["p95"]))
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// slowEndpointRatio is the median latency relative to the fastest endpoint above which an
+// endpoint is highlighted as slow
+const slowEndpointRatio = 1.5
+
+// parseEndpoints parses a comma-separated list of apiserver URLs
+func parseEndpoints(value string) ([]string, error) {
+	var endpoints []string
+	for _, endpoint := range strings.Split(value, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint %q, expected a URL such as https://10.0.0.1:6443", endpoint)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// newEndpointConfig returns a copy of config that talks to the given apiserver endpoint, still
+// verifying the serving certificate against the original host name
+func newEndpointConfig(config *rest.Config, endpoint string) *rest.Config {
+	endpointConfig := rest.CopyConfig(config)
+	endpointConfig.Host = endpoint
+	if endpointConfig.TLSClientConfig.ServerName == "" {
+		if u, err := url.Parse(config.Host); err == nil && u.Hostname() != "" {
+			endpointConfig.TLSClientConfig.ServerName = u.Hostname()
+		}
+	}
+	return endpointConfig
+}
+
+// endpointOperation returns the name of an operation run against a single apiserver endpoint
+func endpointOperation(operation, endpoint string) string {
+	return fmt.Sprintf("%s [%s]", operation, endpoint)
+}
+
+// benchmarkEndpoints runs a set of read operations against each apiserver endpoint in turn,
+// recording them as "<operation> [<endpoint>]", and prints how much slower each endpoint is
+// than the fastest one to spot an unhealthy apiserver replica
+func benchmarkEndpoints(config *rest.Config, endpoints []string, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- Apiserver endpoint comparison ---")
+
+	clientsets := make(map[string]*kubernetes.Clientset)
+	for _, endpoint := range endpoints {
+		clientset, err := kubernetes.NewForConfig(newEndpointConfig(config, endpoint))
+		if err != nil {
+			fmt.Printf("Error creating client for endpoint %s: %v\n", endpoint, err)
+			continue
+		}
+		clientsets[endpoint] = clientset
+	}
+
+	operations := comparedOperations(results)
+	for _, op := range operations {
+		// Rotate through the endpoints, so they are measured under the same conditions
+		var rotation []interleavedOperation
+		for _, endpoint := range endpoints {
+			clientset, ok := clientsets[endpoint]
+			if !ok {
+				continue
+			}
+			name := endpointOperation(op.name, endpoint)
+			rotation = append(rotation, interleavedOperation{name: name, run: func(ctx context.Context) error { return op.run(ctx, clientset, name) }})
+		}
+		runInterleaved(rotation, iterations, results)
+	}
+
+	stats := results.CalculateStats()
+	fmt.Println("\n--- Endpoint Comparison ---")
+	table := NewTable("Operation", "Endpoint", "Median", "vs Fastest")
+	for _, op := range operations {
+		fastest := -1.0
+		for _, endpoint := range endpoints {
+			if stat, ok := stats[endpointOperation(op.name, endpoint)]; ok {
+				if median := durationMs(stat["median"]); fastest < 0 || median < fastest {
+					fastest = median
+				}
+			}
+		}
+		for _, endpoint := range endpoints {
+			stat, ok := stats[endpointOperation(op.name, endpoint)]
+			if !ok {
+				table.AddRow(op.name, endpoint, "-", "-")
+				continue
+			}
+			median := durationMs(stat["median"])
+			ratio := Cell{Text: "-"}
+			if fastest > 0 {
+				ratio.Text = fmt.Sprintf("%.2fx", median/fastest)
+				if median/fastest >= slowEndpointRatio {
+					ratio.Color = colorRed
+				}
+			}
+			table.AddCells(Cell{Text: op.name}, Cell{Text: endpoint}, Cell{Text: formatMs(median)}, ratio)
+		}
+	}
+	table.Render(os.Stdout)
+}
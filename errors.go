@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrorSummary aggregates all errors of one class encountered by an operation
+type ErrorSummary struct {
+	Operation       string    `json:"operation"`
+	Class           string    `json:"class"`
+	Count           int       `json:"count"`
+	FirstOccurrence time.Time `json:"first_occurrence"`
+	LastOccurrence  time.Time `json:"last_occurrence"`
+	Message         string    `json:"message"`
+}
+
+// classifyError maps an error to a coarse class, using the API status reason where available
+func classifyError(err error) string {
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "DeadlineExceeded"
+	case errors.Is(err, context.Canceled):
+		return "Canceled"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return "NetworkTimeout"
+		}
+		return "NetworkError"
+	}
+	return "Unknown"
+}
+
+// errorKey identifies the errors of one class encountered by an operation
+func errorKey(operation, class string) string {
+	return operation + "\x00" + class
+}
+
+// AddError records an error of the specified operation that occurred at the given time
+func (br *BenchmarkResults) AddError(operation string, err error, at time.Time) {
+	class := classifyError(err)
+	key := errorKey(operation, class)
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	summary, ok := br.Errors[key]
+	if !ok {
+		summary = &ErrorSummary{
+			Operation:       operation,
+			Class:           class,
+			FirstOccurrence: at,
+			Message:         err.Error(),
+		}
+		br.Errors[key] = summary
+	}
+	summary.Count++
+	summary.LastOccurrence = at
+}
+
+// ErrorSummaries returns all recorded errors ordered by operation and class
+func (br *BenchmarkResults) ErrorSummaries() []ErrorSummary {
+	summaries := make([]ErrorSummary, 0, len(br.Errors))
+	for _, summary := range br.Errors {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Operation != summaries[j].Operation {
+			return summaries[i].Operation < summaries[j].Operation
+		}
+		return summaries[i].Class < summaries[j].Class
+	})
+	return summaries
+}
+
+// PrintErrors prints the error summary, if any errors were recorded
+func (br *BenchmarkResults) PrintErrors() {
+	summaries := br.ErrorSummaries()
+	if len(summaries) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Errors ---")
+	table := NewTable("Operation", "Class", "Count", "First", "Last")
+	for _, summary := range summaries {
+		table.AddCells(
+			Cell{Text: summary.Operation, Color: colorRed},
+			Cell{Text: summary.Class},
+			Cell{Text: fmt.Sprintf("%d", summary.Count)},
+			Cell{Text: summary.FirstOccurrence.Format("15:04:05")},
+			Cell{Text: summary.LastOccurrence.Format("15:04:05")})
+	}
+	table.Render(os.Stdout)
+}
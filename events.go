@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// eventFloodObjects is the number of objects the flooded events are spread across, the first
+	// of which is the one the describe-style query asks for
+	eventFloodObjects = 100
+
+	// eventFloodWorkers is the number of concurrent creates seeding the events
+	eventFloodWorkers = 16
+)
+
+// eventFloodObject returns the name of the i-th object the flooded events refer to
+func eventFloodObject(i int) string {
+	return fmt.Sprintf("k8s-api-bench-event-flood-%d", i%eventFloodObjects)
+}
+
+// seedEvents creates the events from..to-1 of the event flood in the namespace, spread evenly
+// across eventFloodObjects ConfigMaps that don't need to exist
+func seedEvents(clientset *kubernetes.Clientset, namespace, seedSet string, from, to int) error {
+	events := clientset.CoreV1().Events(namespace)
+	indexes := make(chan int)
+	errs := make(chan error, eventFloodWorkers)
+	var wg sync.WaitGroup
+	for w := 0; w < eventFloodWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				now := metav1.NewTime(time.Now())
+				_, err := events.Create(context.TODO(), &corev1.Event{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+						Labels: seedLabels(seedSet),
+					},
+					InvolvedObject: corev1.ObjectReference{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+						Namespace:  namespace,
+						Name:       eventFloodObject(i),
+					},
+					Reason:         "Benchmark",
+					Message:        fmt.Sprintf("Event %d of the k8s-api-bench event flood", i),
+					Type:           corev1.EventTypeNormal,
+					Source:         corev1.EventSource{Component: createdByValue},
+					FirstTimestamp: now,
+					LastTimestamp:  now,
+					Count:          1,
+				}, metav1.CreateOptions{})
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	var err error
+loop:
+	for i := from; i < to; i++ {
+		select {
+		case indexes <- i:
+		case err = <-errs:
+			break loop
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error seeding events: %v", err)
+	}
+	return nil
+}
+
+// benchmarkEventFlood measures the describe-style query of the events of one object, using a
+// field selector on the involved object as "kubectl describe" does, against listing all events
+// of the namespace, first with eventFloodObjects events and then after seeding a flood of count
+// events. Operations are recorded with the number of events in the namespace, e.g. "list events
+// of object (10000 events)" and "list events in namespace (10000 events)".
+func benchmarkEventFlood(clientset *kubernetes.Clientset, namespace string, count, iterations int, results *BenchmarkResults) {
+	const seedSet = "event-flood"
+
+	fmt.Printf("\n--- Event flood benchmark in namespace %s ---\n", namespace)
+	events := clientset.CoreV1().Events(namespace)
+	defer func() {
+		err := events.DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+		if err != nil {
+			fmt.Printf("Error deleting seeded events: %v\n", err)
+		}
+	}()
+
+	// The same selector kubectl describe uses for the events of an object
+	objectSelector := fields.Set{
+		"involvedObject.kind":      "ConfigMap",
+		"involvedObject.name":      eventFloodObject(0),
+		"involvedObject.namespace": namespace,
+	}.AsSelector().String()
+
+	seeded := 0
+	for _, total := range []int{min(eventFloodObjects, count), count} {
+		if total <= seeded {
+			continue
+		}
+		fmt.Printf("Seeding %d events...\n", total-seeded)
+		if err := seedEvents(clientset, namespace, seedSet, seeded, total); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		seeded = total
+
+		objectName := fmt.Sprintf("list events of object (%d events)", total)
+		var matched int
+		runBenchmark(objectName, iterations, func(ctx context.Context) error {
+			list, err := events.List(ctx, metav1.ListOptions{FieldSelector: objectSelector})
+			if err != nil {
+				return err
+			}
+			matched = len(list.Items)
+			return nil
+		}, results)
+
+		namespaceName := fmt.Sprintf("list events in namespace (%d events)", total)
+		var listed int
+		runBenchmark(namespaceName, iterations, func(ctx context.Context) error {
+			list, err := events.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			listed = len(list.Items)
+			return nil
+		}, results)
+		fmt.Printf("Found %d events of the object among %d events\n", matched, listed)
+	}
+}
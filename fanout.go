@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// parseWidths parses a comma-separated list of positive concurrency widths
+func parseWidths(value string) ([]int, error) {
+	var widths []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		width, err := strconv.Atoi(field)
+		if err != nil || width < 1 {
+			return nil, fmt.Errorf("invalid width %q", field)
+		}
+		widths = append(widths, width)
+	}
+	return widths, nil
+}
+
+// benchmarkFanOut seeds ConfigMaps, then per iteration and fan-out width lists them and issues
+// a GET for every listed item from width concurrent workers, like a controller resolving
+// references. The time until all GETs completed is recorded per width, together with the
+// latency of the individual GETs.
+func benchmarkFanOut(clientset *kubernetes.Clientset, namespace string, objects int, widths []int, iterations int, results *BenchmarkResults) {
+	const seedSet = "fan-out"
+
+	fmt.Printf("\n--- Parallel GET fan-out benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	if _, err := seedConfigMaps(clientset, namespace, seedSet, objects, 0); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	for _, width := range widths {
+		name := fmt.Sprintf("GET fan-out (%d objects, width %d)", objects, width)
+		getName := fmt.Sprintf("GET ConfigMap (fan-out width %d)", width)
+		for i := 0; i < iterations; i++ {
+			if results.skipIteration(name) {
+				continue
+			}
+			list, err := configMaps.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+			if err != nil {
+				recordIteration(name, i+1, iterations, time.Now(), 0, err, results)
+				results.recordOutcome(name, err)
+				continue
+			}
+
+			startTime := time.Now()
+			var failed atomic.Int32
+			elapsed := runConcurrently(getName, len(list.Items), width, func(ctx context.Context, item int) error {
+				_, err := configMaps.Get(ctx, list.Items[item].Name, metav1.GetOptions{})
+				if err != nil {
+					failed.Add(1)
+				}
+				return err
+			}, results)
+			if failed.Load() > 0 {
+				err = fmt.Errorf("%d of %d GETs failed", failed.Load(), len(list.Items))
+			}
+			recordIteration(name, i+1, iterations, startTime, elapsed, err, results)
+			results.recordOutcome(name, err)
+		}
+	}
+}
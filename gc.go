@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// waitForEvents waits until the watch delivers an event of the given type for every named
+// object and returns the arrival times of the first and the last of these events
+func waitForEvents(watcher watch.Interface, eventType watch.EventType, names []string) (time.Time, time.Time, error) {
+	pending := make(map[string]bool, len(names))
+	for _, name := range names {
+		pending[name] = true
+	}
+
+	var first time.Time
+	timeout := time.After(watchEventTimeout)
+	for len(pending) > 0 {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return first, time.Time{}, fmt.Errorf("watch closed while waiting for %d %s events", len(pending), eventType)
+			}
+			if event.Type == watch.Error {
+				return first, time.Time{}, fmt.Errorf("watch error: %v", event.Object)
+			}
+			object, ok := event.Object.(metav1.Object)
+			if !ok || event.Type != eventType || !pending[object.GetName()] {
+				continue
+			}
+			delete(pending, object.GetName())
+			if first.IsZero() {
+				first = time.Now()
+			}
+		case <-timeout:
+			return first, time.Time{}, fmt.Errorf("timed out after %v waiting for %d %s events", watchEventTimeout, len(pending), eventType)
+		}
+	}
+	return first, time.Now(), nil
+}
+
+// benchmarkGarbageCollection creates a parent ConfigMap with dependent ConfigMaps referencing it
+// as owner, deletes the parent and measures the time until the garbage collector has deleted
+// the first and all dependents, characterizing the responsiveness of the GC controller
+func benchmarkGarbageCollection(clientset *kubernetes.Clientset, namespace string, dependents, iterations int, results *BenchmarkResults) {
+	const seedSet = "gc"
+	firstName := fmt.Sprintf("garbage collection (first of %d dependents)", dependents)
+	allName := fmt.Sprintf("garbage collection (all %d dependents)", dependents)
+
+	fmt.Printf("\n--- Garbage collection benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	list, err := configMaps.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+	if err != nil {
+		fmt.Printf("Error listing ConfigMaps: %v\n", err)
+		return
+	}
+	watcher, err := configMaps.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:   seedSelector(seedSet),
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer watcher.Stop()
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(allName) {
+			continue
+		}
+		parent, err := configMaps.Create(context.TODO(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+				Labels: seedLabels(seedSet),
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			recordIteration(allName, i+1, iterations, time.Now(), 0, fmt.Errorf("error creating parent: %v", err), results)
+			results.recordOutcome(allName, err)
+			continue
+		}
+
+		ownerReference := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: parent.Name, UID: parent.UID}
+		children := make([]string, 0, dependents)
+		for j := 0; j < dependents && err == nil; j++ {
+			var child *corev1.ConfigMap
+			child, err = configMaps.Create(context.TODO(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            fmt.Sprintf("%s-dependent-%d", parent.Name, j),
+					Labels:          seedLabels(seedSet),
+					OwnerReferences: []metav1.OwnerReference{ownerReference},
+				},
+			}, metav1.CreateOptions{})
+			if err == nil {
+				children = append(children, child.Name)
+			}
+		}
+		if err != nil {
+			recordIteration(allName, i+1, iterations, time.Now(), 0, fmt.Errorf("error creating dependent: %v", err), results)
+			results.recordOutcome(allName, err)
+			continue
+		}
+
+		policy := metav1.DeletePropagationBackground
+		startTime := time.Now()
+		err = configMaps.Delete(context.TODO(), parent.Name, metav1.DeleteOptions{PropagationPolicy: &policy})
+		deletedTime := time.Now()
+		if err != nil {
+			recordIteration(allName, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(allName, err)
+			continue
+		}
+
+		first, last, err := waitForEvents(watcher, watch.Deleted, children)
+		if !first.IsZero() {
+			recordIteration(firstName, i+1, iterations, deletedTime, first.Sub(deletedTime), nil, results)
+		}
+		recordIteration(allName, i+1, iterations, deletedTime, last.Sub(deletedTime), err, results)
+		results.recordOutcome(allName, err)
+	}
+}
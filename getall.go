@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// kubectlChunkSize is the default --chunk-size of kubectl get
+const kubectlChunkSize = 500
+
+// getAllResource is a resource of the "all" category
+type getAllResource struct {
+	resource string
+	client   func(clientset *kubernetes.Clientset) rest.Interface
+}
+
+// getAllResources are the resources of the "all" category in the order kubectl get all lists
+// them
+var getAllResources = []getAllResource{
+	{"pods", coreClient},
+	{"replicationcontrollers", coreClient},
+	{"services", coreClient},
+	{"daemonsets", appsClient},
+	{"deployments", appsClient},
+	{"replicasets", appsClient},
+	{"statefulsets", appsClient},
+	{"horizontalpodautoscalers", func(clientset *kubernetes.Clientset) rest.Interface {
+		return clientset.AutoscalingV2().RESTClient()
+	}},
+	{"cronjobs", func(clientset *kubernetes.Clientset) rest.Interface { return clientset.BatchV1().RESTClient() }},
+	{"jobs", func(clientset *kubernetes.Clientset) rest.Interface { return clientset.BatchV1().RESTClient() }},
+}
+
+// getAllTables lists the resource in the namespace as server-side printed tables in chunks, as
+// kubectl get does, and returns the number of rows
+func getAllTables(ctx context.Context, clientset *kubernetes.Clientset, r getAllResource, namespace string) (int, error) {
+	rows := 0
+	continueToken := ""
+	for {
+		body, err := r.client(clientset).Get().
+			Namespace(namespace).
+			Resource(r.resource).
+			VersionedParams(&metav1.ListOptions{Limit: kubectlChunkSize, Continue: continueToken}, scheme.ParameterCodec).
+			SetHeader("Accept", tableRepresentation.accept).
+			DoRaw(ctx)
+		if err != nil {
+			return rows, fmt.Errorf("error listing %s: %v", r.resource, err)
+		}
+		table := &metav1.Table{}
+		if err := runtime.DecodeInto(tableRepresentation.decoder, body, table); err != nil {
+			return rows, fmt.Errorf("error decoding %s: %v", r.resource, err)
+		}
+		rows += len(table.Rows)
+		continueToken = table.Continue
+		if continueToken == "" {
+			return rows, nil
+		}
+	}
+}
+
+// benchmarkGetAll issues the list calls of "kubectl get all" in the namespace one after the
+// other, as kubectl does, recording the aggregate as "kubectl get all" and every resource as
+// "kubectl get all (<resource>)"
+func benchmarkGetAll(clientset *kubernetes.Clientset, namespace string, iterations int, results *BenchmarkResults) {
+	const name = "kubectl get all"
+
+	fmt.Println("\n--- kubectl get all ---")
+
+	var rows int
+	runBenchmark(name, iterations, func(ctx context.Context) error {
+		rows = 0
+		for _, r := range getAllResources {
+			startTime := time.Now()
+			n, err := getAllTables(ctx, clientset, r, namespace)
+			if err != nil {
+				return err
+			}
+			results.Add(fmt.Sprintf("%s (%s)", name, r.resource), time.Since(startTime))
+			rows += n
+		}
+		return nil
+	}, results)
+	fmt.Printf("Found %d objects of %d resources in %s\n", rows, len(getAllResources), namespace)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GrafanaAnnotator posts annotations marking the benchmark window to the Grafana HTTP API
+type GrafanaAnnotator struct {
+	url    string
+	token  string
+	tags   []string
+	client *http.Client
+}
+
+// grafanaAnnotation is the request body of the Grafana annotations API
+type grafanaAnnotation struct {
+	Time int64    `json:"time"`
+	Tags []string `json:"tags"`
+	Text string   `json:"text"`
+}
+
+// NewGrafanaAnnotator creates an annotator for the Grafana instance at baseURL, tagging every
+// annotation with the run ID, the cluster and the run labels
+func NewGrafanaAnnotator(baseURL, token string, metadata RunMetadata) *GrafanaAnnotator {
+	tags := []string{"k8s-api-bench", "run-id:" + metadata.RunID, "cluster:" + metadata.Server}
+	for _, key := range sortedKeys(metadata.Labels) {
+		tags = append(tags, key+":"+metadata.Labels[key])
+	}
+	return &GrafanaAnnotator{
+		url:    strings.TrimSuffix(baseURL, "/") + "/api/annotations",
+		token:  token,
+		tags:   tags,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Annotate posts an annotation with the given text at the given time
+func (g *GrafanaAnnotator) Annotate(at time.Time, text string) error {
+	body, err := json.Marshal(grafanaAnnotation{Time: at.UnixMilli(), Tags: g.tags, Text: text})
+	if err != nil {
+		return fmt.Errorf("error encoding annotation: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating annotation request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting annotation: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("grafana returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
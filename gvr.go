@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// gvrFlag is a repeatable command-line flag of group/version/resource triples, also accepting
+// comma-separated lists. Resources of the core group are given as version/resource.
+type gvrFlag []schema.GroupVersionResource
+
+func (g *gvrFlag) String() string {
+	if g == nil {
+		return ""
+	}
+	values := make([]string, 0, len(*g))
+	for _, gvr := range *g {
+		values = append(values, gvrPath(gvr))
+	}
+	return strings.Join(values, ",")
+}
+
+func (g *gvrFlag) Set(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.Trim(strings.TrimSpace(entry), "/")
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "/")
+		var gvr schema.GroupVersionResource
+		switch len(parts) {
+		case 2:
+			gvr = schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}
+		case 3:
+			gvr = schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}
+		default:
+			return fmt.Errorf("expected group/version/resource or version/resource, got %q", entry)
+		}
+		if gvr.Version == "" || gvr.Resource == "" {
+			return fmt.Errorf("expected group/version/resource or version/resource, got %q", entry)
+		}
+		*g = append(*g, gvr)
+	}
+	return nil
+}
+
+// gvrPath formats the resource as group/version/resource, or version/resource for the core group
+func gvrPath(gvr schema.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Version + "/" + gvr.Resource
+	}
+	return gvr.Group + "/" + gvr.Version + "/" + gvr.Resource
+}
+
+// benchmarkGVRs lists every resource across all namespaces through the dynamic client, recorded
+// as "list <group/version/resource>", so any resource including bespoke CRDs can be benchmarked
+// without code changes
+func benchmarkGVRs(config *rest.Config, gvrs []schema.GroupVersionResource, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- Dynamic resource lists ---")
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating dynamic client: %v\n", err)
+		return
+	}
+
+	for _, gvr := range gvrs {
+		name := "list " + gvrPath(gvr)
+		runBenchmark(name, iterations, func(ctx context.Context) error {
+			_, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+			return err
+		}, results)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGVRFlagSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []schema.GroupVersionResource
+		wantErr bool
+	}{
+		{
+			name:   "group, version and resource",
+			values: []string{"apps/v1/deployments"},
+			want:   []schema.GroupVersionResource{{Group: "apps", Version: "v1", Resource: "deployments"}},
+		},
+		{
+			name:   "core group",
+			values: []string{"v1/pods"},
+			want:   []schema.GroupVersionResource{{Version: "v1", Resource: "pods"}},
+		},
+		{
+			name:   "comma-separated with spaces and slashes",
+			values: []string{" /v1/pods/ , batch/v1/jobs,"},
+			want: []schema.GroupVersionResource{
+				{Version: "v1", Resource: "pods"},
+				{Group: "batch", Version: "v1", Resource: "jobs"},
+			},
+		},
+		{
+			name:   "repeated",
+			values: []string{"v1/pods", "apps/v1/deployments"},
+			want: []schema.GroupVersionResource{
+				{Version: "v1", Resource: "pods"},
+				{Group: "apps", Version: "v1", Resource: "deployments"},
+			},
+		},
+		{name: "resource only", values: []string{"pods"}, wantErr: true},
+		{name: "too many parts", values: []string{"a/b/c/d"}, wantErr: true},
+		{name: "empty version", values: []string{"apps//deployments"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var flag gvrFlag
+			var err error
+			for _, value := range tt.values {
+				if err = flag.Set(value); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) error = %v, want error %v", tt.values, err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(flag, tt.want) {
+				t.Errorf("Set(%q) = %v, want %v", tt.values, flag, tt.want)
+			}
+		})
+	}
+}
+
+func TestGVRFlagString(t *testing.T) {
+	flag := gvrFlag{{Version: "v1", Resource: "pods"}, {Group: "apps", Version: "v1", Resource: "deployments"}}
+	if got, want := flag.String(), "v1/pods,apps/v1/deployments"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// minReadyNodeRatio is the share of Ready nodes below which the cluster counts as degraded
+	minReadyNodeRatio = 0.9
+
+	// maxServerErrorRate is the share of apiserver requests answered with 5xx during the error
+	// rate window above which the cluster counts as degraded
+	maxServerErrorRate = 0.05
+
+	// errorRateWindow is the interval over which the apiserver error rate is measured
+	errorRateWindow = 5 * time.Second
+
+	// minErrorRateRequests is the number of requests needed in the window for a meaningful rate
+	minErrorRateRequests = 20
+)
+
+// Health gate modes: refuse to run on a degraded cluster, run but mark the results tainted, or
+// skip the checks
+const (
+	healthGateRefuse = "refuse"
+	healthGateTaint  = "taint"
+	healthGateOff    = "off"
+)
+
+// checkReadyz reports the failed checks if the apiserver's /readyz endpoint is not ready
+func checkReadyz(clientset *kubernetes.Clientset) error {
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/readyz").Param("verbose", "true").DoRaw(context.TODO())
+	if err == nil {
+		return nil
+	}
+	var failed []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if fields := strings.Fields(strings.TrimPrefix(line, "[-]")); strings.HasPrefix(line, "[-]") && len(fields) > 0 {
+			failed = append(failed, fields[0])
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("apiserver not ready, failed checks: %s", strings.Join(failed, ", "))
+	}
+	return fmt.Errorf("apiserver not ready: %v", err)
+}
+
+// readyNodeRatio returns the share of nodes whose Ready condition is true
+func readyNodeRatio(clientset *kubernetes.Clientset) (float64, int, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(nodes.Items) == 0 {
+		return 1, 0, nil
+	}
+	ready := 0
+	for _, node := range nodes.Items {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+	}
+	return float64(ready) / float64(len(nodes.Items)), len(nodes.Items), nil
+}
+
+// apiserverRequestCounts sums the apiserver_request_total counters of the apiserver's metrics
+// into all requests and those answered with a 5xx code
+func apiserverRequestCounts(clientset *kubernetes.Clientset) (float64, float64, error) {
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw(context.TODO())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total, serverErrors float64
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "apiserver_request_total{") {
+			continue
+		}
+		value, err := strconv.ParseFloat(line[strings.LastIndex(line, " ")+1:], 64)
+		if err != nil {
+			continue
+		}
+		total += value
+		if strings.Contains(line, `code="5`) {
+			serverErrors += value
+		}
+	}
+	return total, serverErrors, scanner.Err()
+}
+
+// serverErrorRate measures the share of apiserver requests answered with 5xx over errorRateWindow,
+// reporting false if there were too few requests or the metrics are not readable
+func serverErrorRate(clientset *kubernetes.Clientset) (float64, bool) {
+	totalBefore, errorsBefore, err := apiserverRequestCounts(clientset)
+	if err != nil {
+		return 0, false
+	}
+	time.Sleep(errorRateWindow)
+	totalAfter, errorsAfter, err := apiserverRequestCounts(clientset)
+	if err != nil || totalAfter-totalBefore < minErrorRateRequests {
+		return 0, false
+	}
+	return (errorsAfter - errorsBefore) / (totalAfter - totalBefore), true
+}
+
+// checkClusterHealth checks that the apiserver is ready, enough nodes are Ready and the
+// apiserver is not failing requests, and returns the problems found. Checks whose data cannot
+// be read, e.g. for lack of permissions, are skipped.
+func checkClusterHealth(clientset *kubernetes.Clientset) []string {
+	fmt.Println("Checking cluster health")
+	var problems []string
+
+	if err := checkReadyz(clientset); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if ratio, nodes, err := readyNodeRatio(clientset); err != nil {
+		fmt.Printf("Warning: skipping node readiness check: %v\n", err)
+	} else if ratio < minReadyNodeRatio {
+		problems = append(problems, fmt.Sprintf("only %.0f%% of %d nodes are Ready", ratio*100, nodes))
+	}
+
+	if rate, ok := serverErrorRate(clientset); !ok {
+		fmt.Println("Warning: skipping apiserver error rate check, its metrics are not readable or it is idle")
+	} else if rate > maxServerErrorRate {
+		problems = append(problems, fmt.Sprintf("apiserver answered %.1f%% of requests with 5xx", rate*100))
+	}
+	return problems
+}
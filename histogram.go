@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// histogramSubBucketBits is the number of significant bits kept per recorded value, bounding the
+// relative error of reported percentiles to 1/512 (about 3 significant decimal digits)
+const histogramSubBucketBits = 10
+
+// keepRawSamples keeps every recorded duration in addition to the histograms, for raw-sample
+// exports; memory then grows linearly with the number of samples
+var keepRawSamples bool
+
+// Histogram records durations in the log-linear buckets of an HdrHistogram: values below 1024ns
+// are counted exactly, larger ones in buckets whose width grows with the value so that every
+// bucket is at most 1/512 of its value wide. Only buckets that were hit are stored, so memory
+// stays bounded regardless of the number of samples.
+type Histogram struct {
+	Counts map[int]int64 `json:"counts"`
+	Total  int64         `json:"total"`
+	Sum    time.Duration `json:"sum"`
+	Min    time.Duration `json:"min"`
+	Max    time.Duration `json:"max"`
+}
+
+// NewHistogram creates an empty histogram
+func NewHistogram() *Histogram {
+	return &Histogram{Counts: make(map[int]int64)}
+}
+
+// histogramBucket returns the index of the bucket counting the value in nanoseconds
+func histogramBucket(value int64) int {
+	const subBuckets = 1 << histogramSubBucketBits
+	if value < subBuckets {
+		return int(value)
+	}
+	shift := bits.Len64(uint64(value)) - histogramSubBucketBits
+	return shift*subBuckets/2 + int(value>>shift)
+}
+
+// histogramBucketMax returns the highest value in nanoseconds counted by the bucket
+func histogramBucketMax(index int) int64 {
+	const subBuckets = 1 << histogramSubBucketBits
+	if index < subBuckets {
+		return int64(index)
+	}
+	shift := index/(subBuckets/2) - 1
+	mantissa := int64(index - shift*subBuckets/2)
+	return (mantissa+1)<<shift - 1
+}
+
+// Record adds a duration to the histogram
+func (h *Histogram) Record(d time.Duration) {
+	d = max(d, 0)
+	if h.Total == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Counts[histogramBucket(int64(d))]++
+	h.Total++
+	h.Sum += d
+}
+
+// Merge adds all durations recorded by other to the histogram
+func (h *Histogram) Merge(other *Histogram) {
+	if other.Total == 0 {
+		return
+	}
+	if h.Total == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+	for index, count := range other.Counts {
+		h.Counts[index] += count
+	}
+	h.Total += other.Total
+	h.Sum += other.Sum
+}
+
+// Quantile returns the duration below or at which the fraction q of the recorded durations lie
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.Total == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(q*float64(h.Total))), 1)
+
+	indexes := make([]int, 0, len(h.Counts))
+	for index := range h.Counts {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	var seen int64
+	for _, index := range indexes {
+		seen += h.Counts[index]
+		if seen >= rank {
+			return min(max(time.Duration(histogramBucketMax(index)), h.Min), h.Max)
+		}
+	}
+	return h.Max
+}
+
+// Stats returns the same statistics as durationStats, derived from the histogram
+func (h *Histogram) Stats() map[string]time.Duration {
+	return map[string]time.Duration{
+		"min":    h.Min,
+		"max":    h.Max,
+		"avg":    h.Sum / time.Duration(h.Total),
+		"median": h.Quantile(0.5),
+		"p95":    h.Quantile(0.95),
+		"p99":    h.Quantile(0.99),
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramBucket(t *testing.T) {
+	tests := []struct {
+		value int64
+		want  int
+	}{
+		{value: 0, want: 0},
+		{value: 1, want: 1},
+		{value: 1023, want: 1023},
+		{value: 1024, want: 1024},
+		{value: 1025, want: 1024},
+		{value: 2047, want: 1535},
+		{value: 2048, want: 1536},
+		{value: 4096, want: 2048},
+	}
+	for _, tt := range tests {
+		if got := histogramBucket(tt.value); got != tt.want {
+			t.Errorf("histogramBucket(%d) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestHistogramBucketBounds(t *testing.T) {
+	values := []int64{0, 1, 1023, 1024, 1500, 2047, 2048, 123456, int64(time.Millisecond), int64(3 * time.Second), int64(time.Hour)}
+	for _, value := range values {
+		index := histogramBucket(value)
+		upper := histogramBucketMax(index)
+		if upper < value {
+			t.Errorf("bucket %d of %d ends at %d, below the value", index, value, upper)
+		}
+		if index > 0 && histogramBucketMax(index-1) >= value {
+			t.Errorf("bucket %d of %d, but the previous bucket ends at %d", index, value, histogramBucketMax(index-1))
+		}
+		// Buckets are at most 1/512 of their values wide
+		if float64(upper-value) > float64(value)/512 {
+			t.Errorf("bucket %d of %d ends at %d, more than 1/512 above the value", index, value, upper)
+		}
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	histogram := NewHistogram()
+	for i := 1; i <= 100; i++ {
+		histogram.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{q: 0, want: time.Millisecond},
+		{q: 0.5, want: 50 * time.Millisecond},
+		{q: 0.95, want: 95 * time.Millisecond},
+		{q: 0.99, want: 99 * time.Millisecond},
+		{q: 1, want: 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := histogram.Quantile(tt.q)
+		// Quantiles are reported at the end of their bucket
+		if got < tt.want || float64(got-tt.want) > float64(tt.want)/512 {
+			t.Errorf("Quantile(%v) = %v, want %v within 1/512", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	first, second := NewHistogram(), NewHistogram()
+	first.Record(5 * time.Millisecond)
+	first.Record(10 * time.Millisecond)
+	second.Record(time.Millisecond)
+	second.Record(20 * time.Millisecond)
+
+	first.Merge(second)
+	first.Merge(NewHistogram())
+	if first.Total != 4 {
+		t.Errorf("Total = %d, want 4", first.Total)
+	}
+	if first.Min != time.Millisecond || first.Max != 20*time.Millisecond {
+		t.Errorf("Min, Max = %v, %v, want 1ms, 20ms", first.Min, first.Max)
+	}
+	if first.Sum != 36*time.Millisecond {
+		t.Errorf("Sum = %v, want 36ms", first.Sum)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// trendThreshold is the change in percent against the history within which an operation is
+// considered stable
+const trendThreshold = 5.0
+
+// historyMedians is the median over the recent runs of every operation's median latency in
+// milliseconds, loaded from --history-dir; nil without history
+var historyMedians map[string]float64
+
+// loadHistory reads the summaries of the run directories in dir, as written by --out-dir, and
+// returns the median of each operation's median latency over the most recent runs together
+// with the number of runs used
+func loadHistory(dir string, runs int) (map[string]float64, int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading history directory: %v", err)
+	}
+
+	var summaries []Summary
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		summary, err := loadSummary(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			// Directories of interrupted runs have no summary
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Metadata.StartTime.Before(summaries[j].Metadata.StartTime)
+	})
+	if len(summaries) > runs {
+		summaries = summaries[len(summaries)-runs:]
+	}
+
+	medians := make(map[string][]time.Duration)
+	for _, summary := range summaries {
+		for _, op := range summary.Operations {
+			medians[op.Operation] = append(medians[op.Operation], time.Duration(op.MedianMs*float64(time.Millisecond)))
+		}
+	}
+	history := make(map[string]float64, len(medians))
+	for op, durations := range medians {
+		history[op] = durationMs(durationStats(durations)["median"])
+	}
+	return history, len(summaries), nil
+}
+
+// trendCell renders the change of the operation's median against the history as an arrow and
+// percentage, colored like the diff subcommand; empty if the operation has no history
+func trendCell(op string, stat map[string]time.Duration) Cell {
+	historical, ok := historyMedians[op]
+	if !ok {
+		return Cell{}
+	}
+	change := percentChange(historical, durationMs(stat["median"]))
+	arrow := "→"
+	switch {
+	case change > trendThreshold:
+		arrow = "↑"
+	case change < -trendThreshold:
+		arrow = "↓"
+	}
+	cell := changeCell(change, trendThreshold)
+	cell.Text = arrow + " " + cell.Text
+	return cell
+}
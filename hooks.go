@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// hookTimeout bounds the time a hook may take
+const hookTimeout = 5 * time.Minute
+
+// Hook points
+const (
+	hookPreRun        = "pre-run"
+	hookPostRun       = "post-run"
+	hookPreOperation  = "pre-operation"
+	hookPostOperation = "post-operation"
+)
+
+// Hooks run before and after the run and around every operation of the standard iteration loop,
+// to e.g. flush a cache, scale a component or trigger an external profiler. A hook is an
+// HTTP(S) URL receiving a POST or a shell command.
+var (
+	preRunHook        string
+	postRunHook       string
+	preOperationHook  string
+	postOperationHook string
+)
+
+// Outcomes of the run passed to the post-run hook
+const (
+	runSucceeded = "succeeded"
+	runFailed    = "failed"
+)
+
+// hookEvent describes the hook point to the hook, as JSON body of HTTP hooks and as
+// K8S_API_BENCH_* environment variables of command hooks
+type hookEvent struct {
+	Hook      string `json:"hook"`
+	RunID     string `json:"run_id"`
+	Operation string `json:"operation,omitempty"`
+	// Status is the outcome of the run, only passed to the post-run hook
+	Status string `json:"status,omitempty"`
+}
+
+// hookRunID is the run ID passed to the hooks
+var hookRunID string
+
+// runHook runs the hook at the hook point, if one is configured
+func runHook(point, hook, operation string) error {
+	return runHookEvent(hook, hookEvent{Hook: point, RunID: hookRunID, Operation: operation})
+}
+
+// runHookEvent runs the hook with the event, if one is configured
+func runHookEvent(hook string, event hookEvent) error {
+	if hook == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	if strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://") {
+		return postHook(ctx, hook, event)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+	cmd.Env = append(os.Environ(),
+		"K8S_API_BENCH_HOOK="+event.Hook,
+		"K8S_API_BENCH_RUN_ID="+event.RunID,
+		"K8S_API_BENCH_OPERATION="+event.Operation,
+		"K8S_API_BENCH_STATUS="+event.Status)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %v", event.Hook, err)
+	}
+	return nil
+}
+
+// postHook posts the hook event as JSON to url
+func postHook(ctx context.Context, url string, event hookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding %s hook event: %v", event.Hook, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating %s hook request: %v", event.Hook, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s hook failed: %v", event.Hook, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s hook returned %s: %s", event.Hook, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// runPostRunHook runs the post-run hook with the outcome of the run given its exit status,
+// warning if the hook failed
+func runPostRunHook(status int) {
+	outcome := runSucceeded
+	if status != 0 {
+		outcome = runFailed
+	}
+	event := hookEvent{Hook: hookPostRun, RunID: hookRunID, Status: outcome}
+	if err := runHookEvent(postRunHook, event); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// runOperationHook runs the hook around an operation, warning if it failed
+func runOperationHook(point, hook, operation string) {
+	if err := runHook(point, hook, operation); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// kubeconfigIdentity labels the identity of the kubeconfig itself in the identity comparison
+const kubeconfigIdentity = "kubeconfig"
+
+// parseIdentities parses a comma-separated list of user names to impersonate, such as
+// system:serviceaccount:tenant-a:default
+func parseIdentities(value string) []string {
+	var identities []string
+	for _, identity := range strings.Split(value, ",") {
+		if identity = strings.TrimSpace(identity); identity != "" {
+			identities = append(identities, identity)
+		}
+	}
+	return identities
+}
+
+// identityOperation returns the name of an operation run as the given identity
+func identityOperation(operation, identity string) string {
+	return fmt.Sprintf("%s [as %s]", operation, identity)
+}
+
+// flowAssignment is the flow schema and priority level the apiserver classified requests into,
+// as reported by the API Priority and Fairness response headers
+type flowAssignment struct {
+	mu            sync.Mutex
+	flowSchema    string
+	priorityLevel string
+}
+
+// flowAssignmentTransport records the APF response headers of every request
+type flowAssignmentTransport struct {
+	next       http.RoundTripper
+	assignment *flowAssignment
+}
+
+func (t *flowAssignmentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if flowSchema := resp.Header.Get("X-Kubernetes-PF-FlowSchema-UID"); flowSchema != "" {
+		t.assignment.mu.Lock()
+		t.assignment.flowSchema = flowSchema
+		t.assignment.priorityLevel = resp.Header.Get("X-Kubernetes-PF-PriorityLevel-UID")
+		t.assignment.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// newIdentityConfig returns a copy of config that impersonates the identity, unless it is the
+// kubeconfig's own, recording the APF classification of its requests into assignment
+func newIdentityConfig(config *rest.Config, identity string, assignment *flowAssignment) *rest.Config {
+	identityConfig := rest.CopyConfig(config)
+	if identity != kubeconfigIdentity {
+		identityConfig.Impersonate = rest.ImpersonationConfig{UserName: identity}
+	}
+	identityConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &flowAssignmentTransport{next: rt, assignment: assignment}
+	})
+	return identityConfig
+}
+
+// flowControlNames maps the UIDs of flow schemas and priority levels to their names, leaving
+// the map empty if they cannot be listed
+func flowControlNames(clientset *kubernetes.Clientset) map[string]string {
+	names := make(map[string]string)
+	if flowSchemas, err := clientset.FlowcontrolV1().FlowSchemas().List(context.TODO(), metav1.ListOptions{}); err == nil {
+		for _, fs := range flowSchemas.Items {
+			names[string(fs.UID)] = fs.Name
+		}
+	}
+	if levels, err := clientset.FlowcontrolV1().PriorityLevelConfigurations().List(context.TODO(), metav1.ListOptions{}); err == nil {
+		for _, level := range levels.Items {
+			names[string(level.UID)] = level.Name
+		}
+	}
+	return names
+}
+
+// benchmarkIdentities runs the namespaced list operations in namespace as the kubeconfig's own
+// identity and as each impersonated identity in turn, recording them as "<operation> [as
+// <identity>]", and prints the latencies side by side with the flow schema and priority level
+// every identity was classified into, exposing differences in API Priority and Fairness treatment
+func benchmarkIdentities(clientset *kubernetes.Clientset, config *rest.Config, identities []string, namespace string, iterations int, results *BenchmarkResults) {
+	fmt.Printf("\n--- Identity comparison in namespace %s ---\n", namespace)
+
+	identities = append([]string{kubeconfigIdentity}, identities...)
+	clientsets := make(map[string]*kubernetes.Clientset)
+	assignments := make(map[string]*flowAssignment)
+	for _, identity := range identities {
+		assignments[identity] = &flowAssignment{}
+		identityClientset, err := kubernetes.NewForConfig(newIdentityConfig(config, identity, assignments[identity]))
+		if err != nil {
+			fmt.Printf("Error creating client for identity %s: %v\n", identity, err)
+			continue
+		}
+		clientsets[identity] = identityClientset
+	}
+
+	for _, op := range namespacedOperations {
+		// Rotate through the identities, so they are measured under the same conditions
+		var rotation []interleavedOperation
+		for _, identity := range identities {
+			identityClientset, ok := clientsets[identity]
+			if !ok {
+				continue
+			}
+			name := identityOperation(op.name, identity)
+			rotation = append(rotation, interleavedOperation{name: name, run: func(ctx context.Context) error {
+				return op.list(ctx, identityClientset, namespace, name, results)
+			}})
+		}
+		runInterleaved(rotation, iterations, results)
+	}
+
+	names := flowControlNames(clientset)
+	resolve := func(uid string) string {
+		if name, ok := names[uid]; ok {
+			return name
+		}
+		if uid == "" {
+			return "-"
+		}
+		return uid
+	}
+	fmt.Println("\n--- Flow Control Assignment ---")
+	assignmentTable := NewTable("Identity", "Flow Schema", "Priority Level")
+	for _, identity := range identities {
+		assignment := assignments[identity]
+		assignment.mu.Lock()
+		assignmentTable.AddRow(identity, resolve(assignment.flowSchema), resolve(assignment.priorityLevel))
+		assignment.mu.Unlock()
+	}
+	assignmentTable.Render(os.Stdout)
+
+	stats := results.CalculateStats()
+	fmt.Println("\n--- Identity Comparison ---")
+	table := NewTable("Operation", "Identity", "Median", "P95", "vs Kubeconfig")
+	for _, op := range namespacedOperations {
+		baseline := -1.0
+		if stat, ok := stats[identityOperation(op.name, kubeconfigIdentity)]; ok {
+			baseline = durationMs(stat["median"])
+		}
+		for _, identity := range identities {
+			stat, ok := stats[identityOperation(op.name, identity)]
+			if !ok {
+				table.AddRow(op.name, identity, "-", "-", "-")
+				continue
+			}
+			median := durationMs(stat["median"])
+			ratio := Cell{Text: "-"}
+			if baseline > 0 {
+				ratio.Text = fmt.Sprintf("%.2fx", median/baseline)
+				if median/baseline >= slowEndpointRatio {
+					ratio.Color = colorRed
+				}
+			}
+			table.AddCells(Cell{Text: op.name}, Cell{Text: identity}, Cell{Text: formatMs(median)}, Cell{Text: formatMs(durationMs(stat["p95"]))}, ratio)
+		}
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// runKubectl executes kubectl with the given arguments against the benchmarked cluster,
+// discarding its output
+func runKubectl(kubectlPath, kubeconfig string, args ...string) error {
+	cmd := exec.Command(kubectlPath, append([]string{"--kubeconfig", kubeconfig}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("kubectl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// benchmarkKubectlComparison lists the given resources across all namespaces with client-go
+// directly and with "kubectl get -A", alternating between both, and reports the difference of
+// the median latencies as kubectl overhead. kubectl includes process startup, its own discovery
+// (cached on disk) and output formatting, which is exactly what users experience.
+func benchmarkKubectlComparison(clientset *kubernetes.Clientset, kubectlPath, kubeconfig string, resources []string, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- client-go vs kubectl ---")
+
+	if _, err := exec.LookPath(kubectlPath); err != nil {
+		fmt.Printf("Error: kubectl not found: %v\n", err)
+		return
+	}
+
+	for _, resource := range resources {
+		clientGoName := fmt.Sprintf("list %s (cluster-wide, client-go)", resource)
+		kubectlName := fmt.Sprintf("list %s (cluster-wide, kubectl)", resource)
+
+		runInterleaved([]interleavedOperation{
+			{name: clientGoName, run: func(ctx context.Context) error {
+				return listAs(ctx, clientset, resource, fullObjectsRepresentation, clientGoName, results)
+			}},
+			{name: kubectlName, run: func(context.Context) error {
+				return runKubectl(kubectlPath, kubeconfig, "get", resource, "--all-namespaces")
+			}},
+		}, iterations, results)
+
+		stats := results.CalculateStats()
+		clientGoStats, ok := stats[clientGoName]
+		if !ok {
+			continue
+		}
+		kubectlStats, ok := stats[kubectlName]
+		if !ok {
+			continue
+		}
+		results.SetMetric(fmt.Sprintf("kubectl overhead list %s (ms)", resource), durationMs(kubectlStats["median"]-clientGoStats["median"]))
+	}
+}
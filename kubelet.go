@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// benchmarkKubeletProxy fetches the given kubelet path through the apiserver node proxy for up to
+// maxNodes nodes, measuring the apiserver to kubelet proxy path
+func benchmarkKubeletProxy(clientset *kubernetes.Clientset, path string, maxNodes, iterations int, results *BenchmarkResults) {
+	path = strings.TrimPrefix(path, "/")
+	fmt.Printf("\n--- Kubelet proxy benchmark (/%s) ---\n", path)
+
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error listing nodes: %v\n", err)
+		return
+	}
+
+	sampled := nodes.Items
+	if len(sampled) > maxNodes {
+		sampled = sampled[:maxNodes]
+	}
+	fmt.Printf("Benchmarking %d of %d nodes\n", len(sampled), len(nodes.Items))
+
+	for _, node := range sampled {
+		name := fmt.Sprintf("kubelet proxy /%s [%s]", path, node.Name)
+		runBenchmark(name, iterations, func(ctx context.Context) error {
+			body, err := clientset.CoreV1().RESTClient().Get().
+				Resource("nodes").
+				Name(node.Name).
+				SubResource("proxy").
+				Suffix(path).
+				DoRaw(ctx)
+			if err != nil {
+				return err
+			}
+			results.AddSize(name, len(body))
+			return nil
+		}, results)
+	}
+}
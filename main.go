@@ -6,235 +6,679 @@ import (
 	"fmt"
 	"k8s.io/client-go/discovery"
 	"math"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"sync"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 )
 
+// version is set at build time via -ldflags
+var version = "dev"
+
 // BenchmarkResults stores the results of all benchmark operations
 type BenchmarkResults struct {
-	// Map of operation name to slice of durations
+	// Guards Histograms, Digests, Results, Namespaces, Sizes, Timeline, Events, Metrics, Errors and Workers, which are written
+	// concurrently by parallel benchmarks
+	mu sync.Mutex
+
+	// Map of operation name to histogram of durations, from which statistics are calculated
+	Histograms map[string]*Histogram
+
+	// Map of operation name to t-digest of durations, used instead of Histograms with streamingStats
+	Digests map[string]*TDigest
+
+	// Map of operation name to slice of durations, only recorded with keepRawSamples
 	Results map[string][]time.Duration
+
+	// Namespaces each namespaced operation was benchmarked in
+	Namespaces map[string]map[string]bool
+
+	// Response sizes in bytes per operation
+	Sizes map[string]*SizeStats
+
+	// Start time and latency of every successful iteration per operation
+	Timeline map[string][]TimelinePoint
+
+	// Number of timeline points seen per operation, of which Timeline keeps a sample when
+	// maxSamplesPerOp is set
+	timelineSeen map[string]int
+
+	// Latency-vs-concurrency curve of the concurrency ramp
+	Ramp []RampPoint
+
+	// Saturation analysis of the concurrency ramp, if it had enough levels
+	Saturation *SaturationAnalysis
+
+	// Recommended page sizes per resource of the limit sweep
+	PageSizes []PageSizeRecommendation
+
+	// Size and decode time of responses with and without managedFields
+	ManagedFields []ManagedFieldsImpact
+
+	// Client-vs-server latency of requests sampled with --audit-log, set at the end of the run
+	ServerTimings []ServerTiming
+
+	// Notable events during the run, such as credential rotations, on the same clock as Timeline
+	Events []TimelineEvent
+
+	// Operations cut short by the run time budget
+	Incomplete map[string]*IncompleteOperation
+
+	// Time each operation spent backing off from 429 responses
+	Throttled map[string]time.Duration
+
+	// Go runtime activity of the client during the run, set at the end of the run
+	Runtime *ClientRuntime
+
+	// Requests of every operation sent over new and reused connections
+	Connections map[string]*ConnectionCounts
+
+	// Operations stopped by the circuit breaker, and the current streak of consecutive failures
+	// of every operation
+	Skipped        map[string]*SkippedOperation
+	failureStreaks map[string]int
+
+	// Scalar metrics that are not latencies, such as throughput
+	Metrics map[string]float64
+
+	// Elapsed time of the iterations per operation that ran into a deadline
+	Timeouts map[string]*Histogram
+
+	// Errors encountered during the run, keyed by operation and error class
+	Errors map[string]*ErrorSummary
+
+	// Iterations per operation restored from a checkpoint, which are skipped when resuming
+	Resumed map[string]int
+
+	// Samples of concurrently run operations per worker, to expose skew between workers
+	Workers map[string]map[int]*WorkerSamples
+
+	// Optional sink receiving every completed iteration as it happens
+	Stream *StreamWriter
 }
 
 // NewBenchmarkResults creates a new BenchmarkResults instance
 func NewBenchmarkResults() *BenchmarkResults {
 	return &BenchmarkResults{
-		Results: make(map[string][]time.Duration),
+		Histograms:     make(map[string]*Histogram),
+		Digests:        make(map[string]*TDigest),
+		Results:        make(map[string][]time.Duration),
+		Namespaces:     make(map[string]map[string]bool),
+		Sizes:          make(map[string]*SizeStats),
+		Metrics:        make(map[string]float64),
+		Errors:         make(map[string]*ErrorSummary),
+		Skipped:        make(map[string]*SkippedOperation),
+		Throttled:      make(map[string]time.Duration),
+		Connections:    make(map[string]*ConnectionCounts),
+		Timeouts:       make(map[string]*Histogram),
+		Incomplete:     make(map[string]*IncompleteOperation),
+		failureStreaks: make(map[string]int),
+		Timeline:       make(map[string][]TimelinePoint),
+		timelineSeen:   make(map[string]int),
+		Resumed:        make(map[string]int),
+		Workers:        make(map[string]map[int]*WorkerSamples),
 	}
 }
 
 // Add adds a new duration for the specified operation
 func (br *BenchmarkResults) Add(operation string, duration time.Duration) {
-	br.Results[operation] = append(br.Results[operation], duration)
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if streamingStats {
+		digest, ok := br.Digests[operation]
+		if !ok {
+			digest = NewTDigest()
+			br.Digests[operation] = digest
+		}
+		digest.Record(duration)
+		return
+	}
+
+	histogram, ok := br.Histograms[operation]
+	if !ok {
+		histogram = NewHistogram()
+		br.Histograms[operation] = histogram
+	}
+	histogram.Record(duration)
+	if keepRawSamples {
+		switch slot := reservoirSlot(int(histogram.Total), len(br.Results[operation])); {
+		case slot < 0:
+			br.Results[operation] = append(br.Results[operation], duration)
+		case slot < len(br.Results[operation]):
+			br.Results[operation][slot] = duration
+		}
+	}
+}
+
+// Count returns the number of durations recorded for the operation
+func (br *BenchmarkResults) Count(operation string) int {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if histogram, ok := br.Histograms[operation]; ok {
+		return int(histogram.Total)
+	}
+	if digest, ok := br.Digests[operation]; ok {
+		return int(digest.Total)
+	}
+	return 0
+}
+
+// SizeStats accumulates the response sizes of an operation
+type SizeStats struct {
+	TotalBytes int64
+	Count      int
+}
+
+// AddSize records the response size in bytes of the specified operation
+func (br *BenchmarkResults) AddSize(operation string, bytes int) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	stats, ok := br.Sizes[operation]
+	if !ok {
+		stats = &SizeStats{}
+		br.Sizes[operation] = stats
+	}
+	stats.TotalBytes += int64(bytes)
+	stats.Count++
+}
+
+// AvgSize returns the average response size in bytes of the operation, if any was recorded
+func (br *BenchmarkResults) AvgSize(operation string) (int64, bool) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	stats, ok := br.Sizes[operation]
+	if !ok || stats.Count == 0 {
+		return 0, false
+	}
+	return stats.TotalBytes / int64(stats.Count), true
+}
+
+// SetMetric records a scalar metric of the run
+func (br *BenchmarkResults) SetMetric(name string, value float64) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	br.Metrics[name] = value
+}
+
+// PrintMetrics prints the scalar metrics, if any were recorded
+func (br *BenchmarkResults) PrintMetrics() {
+	if len(br.Metrics) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Metrics ---")
+	table := NewTable("Metric", "Value")
+	for _, name := range sortedKeys(br.Metrics) {
+		table.AddRow(name, fmt.Sprintf("%.3f", br.Metrics[name]))
+	}
+	table.Render(os.Stdout)
 }
 
 // Helper function to measure the execution time of a function
-func measureTime(name string, f func() error, results *BenchmarkResults) {
+func measureTime(name string, iteration, iterations int, f func(ctx context.Context) error, results *BenchmarkResults) {
+	defer progress.Done(name)
+
+	// Iterations restored from a checkpoint have already been measured
+	if iteration <= results.ResumedIterations(name) || results.skipIteration(name) {
+		return
+	}
+
+	ctx, throttled := withThrottleCounter(context.Background())
+	ctx, connected := withConnectionCounter(ctx)
 	startTime := time.Now()
-	err := f()
-	duration := time.Since(startTime)
+	err := f(ctx)
+	recordIteration(name, iteration, iterations, startTime, time.Since(startTime), err, results)
+	results.recordOutcome(name, err)
+	results.AddThrottled(name, time.Duration(throttled.Load()))
+	results.AddConnections(name, connected.Counts())
+}
+
+// recordIteration reports a single iteration of an operation that started at startTime and
+// took duration, storing the duration on success and the error otherwise
+func recordIteration(name string, iteration, iterations int, startTime time.Time, duration time.Duration, err error, results *BenchmarkResults) {
+	// Benchmarks with multi-step iterations run again when resuming, but keep the restored samples
+	if iteration <= results.ResumedIterations(name) {
+		return
+	}
+
+	record := IterationRecord{
+		Timestamp:  startTime,
+		Operation:  name,
+		Iteration:  iteration,
+		DurationMs: durationMs(duration),
+	}
+	credentialRefreshes.RecordIteration(startTime, duration)
 
 	if err != nil {
-		fmt.Printf("Error during %s: %v\n", name, err)
+		if summaryTop == 0 {
+			fmt.Printf("Iteration %d/%d: Error during %s: %v\n", iteration, iterations, name, err)
+		}
+		record.Error = err.Error()
+		results.AddError(name, err, startTime)
+		results.AddTimeout(name, err, duration)
 	} else {
-		fmt.Printf("Time to %s: %v\n", name, duration)
+		if summaryTop == 0 {
+			fmt.Printf("Iteration %d/%d: Time to %s: %v\n", iteration, iterations, name, duration)
+		}
 		// Store the duration in the results
 		results.Add(name, duration)
+		results.AddTimelinePoint(name, startTime, duration)
 	}
+
+	results.Stream.Write(record)
 }
 
 // Helper function to run a benchmark operation multiple times
-func runBenchmark(name string, iterations int, f func() error, results *BenchmarkResults) {
-	fmt.Printf("Running benchmark '%s' for %d iterations...\n", name, iterations)
+func runBenchmark(name string, iterations int, f func(ctx context.Context) error, results *BenchmarkResults) {
+	runInterleaved([]interleavedOperation{{name: name, run: f}}, iterations, results)
+}
+
+// interleavedOperation is one of the operations runInterleaved alternates between
+type interleavedOperation struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runInterleaved runs the iterations of the operations like runBenchmark, alternating between
+// them so that they are measured under the same conditions. At a fixed iterationRate every
+// operation keeps its own schedule, so they run one after the other instead.
+func runInterleaved(operations []interleavedOperation, iterations int, results *BenchmarkResults) {
+	for _, op := range operations {
+		if summaryTop == 0 {
+			fmt.Printf("Running benchmark '%s' for %d iterations...\n", op.name, iterations)
+		}
+		runOperationHook(hookPreOperation, preOperationHook, op.name)
+	}
+	defer func() {
+		for _, op := range operations {
+			runOperationHook(hookPostOperation, postOperationHook, op.name)
+		}
+	}()
+
+	if iterationRate > 0 {
+		for _, op := range operations {
+			runAtRate(op.name, iterations, op.run, results)
+		}
+		return
+	}
 	for i := 0; i < iterations; i++ {
-		fmt.Printf("Iteration %d/%d: ", i+1, iterations)
-		measureTime(name, f, results)
+		for _, op := range operations {
+			measureTime(op.name, i+1, iterations, op.run, results)
+		}
 	}
 }
 
 // Calculate statistics for the benchmark results
 func (br *BenchmarkResults) CalculateStats() map[string]map[string]time.Duration {
+	// Estimating quantiles compresses the t-digests, which must not race with recording or checkpointing
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
 	stats := make(map[string]map[string]time.Duration)
 
-	for op, durations := range br.Results {
-		if len(durations) == 0 {
+	for op, histogram := range br.Histograms {
+		if histogram.Total == 0 {
 			continue
 		}
+		stats[op] = histogram.Stats()
+	}
+	for op, digest := range br.Digests {
+		if digest.Total == 0 {
+			continue
+		}
+		stats[op] = digest.Stats()
+	}
 
-		// Sort durations for percentile calculations
-		sort.Slice(durations, func(i, j int) bool {
-			return durations[i] < durations[j]
-		})
-
-		// Calculate statistics
-		var sum time.Duration
-		min := durations[0]
-		max := durations[0]
+	return stats
+}
 
-		for _, d := range durations {
-			sum += d
-			if d < min {
-				min = d
-			}
-			if d > max {
-				max = d
-			}
+// durationStats calculates min, max, avg, median, p95 and p99 of a non-empty set of durations
+func durationStats(durations []time.Duration) map[string]time.Duration {
+	// Sort a copy of the durations for percentile calculations, keeping the recording order intact
+	durations = append([]time.Duration(nil), durations...)
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+
+	// Calculate statistics
+	var sum time.Duration
+	min := durations[0]
+	max := durations[0]
+
+	for _, d := range durations {
+		sum += d
+		if d < min {
+			min = d
 		}
+		if d > max {
+			max = d
+		}
+	}
 
-		avg := sum / time.Duration(len(durations))
+	avg := sum / time.Duration(len(durations))
 
-		// Calculate median (50th percentile)
-		median := durations[len(durations)/2]
-		if len(durations)%2 == 0 {
-			median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
-		}
+	// Calculate median (50th percentile)
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
+	}
 
-		// Calculate 95th percentile
-		p95Index := int(math.Ceil(float64(len(durations))*0.95)) - 1
-		if p95Index >= len(durations) {
-			p95Index = len(durations) - 1
+	// Calculate the 95th and 99th percentile
+	percentile := func(p float64) time.Duration {
+		index := int(math.Ceil(float64(len(durations))*p)) - 1
+		if index >= len(durations) {
+			index = len(durations) - 1
 		}
-		p95 := durations[p95Index]
+		return durations[index]
+	}
+	p95 := percentile(0.95)
+	p99 := percentile(0.99)
+
+	return map[string]time.Duration{
+		"min":    min,
+		"max":    max,
+		"avg":    avg,
+		"median": median,
+		"p95":    p95,
+		"p99":    p99,
+	}
+}
+
+// durationUnit is the unit durations are rendered in: auto, us, ms or s
+var durationUnit = "auto"
 
-		// Store statistics
-		stats[op] = map[string]time.Duration{
-			"min":    min,
-			"max":    max,
-			"avg":    avg,
-			"median": median,
-			"p95":    p95,
+// validDurationUnit reports whether the unit is a known --unit value
+func validDurationUnit(unit string) bool {
+	switch unit {
+	case "auto", "us", "ms", "s":
+		return true
+	}
+	return false
+}
+
+// formatDuration formats a time.Duration with one decimal place in durationUnit. The auto unit
+// picks µs below a millisecond and s from a second on, so sub-millisecond health checks keep
+// their precision and multi-second lists stay readable.
+func formatDuration(d time.Duration) string {
+	unit := durationUnit
+	if unit == "auto" {
+		switch magnitude := d.Abs(); {
+		case magnitude < time.Millisecond:
+			unit = "us"
+		case magnitude >= time.Second:
+			unit = "s"
+		default:
+			unit = "ms"
 		}
 	}
 
-	return stats
+	switch unit {
+	case "us":
+		return fmt.Sprintf("%.1f µs", float64(d)/float64(time.Microsecond))
+	case "s":
+		return fmt.Sprintf("%.2f s", d.Seconds())
+	}
+	return fmt.Sprintf("%.1f ms", float64(d)/float64(time.Millisecond))
 }
 
-// formatDuration formats a time.Duration to show only one decimal place in milliseconds
-func formatDuration(d time.Duration) string {
-	// Convert to milliseconds with one decimal place
-	ms := float64(d.Microseconds()) / 1e3
-	return fmt.Sprintf("%.1f ms", ms)
+// formatMs formats a duration given in milliseconds like formatDuration
+func formatMs(ms float64) string {
+	return formatDuration(time.Duration(ms * float64(time.Millisecond)))
 }
 
-// Print the statistics in a readable format
-func (br *BenchmarkResults) PrintStats() {
-	stats := br.CalculateStats()
+// durationMs converts a time.Duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1e3
+}
+
+// sortedKeys returns the keys of the map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-	// Sort operations for consistent output
+// sortedOperations returns the operation names of the statistics in a consistent order
+func sortedOperations(stats map[string]map[string]time.Duration) []string {
 	operations := make([]string, 0, len(stats))
 	for op := range stats {
 		operations = append(operations, op)
 	}
 	sort.Strings(operations)
+	return operations
+}
 
-	// Calculate the maximum length of operation names
-	maxOpLength := 0
-	for _, op := range operations {
-		if len(op) > maxOpLength {
-			maxOpLength = len(op)
-		}
+// formatBytes formats a size in bytes using binary units
+func formatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
 	}
+	return fmt.Sprintf("%d B", bytes)
+}
 
-	// Add some padding to the maximum length
-	opColWidth := maxOpLength + 2
-
-	// Define column width for time values
-	timeColWidth := 12
+// Print the statistics in a readable format
+func (br *BenchmarkResults) PrintStats() {
+	stats := br.CalculateStats()
 
 	fmt.Println("\n--- Benchmark Statistics ---")
 
-	// Create the header with dynamic width
-	headerFormat := fmt.Sprintf("%%-%ds | %%%ds | %%%ds | %%%ds | %%%ds | %%%ds\n",
-		opColWidth, timeColWidth, timeColWidth, timeColWidth, timeColWidth, timeColWidth)
-	fmt.Printf(headerFormat, "Operation", "Min", "Max", "Avg", "Median", "P95")
+	// Only show the response size column by default if sizes were recorded
+	columns := selectedColumns
+	if len(columns) == 0 {
+		columns = defaultColumns
+		if len(br.Sizes) > 0 {
+			columns = append(columns[:len(columns):len(columns)], "size")
+		}
+		if len(br.Throttled) > 0 {
+			columns = append(columns[:len(columns):len(columns)], "throttled")
+		}
+		// A single connection reused throughout the run is not worth a column
+		if connections.Counts().New > 1 {
+			columns = append(columns[:len(columns):len(columns)], "reuse")
+		}
+		if historyMedians != nil {
+			columns = append(columns[:len(columns):len(columns)], "trend")
+		}
+	}
 
-	// Create the separator line with dynamic width
-	separatorLine := strings.Repeat("-", opColWidth) + "-+" +
-		strings.Repeat("-", timeColWidth+2) + "+" +
-		strings.Repeat("-", timeColWidth+2) + "+" +
-		strings.Repeat("-", timeColWidth+2) + "+" +
-		strings.Repeat("-", timeColWidth+2) + "+" +
-		strings.Repeat("-", timeColWidth+2)
-	fmt.Println(separatorLine)
+	headers := []string{"Operation"}
+	for _, name := range columns {
+		headers = append(headers, statsColumns[name].header)
+	}
 
-	// Create the row format with dynamic width
-	rowFormat := fmt.Sprintf("%%-%ds | %%%ds | %%%ds | %%%ds | %%%ds | %%%ds\n",
-		opColWidth, timeColWidth, timeColWidth, timeColWidth, timeColWidth, timeColWidth)
+	operations := sortedOperations(stats)
+	if summaryTop > 0 {
+		operations = br.slowestOperations(operations, stats)
+	} else {
+		br.sortRows(operations, stats)
+	}
 
+	table := NewTable(headers...)
 	for _, op := range operations {
 		stat := stats[op]
-		fmt.Printf(rowFormat,
-			op,
-			formatDuration(stat["min"]),
-			formatDuration(stat["max"]),
-			formatDuration(stat["avg"]),
-			formatDuration(stat["median"]),
-			formatDuration(stat["p95"]))
+
+		// Highlight operations whose tail latency exceeds the slow threshold
+		color := ""
+		if stat["p95"] > slowThreshold {
+			color = colorRed
+		}
+
+		cells := []Cell{{Text: op, Color: color}}
+		for _, name := range columns {
+			cells = append(cells, statsColumns[name].cell(br, op, stat, color))
+		}
+		table.AddCells(cells...)
+	}
+	table.Render(os.Stdout)
+
+	if summaryTop > 0 {
+		br.printTotals(len(operations), len(stats))
 	}
 }
 
-// List pods in a namespace (used for tab completion)
-func listPods(clientset *kubernetes.Clientset, namespace string) error {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+// fetchAndDecode performs the request and decodes the response body into obj. The time spent
+// receiving the response and the time spent decoding it are recorded separately as
+// "<name> (network)" and "<name> (decode)", so slow operations can be attributed to either the
+// apiserver or client-side deserialization.
+func fetchAndDecode(ctx context.Context, req *rest.Request, decoder runtime.Decoder, obj runtime.Object, name string, results *BenchmarkResults) error {
+	startTime := time.Now()
+	body, err := req.DoRaw(ctx)
 	if err != nil {
 		return err
 	}
+	networkDuration := time.Since(startTime)
+
+	startTime = time.Now()
+	if err := runtime.DecodeInto(decoder, body, obj); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	decodeDuration := time.Since(startTime)
+
+	results.Add(name+" (network)", networkDuration)
+	results.Add(name+" (decode)", decodeDuration)
+	results.AddSize(name, len(body))
+	return nil
+}
+
+// listNamespaced lists the given core or apps resource in a namespace and decodes it into obj
+func listNamespaced(ctx context.Context, client rest.Interface, resource, namespace string, obj runtime.Object, name string, results *BenchmarkResults) error {
+	req := client.Get().
+		Namespace(namespace).
+		Resource(resource).
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec)
+	return fetchAndDecode(ctx, req, scheme.Codecs.UniversalDeserializer(), obj, name, results)
+}
+
+// List pods in a namespace (used for tab completion)
+func listPods(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	pods := &corev1.PodList{}
+	if err := listNamespaced(ctx, clientset.CoreV1().RESTClient(), "pods", namespace, pods, name, results); err != nil {
+		return err
+	}
 
-	fmt.Printf("Found %d pods in namespace %s\n", len(pods.Items), namespace)
+	fmt.Printf("Found %d pods in %s\n", len(pods.Items), describeNamespace(namespace))
 	return nil
 }
 
 // List deployments in a namespace (used for tab completion)
-func listDeployments(clientset *kubernetes.Clientset, namespace string) error {
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
+func listDeployments(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	deployments := &appsv1.DeploymentList{}
+	if err := listNamespaced(ctx, clientset.AppsV1().RESTClient(), "deployments", namespace, deployments, name, results); err != nil {
 		return err
 	}
 
-	fmt.Printf("Found %d deployments in namespace %s\n", len(deployments.Items), namespace)
+	fmt.Printf("Found %d deployments in %s\n", len(deployments.Items), describeNamespace(namespace))
 	return nil
 }
 
 // List services in a namespace (used for tab completion)
-func listServices(clientset *kubernetes.Clientset, namespace string) error {
-	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
+func listServices(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	services := &corev1.ServiceList{}
+	if err := listNamespaced(ctx, clientset.CoreV1().RESTClient(), "services", namespace, services, name, results); err != nil {
 		return err
 	}
 
-	fmt.Printf("Found %d services in namespace %s\n", len(services.Items), namespace)
+	fmt.Printf("Found %d services in %s\n", len(services.Items), describeNamespace(namespace))
 	return nil
 }
 
 // List ConfigMaps in a namespace (used for tab completion)
-func listConfigMaps(clientset *kubernetes.Clientset, namespace string) error {
-	configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
+func listConfigMaps(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := listNamespaced(ctx, clientset.CoreV1().RESTClient(), "configmaps", namespace, configMaps, name, results); err != nil {
 		return err
 	}
 
-	fmt.Printf("Found %d ConfigMaps in namespace %s\n", len(configMaps.Items), namespace)
+	fmt.Printf("Found %d ConfigMaps in %s\n", len(configMaps.Items), describeNamespace(namespace))
 	return nil
 }
 
 // List Secrets in a namespace (used for tab completion)
-func listSecrets(clientset *kubernetes.Clientset, namespace string) error {
-	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
+func listSecrets(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	secrets := &corev1.SecretList{}
+	if err := listNamespaced(ctx, clientset.CoreV1().RESTClient(), "secrets", namespace, secrets, name, results); err != nil {
+		return err
+	}
+
+	fmt.Printf("Found %d Secrets in %s\n", len(secrets.Items), describeNamespace(namespace))
+	return nil
+}
+
+// List HorizontalPodAutoscalers in a namespace
+func listHorizontalPodAutoscalers(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := listNamespaced(ctx, clientset.AutoscalingV2().RESTClient(), "horizontalpodautoscalers", namespace, hpas, name, results); err != nil {
+		return err
+	}
+
+	fmt.Printf("Found %d HorizontalPodAutoscalers in %s\n", len(hpas.Items), describeNamespace(namespace))
+	return nil
+}
+
+// List PodDisruptionBudgets in a namespace
+func listPodDisruptionBudgets(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error {
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := listNamespaced(ctx, clientset.PolicyV1().RESTClient(), "poddisruptionbudgets", namespace, pdbs, name, results); err != nil {
+		return err
+	}
+
+	fmt.Printf("Found %d PodDisruptionBudgets in %s\n", len(pdbs.Items), describeNamespace(namespace))
+	return nil
+}
+
+// listClusterScoped lists the given cluster-scoped resource and decodes it into obj
+func listClusterScoped(ctx context.Context, client rest.Interface, resource string, obj runtime.Object, name string, results *BenchmarkResults) error {
+	req := client.Get().
+		Resource(resource).
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec)
+	return fetchAndDecode(ctx, req, scheme.Codecs.UniversalDeserializer(), obj, name, results)
+}
+
+// List PriorityClasses
+func listPriorityClasses(ctx context.Context, clientset *kubernetes.Clientset, results *BenchmarkResults) error {
+	priorityClasses := &schedulingv1.PriorityClassList{}
+	if err := listClusterScoped(ctx, clientset.SchedulingV1().RESTClient(), "priorityclasses", priorityClasses, "list PriorityClasses", results); err != nil {
+		return err
+	}
+
+	fmt.Printf("Found %d PriorityClasses\n", len(priorityClasses.Items))
+	return nil
+}
+
+// List StorageClasses
+func listStorageClasses(ctx context.Context, clientset *kubernetes.Clientset, results *BenchmarkResults) error {
+	storageClasses := &storagev1.StorageClassList{}
+	if err := listClusterScoped(ctx, clientset.StorageV1().RESTClient(), "storageclasses", storageClasses, "list StorageClasses", results); err != nil {
 		return err
 	}
 
-	fmt.Printf("Found %d Secrets in namespace %s\n", len(secrets.Items), namespace)
+	fmt.Printf("Found %d StorageClasses\n", len(storageClasses.Items))
 	return nil
 }
 
@@ -275,7 +719,7 @@ func listAllAPIResources(clientset *kubernetes.Clientset) error {
 }
 
 // List Custom Resource Definitions (used for tab completion)
-func listCRDs(config *rest.Config) error {
+func listCRDs(ctx context.Context, config *rest.Config, results *BenchmarkResults) error {
 	// Create the apiextensions clientset
 	apiextensionsClient, err := apiextensionsclientset.NewForConfig(config)
 	if err != nil {
@@ -283,130 +727,1360 @@ func listCRDs(config *rest.Config) error {
 	}
 
 	// List CRDs
-	crds, err := apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions().List(context.TODO(), metav1.ListOptions{})
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	req := apiextensionsClient.ApiextensionsV1().RESTClient().Get().
+		Resource("customresourcedefinitions").
+		VersionedParams(&metav1.ListOptions{}, apiextensionsscheme.ParameterCodec)
+	err = fetchAndDecode(ctx, req, apiextensionsscheme.Codecs.UniversalDeserializer(), crds, "list Custom Resource Definitions", results)
 	if err != nil {
-		return fmt.Errorf("error listing CRDs: %v", err)
+		return fmt.Errorf("error listing CRDs: %w", err)
 	}
 
 	fmt.Printf("Found %d Custom Resource Definitions\n", len(crds.Items))
 	return nil
 }
 
+// namespacedOperations are the operations benchmarked in every selected namespace
+var namespacedOperations = []struct {
+	name         string
+	groupVersion string
+	resource     string
+	list         func(ctx context.Context, clientset *kubernetes.Clientset, namespace, name string, results *BenchmarkResults) error
+}{
+	{"list pods", "v1", "pods", listPods},
+	{"list deployments", "apps/v1", "deployments", listDeployments},
+	{"list services", "v1", "services", listServices},
+	{"list ConfigMaps", "v1", "configmaps", listConfigMaps},
+	{"list Secrets", "v1", "secrets", listSecrets},
+	{"list HorizontalPodAutoscalers", "autoscaling/v2", "horizontalpodautoscalers", listHorizontalPodAutoscalers},
+	{"list PodDisruptionBudgets", "policy/v1", "poddisruptionbudgets", listPodDisruptionBudgets},
+}
+
+// namespacedOperation returns the name under which an operation in a namespace is recorded
+func namespacedOperation(operation, namespace string) string {
+	return fmt.Sprintf("%s [%s]", operation, namespace)
+}
+
+// clusterWideOperation returns the name under which the cluster-wide variant of a namespaced
+// operation is recorded
+func clusterWideOperation(operation string) string {
+	return operation + " (cluster-wide)"
+}
+
+// describeNamespace returns a human-readable description of the namespace scope of a request
+func describeNamespace(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return "all namespaces"
+	}
+	return "namespace " + namespace
+}
+
+// benchmarkClusterWide runs all namespaced operations across all namespaces at once, like
+// "kubectl get -A" and most dashboards do
+func benchmarkClusterWide(clientset *kubernetes.Clientset, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- Cluster-wide list operations ---")
+
+	for _, op := range namespacedOperations {
+		if !operationSelected(op.name) {
+			continue
+		}
+		name := clusterWideOperation(op.name)
+		runBenchmark(name, iterations, func(ctx context.Context) error {
+			return op.list(ctx, clientset, metav1.NamespaceAll, name, results)
+		}, results)
+	}
+}
+
+// benchmarkNamespace runs all namespaced operations in the given namespace
+func benchmarkNamespace(clientset *kubernetes.Clientset, namespace string, iterations int, results *BenchmarkResults) {
+	fmt.Printf("\n--- Benchmarking namespace: %s ---\n", namespace)
+
+	for _, op := range namespacedOperations {
+		if !operationSelected(op.name) {
+			continue
+		}
+		name := namespacedOperation(op.name, namespace)
+		results.TrackNamespace(op.name, namespace)
+		runBenchmark(name, iterations, func(ctx context.Context) error {
+			return op.list(ctx, clientset, namespace, name, results)
+		}, results)
+	}
+}
+
 func main() {
+	os.Exit(run())
+}
+
+// run runs the subcommand or the benchmark and returns the exit status, so that the deferred
+// cleanups have run by the time the process exits
+func run() (status int) {
+	// Dispatch subcommands before parsing the benchmark flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "merge":
+			return runMerge(os.Args[2:])
+		case "diff":
+			return runDiff(os.Args[2:])
+		case "repl":
+			return runRepl(os.Args[2:])
+		case "version":
+			return runVersion(os.Args[2:])
+		case "list-ops":
+			return runListOps(os.Args[2:])
+		case "cleanup":
+			return runCleanup(os.Args[2:])
+		case "validate":
+			os.Args = validateArgs(os.Args[2:])
+			validateOnly = true
+		}
+	}
+
 	// Define command-line flags
 	var kubeconfig string
 	var iterations int
+	var streamOutput string
+	var runID string
+	var networkBaseline int
+	var auditLogPath string
+	var auditSample float64
+	var endpointList string
+	var impersonateList string
+	var impersonateNamespace string
+	var tenants int
+	var tenantUsers string
+	var tenantNamespace string
+	var grafanaURL string
+	var resultsWebhook string
+	var resultsNamespace string
+	var checkpointPath string
+	var checkpointInterval time.Duration
+	var resume string
+	var scheduleExpr string
+	var controlAddr string
+	var scenarioPath string
+	var suite string
+	var suiteSelection string
+	var suiteParallelism int
+	var profile string
+	var warmup int
+	var operations string
+	var summaryOutput string
+	webhookHeaders := headersFlag{}
+	labels := labelsFlag{}
+	var gvrs gvrFlag
+	var outDir string
+	var historyDir string
+	var ci bool
+	var strict bool
+	var baselinePath string
+	var regressionThreshold float64
+	var junitOutput string
+	var tapOutput string
+	var healthGate string
+	var maxRuntime time.Duration
+	var historyRuns int
+	var noColor bool
+	var verbosity int
+	var namespaceRegex string
+	var namespaceExcludeRegex string
+	var maxNamespaces int
+	var namespaceSample string
+	var namespaceParallelism int
+	var clusterWideLists bool
+	var limitSweep string
+	var limitSweepResources string
+	var payloadSizes string
+	var payloadObjects int
+	var seedNamespace string
+	var seedTemplate string
+	var keepNamespace bool
+	var keepSeeded bool
+	var seedCount int
+	var seedSize string
+	var metadataLists string
+	var tableLists string
+	var managedFieldsLists string
+	var kubectlCompletion string
+	var completionNamespace string
+	var getAllNamespace string
+	var watchLatency bool
+	var watchThroughputEvents int
+	var watchers int
+	var crdConversion bool
+	var crdRegistration bool
+	var discoveryCache bool
+	var apiResourcesBenchmark bool
+	var discoveryStages bool
+	var admissionNamespace string
+	var admissionLabels string
+	var tokenServiceAccount string
+	var csrBenchmark bool
+	var aggregatedAPIs bool
+	var scaleSubresource bool
+	var gcDependents int
+	var columnList string
+	var finalizerLatency bool
+	var deletionPropagation bool
+	var rolloutReplicas int
+	var bulkCreate int
+	var bulkCreateWorkers int
+	var eventFlood int
+	var fanOutWidths string
+	var fanOutObjects int
+	var cancelAfter string
+	var rampLevels string
+	var rampRequests int
+	var cancelObjects int
+	var kubeletProxyPath string
+	var kubeletProxyNodes int
+	var apiProxy string
+	var httpProxy string
+	var kubectlCompare string
+	var kubectlPath string
+	var qps float64
+	var burst int
 
 	// If the kubeconfig flag is not provided, use the default path
 	home := homedir.HomeDir()
 	if home == "" {
 		fmt.Println("Error: unable to find home directory")
-		os.Exit(1)
+		return 1
 	}
 	defaultKubeconfig := filepath.Join(home, ".kube", "config")
 
 	// Set up the flags
 	flag.StringVar(&kubeconfig, "kubeconfig", defaultKubeconfig, "Path to the kubeconfig file")
 	flag.IntVar(&iterations, "iterations", 1, "Number of iterations for each benchmark operation")
+	flag.DurationVar(&maxRuntime, "max-runtime", 0, "Hard cap on the total run time, e.g. 30m; once exhausted no further iterations start and the remaining operations are reported as incomplete (0 for no cap)")
+	flag.IntVar(&maxConsecutiveFailures, "max-consecutive-failures", 0, "Skip the remaining iterations of an operation after this many consecutive failures, reporting it as SKIPPED-after-errors (0 to never skip)")
+	flag.StringVar(&streamOutput, "stream-output", "", "Write a JSON line per completed iteration to this file (\"-\" for stdout)")
+	flag.StringVar(&outDir, "out-dir", "", "Write all run artifacts into a subdirectory of this directory named after the run ID")
+	flag.StringVar(&historyDir, "history-dir", "", "Directory of previous runs written by --out-dir, to show each operation's trend against their median")
+	flag.IntVar(&historyRuns, "history-runs", 10, "Number of most recent runs in --history-dir the trend is computed against")
+	flag.StringVar(&runID, "run-id", "", "Identifier recorded with every result (default: start timestamp plus a random suffix)")
+	flag.Var(labels, "label", "Label recorded with every result as key=value (repeatable)")
+	flag.BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled by the NO_COLOR environment variable)")
+	flag.DurationVar(&slowThreshold, "slow-threshold", slowThreshold, "Highlight operations whose P95 latency exceeds this duration")
+	flag.StringVar(&columnList, "columns", "", "Comma-separated columns of the statistics table: count, min, max, avg, p50, p95, p99, err%, size, throttled, reuse, trend (default min,max,avg,p50,p95,p99)")
+	flag.DurationVar(&progressInterval, "progress-interval", progressInterval, "Report the percentage of planned iterations completed and the estimated time remaining at most this often (0 to disable)")
+	flag.IntVar(&summaryTop, "summary-top", 0, "Only print the N slowest operations and overall totals instead of the full statistics and per-iteration output (0 to print everything)")
+	flag.StringVar(&durationUnit, "unit", durationUnit, "Unit durations are shown in: auto (µs, ms or s depending on magnitude), us, ms or s")
+	flag.StringVar(&sortBy, "sort-by", sortBy, "Sort the statistics table by name or by any column")
+	flag.BoolVar(&sortDescending, "desc", false, "Sort the statistics table in descending order")
+	flag.Float64Var(&iterationRate, "rate", 0, "Fixed rate in iterations per second at which operations are issued, reporting latencies corrected for coordinated omission (0 to run iterations back to back)")
+	flag.BoolVar(&keepRawSamples, "raw-samples", false, "Keep every sample in addition to the latency histograms and export them as samples.json; memory grows with the number of samples")
+	flag.IntVar(&maxSamplesPerOp, "max-samples-per-op", 0, "Keep at most this many raw samples and timeline points per operation, chosen by reservoir sampling (0 for no limit)")
+	flag.DurationVar(&statsWindow, "stats-window", 0, "Additionally calculate percentiles per time window of this length, printed as each window ends (e.g. 30s, 0 to disable)")
+	flag.BoolVar(&streamingStats, "streaming-stats", false, "Estimate percentiles with constant-memory t-digests and skip the per-iteration timeline, for multi-hour continuous runs")
+	flag.IntVar(&verbosity, "v", 0, fmt.Sprintf("Log verbosity, %d or higher logs every API request", requestLogLevel))
+	flag.StringVar(&namespaceRegex, "namespace-regex", "", "Only benchmark namespaces matching this regular expression")
+	flag.StringVar(&namespaceExcludeRegex, "namespace-exclude-regex", "", "Skip namespaces matching this regular expression")
+	flag.IntVar(&maxNamespaces, "max-namespaces", 0, "Benchmark at most this many namespaces (0 for all)")
+	flag.StringVar(&namespaceSample, "namespace-sample", sampleFirst, "How to pick namespaces when --max-namespaces is exceeded: first, random or largest (by pod count)")
+	flag.IntVar(&namespaceParallelism, "namespace-parallelism", 1, "Number of namespaces benchmarked concurrently")
+	flag.BoolVar(&clusterWideLists, "cluster-wide-lists", false, "Also benchmark listing the namespaced resources across all namespaces, which can be expensive on large clusters")
+	flag.StringVar(&limitSweep, "limit-sweep", "", "Comma-separated page sizes to benchmark paginated cluster-wide lists with, 0 or \"unlimited\" for no limit (e.g. 100,500,1000,0)")
+	flag.StringVar(&limitSweepResources, "limit-sweep-resources", "pods", "Comma-separated resources used by --limit-sweep (pods, services, configmaps, secrets, deployments)")
+	flag.StringVar(&payloadSizes, "payload-sizes", "", "Comma-separated ConfigMap sizes to benchmark GET/LIST latency with (e.g. 1KB,100KB,900KB)")
+	flag.IntVar(&payloadObjects, "payload-objects", 10, "Number of ConfigMaps seeded per payload size")
+	flag.StringVar(&seedNamespace, "seed-namespace", "", "Namespace in which benchmark objects are created (default: a temporary namespace created for the run)")
+	flag.BoolVar(&keepNamespace, "keep-namespace", false, "Keep the temporary benchmark namespace after the run for debugging")
+	flag.StringVar(&seedTemplate, "seed-template", "", "Go-templated manifest of objects (any kind, including custom resources) seeded before benchmarking and deleted afterwards")
+	flag.BoolVar(&keepSeeded, "keep-seeded", false, "Keep the objects seeded from --seed-template after the run, e.g. for a later measure phase")
+	flag.IntVar(&seedCount, "seed-count", 10, "Number of copies of the --seed-template manifest to create")
+	flag.StringVar(&seedSize, "seed-size", "1KB", "Size of the {{.Payload}} string available to --seed-template")
+	flag.StringVar(&metadataLists, "metadata-lists", "", "Comma-separated resources to compare metadata-only (PartialObjectMetadataList) with full cluster-wide lists")
+	flag.StringVar(&tableLists, "table-lists", "", "Comma-separated resources to compare server-side Table (as used by kubectl get) with full cluster-wide lists")
+	flag.StringVar(&managedFieldsLists, "managed-fields-lists", "", "Comma-separated resources to list and get with and without managedFields and through the metadata client, comparing response size and decode time")
+	flag.StringVar(&kubectlCompletion, "kubectl-completion", "", "Comma-separated resources whose kubectl shell completion of object names to emulate: discovery from a warm disk cache, the namespace list and the name list")
+	flag.StringVar(&completionNamespace, "completion-namespace", metav1.NamespaceDefault, "Namespace in which --kubectl-completion completes object names")
+	flag.StringVar(&getAllNamespace, "get-all-namespace", "", "Namespace in which to emulate the list calls of \"kubectl get all\" (empty to disable)")
+	flag.BoolVar(&watchLatency, "watch-latency", false, "Benchmark the time from creating an object until its ADDED watch event arrives")
+	flag.IntVar(&watchThroughputEvents, "watch-throughput-events", 0, "Number of updates generated to benchmark watch event throughput and delivery lag (0 to disable)")
+	flag.IntVar(&watchers, "watchers", 0, "Number of concurrent watches opened to benchmark per-watch event latency (0 to disable)")
+	flag.BoolVar(&crdConversion, "crd-conversion", false, "Benchmark listing CRDs with conversion webhooks at their storage versus converted versions")
+	flag.BoolVar(&crdRegistration, "crd-registration", false, "Benchmark the time until a newly created CRD is Established, discoverable and served")
+	flag.BoolVar(&discoveryCache, "discovery-cache", false, "Benchmark discovery with a cold versus a warm kubectl-style disk cache")
+	flag.BoolVar(&apiResourcesBenchmark, "api-resources", false, "Benchmark \"kubectl api-resources\" without a discovery cache versus with a warm disk cache")
+	flag.BoolVar(&discoveryStages, "discovery-stages", false, "Time the stages of discovery separately and rank the group versions by discovery latency")
+	flag.StringVar(&admissionNamespace, "admission-namespace", "", "Namespace in which admission webhooks apply, enables comparing Create/Update latency there with the seed namespace")
+	flag.StringVar(&admissionLabels, "admission-labels", "", "Labels put on objects created in --admission-namespace to match webhook object selectors (e.g. team=a)")
+	flag.StringVar(&tokenServiceAccount, "token-service-account", "", "ServiceAccount in the seed namespace used to benchmark TokenRequest and TokenReview (e.g. default)")
+	flag.BoolVar(&csrBenchmark, "csr-lifecycle", false, "Benchmark creating, approving and issuing CertificateSigningRequests")
+	flag.BoolVar(&aggregatedAPIs, "aggregated-apis", false, "Benchmark a request through every aggregated apiserver registered as APIService")
+	flag.BoolVar(&scaleSubresource, "scale-subresource", false, "Benchmark GET and PATCH of the /scale subresource of a seeded Deployment")
+	flag.IntVar(&gcDependents, "gc-dependents", 0, "Number of dependents of a deleted owner to benchmark garbage collection latency with (0 to disable)")
+	flag.BoolVar(&finalizerLatency, "finalizer-latency", false, "Benchmark how long objects take to disappear after deletion with and without a finalizer")
+	flag.BoolVar(&deletionPropagation, "deletion-propagation", false, "Benchmark Deployment deletion with Foreground, Background and Orphan propagation")
+	flag.IntVar(&rolloutReplicas, "rollout-replicas", 0, "Number of replicas of a seeded Deployment to benchmark rollout completion with (0 to disable)")
+	flag.IntVar(&bulkCreate, "bulk-create", 0, "Number of ConfigMaps to create as fast as allowed to benchmark write throughput (0 to disable)")
+	flag.IntVar(&bulkCreateWorkers, "bulk-create-workers", 10, "Number of workers creating ConfigMaps concurrently in the bulk create benchmark")
+	flag.IntVar(&eventFlood, "event-flood", 0, "Number of Events to seed to compare the events query of a single object with listing all events of the namespace (0 to disable)")
+	flag.StringVar(&fanOutWidths, "fan-out-widths", "", "Comma-separated numbers of concurrent GETs to benchmark resolving listed objects with (e.g. 1,10,50)")
+	flag.IntVar(&fanOutObjects, "fan-out-objects", 100, "Number of ConfigMaps seeded for the fan-out benchmark")
+	flag.StringVar(&rampLevels, "ramp", "", "Comma-separated concurrency levels to ramp through, recording latency and throughput per level (e.g. 1,2,4,8,16,32)")
+	flag.IntVar(&rampRequests, "ramp-requests", 200, "Number of requests issued at every concurrency level of --ramp")
+	flag.StringVar(&cancelAfter, "cancel-after", "", "Comma-separated times after which a large LIST is cancelled, to measure how quickly the client recovers (e.g. 10ms,50ms,200ms)")
+	flag.IntVar(&cancelObjects, "cancel-objects", 500, "Number of 10KB ConfigMaps seeded for the cancellation benchmark")
+	flag.Var(&gvrs, "gvr", "List this group/version/resource (version/resource for the core group) across all namespaces through the dynamic client, e.g. apps/v1/deployments (repeatable)")
+	flag.StringVar(&kubeletProxyPath, "kubelet-proxy", "", "Kubelet path fetched through the apiserver node proxy (e.g. healthz or stats/summary)")
+	flag.IntVar(&kubeletProxyNodes, "kubelet-proxy-nodes", 3, "Number of nodes benchmarked by --kubelet-proxy")
+	flag.StringVar(&apiProxy, "api-proxy", "", "URL of a local API proxy such as \"kubectl proxy\" (e.g. http://127.0.0.1:8001) to compare direct with proxied latency")
+	flag.StringVar(&httpProxy, "http-proxy", "", "URL of a forward HTTP proxy to compare direct with proxied latency")
+	flag.StringVar(&kubectlCompare, "kubectl-compare", "", "Comma-separated resources to list with both client-go and \"kubectl get -A\" (e.g. pods,secrets)")
+	flag.StringVar(&kubectlPath, "kubectl-path", "kubectl", "Path of the kubectl binary used by --kubectl-compare")
+	flag.StringVar(&endpointList, "endpoints", "", "Comma-separated apiserver URLs (e.g. the control-plane nodes behind the load balancer) to compare against each other")
+	flag.StringVar(&impersonateList, "impersonate", "", "Comma-separated user names (e.g. system:serviceaccount:tenant:default) to impersonate and compare against the kubeconfig's own identity")
+	flag.StringVar(&impersonateNamespace, "impersonate-namespace", "default", "Namespace the identities compared by --impersonate list resources in")
+	flag.IntVar(&tenants, "tenants", 0, "Number of independent clients running the namespaced list operations concurrently to simulate multi-tenant load (0 to disable)")
+	flag.StringVar(&tenantUsers, "tenant-users", "", "Comma-separated user names the --tenants clients impersonate in turn (default: the kubeconfig's own identity)")
+	flag.StringVar(&tenantNamespace, "tenant-namespace", "default", "Namespace the --tenants clients list resources in")
+	flag.StringVar(&checkpointPath, "checkpoint", "", "Periodically write the collected samples to this file, so the run can be resumed")
+	flag.DurationVar(&checkpointInterval, "checkpoint-interval", time.Minute, "Interval between checkpoints")
+	flag.StringVar(&resume, "resume", "", "Resume an interrupted run from this checkpoint file, skipping the iterations it already contains; operations cut short by --max-runtime are retried rather than restored")
+	flag.StringVar(&scheduleExpr, "schedule", "", "Keep running and execute the benchmark on this cron schedule (e.g. \"0 */6 * * *\")")
+	flag.StringVar(&resultsNamespace, "results-configmap-namespace", "", "Write the run summary into a ConfigMap in this namespace")
+	flag.StringVar(&scenarioPath, "scenario", "", "Read flag values from this YAML scenario file, flags given on the command line take precedence")
+	flag.StringVar(&suiteSelection, "suites", "", "Comma-separated suites of the scenario to run in parallel (default: all suites)")
+	flag.IntVar(&suiteParallelism, "suite-parallelism", 0, "Number of suites run at the same time (0 for all at once)")
+	flag.StringVar(&suite, "suite", "", "Run only this suite of the scenario, in this process")
+	flag.StringVar(&profile, "profile", "", "Preset of settings: quick, completion, standard or exhaustive; explicit flags and scenario settings take precedence")
+	flag.BoolVar(&ci, "ci", false, "Preset for pipelines: --summary-top=20 --no-color --out-dir=k8s-api-bench-results --junit-output=k8s-api-bench-results/junit.xml --strict --health-gate=refuse; explicit flags, scenario and profile settings take precedence")
+	flag.BoolVar(&strict, "strict", false, "Exit with status 2 if any iteration failed or an operation regressed against --baseline")
+	flag.StringVar(&baselinePath, "baseline", "", "Summary JSON or run directory of a previous run to compare the medians against")
+	flag.Float64Var(&regressionThreshold, "regression-threshold", 10, "Relative slowdown of the median in percent above which an operation regressed against --baseline")
+	flag.StringVar(&junitOutput, "junit-output", "", "Write a JUnit XML report with a test case per operation to this file")
+	flag.StringVar(&healthGate, "health-gate", healthGateTaint, "Before benchmarking, check /readyz, node readiness and the apiserver error rate; on a degraded cluster refuse to run (refuse), mark the results tainted (taint), or skip the checks (off)")
+	flag.StringVar(&tapOutput, "tap-output", "", "Write a TAP version 13 report with a test point per operation to this file, or - for stdout")
+	flag.IntVar(&warmup, "warmup", 0, "Run every selected core suite operation this many times before measuring, without recording")
+	flag.StringVar(&operations, "operations", "", "Comma-separated catalogue operations or tag:<tag> entries to run in the core suite (default: all, see list-ops)")
+	flag.StringVar(&controlAddr, "control-addr", "", "With --schedule, serve an HTTP control API on this address (e.g. :8080, bound to loopback unless K8S_API_BENCH_CONTROL_TOKEN is set)")
+	flag.BoolVar(&reloadCredentials, "reload-credentials", false, "Reload client certificates and tokens when the kubeconfig or its credential files change during the run (implied by --schedule)")
+	flag.StringVar(&summaryOutput, "summary-output", "", "Write the final JSON summary to this file")
+	flag.StringVar(&resultsWebhook, "results-webhook", "", "POST the final JSON summary to this URL")
+	flag.Var(webhookHeaders, "results-webhook-header", "Header added to the results webhook request as \"Name: value\" (repeatable)")
+	flag.StringVar(&preRunHook, "pre-run-hook", "", "Shell command to run, or HTTP(S) URL to POST to, before benchmarking; the run is aborted if it fails")
+	flag.StringVar(&postRunHook, "post-run-hook", "", "Shell command to run, or HTTP(S) URL to POST to, at the end of the run, also when it fails, with the outcome of the run")
+	flag.StringVar(&preOperationHook, "pre-operation-hook", "", "Shell command to run, or HTTP(S) URL to POST to, before the iterations of every operation")
+	flag.StringVar(&postOperationHook, "post-operation-hook", "", "Shell command to run, or HTTP(S) URL to POST to, after the iterations of every operation")
+	flag.StringVar(&grafanaURL, "grafana-url", "", "Grafana base URL to post run start and end annotations to, authenticated with the GRAFANA_TOKEN environment variable")
+	flag.IntVar(&networkBaseline, "network-baseline", 0, "Measure the raw TCP connect and TLS handshake time to the apiserver this many times before benchmarking (e.g. 5)")
+	flag.StringVar(&auditLogPath, "audit-log", "", "Path of the apiserver's JSON audit log, to compare the client-side duration of sampled requests with their server-side duration")
+	flag.Float64Var(&auditSample, "audit-sample", 0.1, "Fraction of requests sampled for --audit-log")
+	flag.Float64Var(&qps, "qps", float64(rest.DefaultQPS), "Client-side rate limit in requests per second")
+	flag.IntVar(&burst, "burst", rest.DefaultBurst, "Client-side rate limit burst")
+	flag.DurationVar(&requestTimeout, "request-timeout", 0, "Deadline of every request; iterations running into it are reported per operation with their elapsed time (0 for none)")
+	flag.IntVar(&maxIdleConns, "max-idle-conns", 0, "Maximum number of idle connections the transport keeps to the apiserver (0 for the client-go default of 25)")
+	flag.IntVar(&maxConnsPerHost, "max-conns-per-host", 0, "Maximum number of connections to the apiserver, queuing requests beyond it (0 for no limit)")
+	flag.DurationVar(&idleConnTimeout, "idle-conn-timeout", 0, "Time after which idle connections to the apiserver are closed (0 for the client-go default of 90s)")
 	flag.Parse()
 
-	if iterations < 1 {
-		fmt.Println("Error: iterations must be at least 1")
-		os.Exit(1)
+	var suites map[string]*suiteDefinition
+	var rawOperations []rawOperation
+	if scenarioPath != "" {
+		settings, err := loadScenario(scenarioPath)
+		if err != nil {
+			fmt.Printf("Error loading scenario: %v\n", err)
+			return 1
+		}
+		// The settings of the selected suite take precedence over those shared by all suites
+		var problems []error
+		suites, problems = scenarioSuites(flag.CommandLine, settings)
+		if value, ok := settings["raw-operations"]; ok {
+			delete(settings, "raw-operations")
+			if rawOperations, err = parseRawOperations(value); err != nil {
+				problems = append(problems, fmt.Errorf("invalid raw-operations: %v", err))
+			}
+		}
+		if definition, ok := suites[suite]; ok {
+			problems = append(problems, applyScenario(flag.CommandLine, definition.settings)...)
+			if definition.rawOperations != nil {
+				rawOperations = definition.rawOperations
+			}
+		}
+		if problems = append(problems, applyScenario(flag.CommandLine, settings)...); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Printf("Error in scenario %s: %v\n", scenarioPath, problem)
+			}
+			return 1
+		}
 	}
 
-	fmt.Printf("Using kubeconfig: %s\n", kubeconfig)
-	fmt.Printf("Running each benchmark operation for %d iterations\n", iterations)
+	if profile != "" {
+		settings, err := profileSettings(profile)
+		if err != nil {
+			fmt.Printf("Error: invalid --profile: %v\n", err)
+			return 1
+		}
+		if problems := applyScenario(flag.CommandLine, settings); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Printf("Error in profile %s: %v\n", profile, problem)
+			}
+			return 1
+		}
+	}
 
-	// Create benchmark results object
-	benchmarkResults := NewBenchmarkResults()
+	if ci {
+		if problems := applyScenario(flag.CommandLine, ciSettings); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Printf("Error in --ci settings: %v\n", problem)
+			}
+			return 1
+		}
+	}
 
-	// Build the config from the kubeconfig file
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
-	if err != nil {
-		fmt.Printf("Error building kubeconfig: %v\n", err)
-		os.Exit(1)
+	if noColor {
+		colorEnabled = false
 	}
 
-	// Create the clientset
-	clientset, err := kubernetes.NewForConfig(config)
+	if _, ok := suites[suite]; suite != "" && !ok {
+		fmt.Printf("Error: unknown suite %q, the scenario must define it under suites\n", suite)
+		return 1
+	}
+	if suiteSelection != "" && len(suites) == 0 {
+		fmt.Println("Error: --suites requires a scenario defining suites")
+		return 1
+	}
+	selectedSuites, err := parseSuiteSelection(suiteSelection, suites)
 	if err != nil {
-		fmt.Printf("Error creating Kubernetes client: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Error: invalid --suites: %v\n", err)
+		return 1
+	}
+	if suiteParallelism < 0 {
+		fmt.Println("Error: suite-parallelism must not be negative")
+		return 1
 	}
 
-	// Get namespaces (we need this for later operations)
-	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		fmt.Printf("Error listing namespaces: %v\n", err)
-		os.Exit(1)
+	if iterations < 1 {
+		fmt.Println("Error: iterations must be at least 1")
+		return 1
 	}
 
-	// Benchmark listing namespaces
-	runBenchmark("list namespaces", iterations, func() error {
-		_, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-		return err
-	}, benchmarkResults)
+	if progressInterval < 0 {
+		fmt.Println("Error: progress-interval must not be negative")
+		return 1
+	}
 
-	fmt.Println("Available namespaces:")
-	for i, ns := range namespaces.Items {
-		fmt.Printf("%d. %s\n", i+1, ns.Name)
+	if maxRuntime < 0 {
+		fmt.Println("Error: max-runtime must not be negative")
+		return 1
 	}
 
-	// Benchmark operations used for tab completion
-	fmt.Println("\n--- Tab Completion API Operations Benchmark ---")
+	if maxConsecutiveFailures < 0 {
+		fmt.Println("Error: max-consecutive-failures must not be negative")
+		return 1
+	}
 
-	// Perform namespace-specific operations for each namespace
-	for _, ns := range namespaces.Items {
-		nsName := ns.Name
-		fmt.Printf("\n--- Benchmarking namespace: %s ---\n", nsName)
+	if summaryTop < 0 {
+		fmt.Println("Error: summary-top must not be negative")
+		return 1
+	}
 
-		// List pods in the current namespace
-		runBenchmark("list pods", iterations, func() error {
-			return listPods(clientset, nsName)
-		}, benchmarkResults)
+	if !validDurationUnit(durationUnit) {
+		fmt.Printf("Error: unknown unit %q (expected auto, us, ms or s)\n", durationUnit)
+		return 1
+	}
 
-		// List deployments in the current namespace
-		runBenchmark("list deployments", iterations, func() error {
-			return listDeployments(clientset, nsName)
-		}, benchmarkResults)
+	if maxSamplesPerOp < 0 {
+		fmt.Println("Error: max-samples-per-op must not be negative")
+		return 1
+	}
 
-		// List services in the current namespace
-		runBenchmark("list services", iterations, func() error {
-			return listServices(clientset, nsName)
-		}, benchmarkResults)
+	if statsWindow < 0 {
+		fmt.Println("Error: stats-window must not be negative")
+		return 1
+	}
 
-		// List ConfigMaps in the current namespace
-		runBenchmark("list ConfigMaps", iterations, func() error {
-			return listConfigMaps(clientset, nsName)
-		}, benchmarkResults)
+	if streamingStats && (keepRawSamples || statsWindow > 0) {
+		fmt.Println("Error: --streaming-stats cannot be combined with --raw-samples or --stats-window")
+		return 1
+	}
 
-		// List Secrets in the current namespace
-		runBenchmark("list Secrets", iterations, func() error {
-			return listSecrets(clientset, nsName)
-		}, benchmarkResults)
+	if streamOutput == "-" && tapOutput == "-" {
+		fmt.Println("Error: --stream-output and --tap-output cannot both write to stdout")
+		return 1
 	}
 
-	// Non-namespace specific operations
-	fmt.Println("\n--- Non-namespace specific operations ---")
+	if iterationRate < 0 {
+		fmt.Println("Error: rate must not be negative")
+		return 1
+	}
+
+	if warmup < 0 {
+		fmt.Println("Error: warmup must not be negative")
+		return 1
+	}
+
+	if requestTimeout < 0 {
+		fmt.Println("Error: request-timeout must not be negative")
+		return 1
+	}
+
+	if maxIdleConns < 0 || maxConnsPerHost < 0 || idleConnTimeout < 0 {
+		fmt.Println("Error: max-idle-conns, max-conns-per-host and idle-conn-timeout must not be negative")
+		return 1
+	}
+
+	if bulkCreateWorkers < 1 {
+		fmt.Println("Error: bulk-create-workers must be at least 1")
+		return 1
+	}
+
+	if namespaceParallelism < 1 {
+		fmt.Println("Error: namespace-parallelism must be at least 1")
+		return 1
+	}
+
+	if maxNamespaces < 0 {
+		fmt.Println("Error: max-namespaces must not be negative")
+		return 1
+	}
+
+	if !validNamespaceSample(namespaceSample) {
+		fmt.Printf("Error: unknown namespace sample strategy %q\n", namespaceSample)
+		return 1
+	}
+
+	widths, err := parseWidths(fanOutWidths)
+	if err != nil {
+		fmt.Printf("Error: invalid --fan-out-widths: %v\n", err)
+		return 1
+	}
+	if fanOutObjects < 1 {
+		fmt.Println("Error: fan-out-objects must be at least 1")
+		return 1
+	}
+
+	if historyRuns < 1 {
+		fmt.Println("Error: history-runs must be at least 1")
+		return 1
+	}
+	if historyDir != "" {
+		history, runs, err := loadHistory(historyDir, historyRuns)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		if runs == 0 {
+			fmt.Printf("Warning: no previous runs found in %s, trends are not shown\n", historyDir)
+		} else {
+			historyMedians = history
+			fmt.Printf("Showing trends against the median of the last %d runs in %s\n", runs, historyDir)
+		}
+	}
+
+	switch healthGate {
+	case healthGateRefuse, healthGateTaint, healthGateOff:
+	default:
+		fmt.Printf("Error: unknown health gate %q (expected refuse, taint or off)\n", healthGate)
+		return 1
+	}
+
+	if regressionThreshold < 0 {
+		fmt.Println("Error: regression-threshold must not be negative")
+		return 1
+	}
+	var baseline Summary
+	if baselinePath != "" {
+		baseline, err = loadSummary(baselinePath)
+		if err != nil {
+			fmt.Printf("Error loading baseline: %v\n", err)
+			return 1
+		}
+	}
+
+	ramp, err := parseWidths(rampLevels)
+	if err != nil {
+		fmt.Printf("Error: invalid --ramp: %v\n", err)
+		return 1
+	}
+	if rampRequests < 1 {
+		fmt.Println("Error: ramp-requests must be at least 1")
+		return 1
+	}
+
+	cancelPoints, err := parseCancelPoints(cancelAfter)
+	if err != nil {
+		fmt.Printf("Error: invalid --cancel-after: %v\n", err)
+		return 1
+	}
+	if cancelObjects < 1 {
+		fmt.Println("Error: cancel-objects must be at least 1")
+		return 1
+	}
+
+	selectedColumns, err = parseColumns(columnList)
+	if err != nil {
+		fmt.Printf("Error: invalid --columns: %v\n", err)
+		return 1
+	}
+	sortBy, err = parseSortBy(sortBy)
+	if err != nil {
+		fmt.Printf("Error: invalid --sort-by: %v\n", err)
+		return 1
+	}
+
+	sweepLimits, err := parseLimits(limitSweep)
+	if err != nil {
+		fmt.Printf("Error: invalid --limit-sweep: %v\n", err)
+		return 1
+	}
+	sweepResourceNames, err := parseListableResources(limitSweepResources)
+	if err != nil {
+		fmt.Printf("Error: invalid --limit-sweep-resources: %v\n", err)
+		return 1
+	}
+
+	metadataListResources, err := parseListableResources(metadataLists)
+	if err != nil {
+		fmt.Printf("Error: invalid --metadata-lists: %v\n", err)
+		return 1
+	}
+	kubectlCompareResources, err := parseListableResources(kubectlCompare)
+	if err != nil {
+		fmt.Printf("Error: invalid --kubectl-compare: %v\n", err)
+		return 1
+	}
+
+	tableListResources, err := parseListableResources(tableLists)
+	if err != nil {
+		fmt.Printf("Error: invalid --table-lists: %v\n", err)
+		return 1
+	}
+
+	// Representations to compare with full-object lists, per resource
+	listRepresentations := make(map[string][]listRepresentation)
+	for _, resource := range metadataListResources {
+		listRepresentations[resource] = append(listRepresentations[resource], metadataOnlyRepresentation)
+	}
+	for _, resource := range tableListResources {
+		listRepresentations[resource] = append(listRepresentations[resource], tableRepresentation)
+	}
+
+	managedFieldsResources, err := parseListableResources(managedFieldsLists)
+	if err != nil {
+		fmt.Printf("Error: invalid --managed-fields-lists: %v\n", err)
+		return 1
+	}
+
+	kubectlCompletionResources, err := parseListableResources(kubectlCompletion)
+	if err != nil {
+		fmt.Printf("Error: invalid --kubectl-completion: %v\n", err)
+		return 1
+	}
+
+	payloadSizeNames, err := parsePayloadSizes(payloadSizes)
+	if err != nil {
+		fmt.Printf("Error: invalid --payload-sizes: %v\n", err)
+		return 1
+	}
+	if payloadObjects < 1 {
+		fmt.Println("Error: payload-objects must be at least 1")
+		return 1
+	}
+
+	if apiProxy != "" && httpProxy != "" {
+		fmt.Println("Error: --api-proxy and --http-proxy cannot be combined")
+		return 1
+	}
+
+	if auditSample <= 0 || auditSample > 1 {
+		fmt.Println("Error: audit-sample must be greater than 0 and at most 1")
+		return 1
+	}
+
+	if checkpointInterval <= 0 {
+		fmt.Println("Error: checkpoint-interval must be positive")
+		return 1
+	}
+
+	if seedCount < 1 {
+		fmt.Println("Error: seed-count must be at least 1")
+		return 1
+	}
+	seedSizeBytes, err := parseSize(seedSize)
+	if err != nil {
+		fmt.Printf("Error: invalid --seed-size: %v\n", err)
+		return 1
+	}
+
+	if kubeletProxyNodes < 1 {
+		fmt.Println("Error: kubelet-proxy-nodes must be at least 1")
+		return 1
+	}
+
+	admissionLabelSet, err := parseLabels(admissionLabels)
+	if err != nil {
+		fmt.Printf("Error: invalid --admission-labels: %v\n", err)
+		return 1
+	}
+
+	endpoints, err := parseEndpoints(endpointList)
+	if err != nil {
+		fmt.Printf("Error: invalid --endpoints: %v\n", err)
+		return 1
+	}
+
+	includeNamespaces, err := compileOptionalRegexp(namespaceRegex)
+	if err != nil {
+		fmt.Printf("Error: invalid --namespace-regex: %v\n", err)
+		return 1
+	}
+	excludeNamespaces, err := compileOptionalRegexp(namespaceExcludeRegex)
+	if err != nil {
+		fmt.Printf("Error: invalid --namespace-exclude-regex: %v\n", err)
+		return 1
+	}
+
+	selectedOperations, err = parseOperations(operations)
+	if err != nil {
+		fmt.Printf("Error: invalid --operations: %v\n", err)
+		return 1
+	}
+
+	if controlAddr != "" && scheduleExpr == "" {
+		fmt.Println("Error: --control-addr requires --schedule")
+		return 1
+	}
+
+	var schedule *cronSchedule
+	if scheduleExpr != "" {
+		schedule, err = parseCronSchedule(scheduleExpr)
+		if err != nil {
+			fmt.Printf("Error: invalid --schedule: %v\n", err)
+			return 1
+		}
+		if runID != "" || resume != "" || summaryOutput != "" {
+			fmt.Println("Error: --schedule cannot be combined with --run-id, --resume or --summary-output")
+			return 1
+		}
+	}
+
+	if validateOnly {
+		fmt.Println("Scenario is valid")
+		return 0
+	}
+
+	if schedule != nil {
+		return runSchedule(schedule, os.Args[1:], controlAddr)
+	}
+
+	if len(suites) > 0 && suite == "" {
+		outputs := map[string]string{
+			"summary-output": summaryOutput,
+			"junit-output":   junitOutput,
+			"tap-output":     tapOutput,
+			"stream-output":  streamOutput,
+			"checkpoint":     checkpointPath,
+		}
+		return runSuites(selectedSuites, suites, suiteParallelism, os.Args[1:], outputs)
+	}
+
+	// Create benchmark results object
+	benchmarkResults := NewBenchmarkResults()
+
+	// Restore the samples of an interrupted run, continuing under its run ID
+	var resumedStartTime time.Time
+	if resume != "" {
+		resumedRunID, startTime, err := benchmarkResults.LoadCheckpoint(resume)
+		if err != nil {
+			fmt.Printf("Error loading checkpoint: %v\n", err)
+			return 1
+		}
+		if runID == "" {
+			runID = resumedRunID
+		}
+		resumedStartTime = startTime
+		if checkpointPath == "" {
+			checkpointPath = resume
+		}
+	}
+
+	if runID == "" {
+		runID = newRunID(time.Now())
+	}
+	if !validRunID(runID) {
+		fmt.Printf("Error: --run-id must not be empty or contain path separators\n")
+		return 1
+	}
+
+	// Opened before anything else is printed, as streaming to stdout moves the output to stderr
+	if streamOutput != "" {
+		stream, err := NewStreamWriter(streamOutput, runID, labels)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		defer stream.Close()
+		benchmarkResults.Stream = stream
+	}
+
+	fmt.Printf("Using kubeconfig: %s\n", kubeconfig)
+	fmt.Printf("Run ID: %s\n", runID)
+	if resume != "" {
+		fmt.Printf("Resuming from checkpoint %s\n", resume)
+	}
+	fmt.Printf("Running each benchmark operation for %d iterations\n", iterations)
+
+	// Build the config from the kubeconfig file
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		fmt.Printf("Error building kubeconfig: %v\n", err)
+		return 1
+	}
+
+	config.QPS = float32(qps)
+	config.Burst = burst
+	config.Timeout = requestTimeout
+	tuneConnectionPool(config)
+
+	if verbosity >= requestLogLevel {
+		config.Wrap(newVerboseTransport)
+	}
+
+	if reloadCredentials {
+		reloader, err := newCredentialReloader(kubeconfig, config)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		stopReload := make(chan struct{})
+		defer close(stopReload)
+		go reloader.Watch(benchmarkResults, stopReload)
+	}
+
+	// Exec plugins and auth providers refresh expiring tokens mid-run, pausing the request
+	credentialRefreshes = trackCredentialRefreshes(config)
+
+	// The apiserver's priority and fairness rejects requests with 429 when its queues are full
+	trackThrottling(config)
+
+	// New connections behind load balancers with short idle timeouts add handshakes to requests
+	trackConnections(config)
+
+	var sampler *auditSampler
+	if auditLogPath != "" {
+		sampler = sampleAuditIDs(config, auditSample)
+	}
+
+	metadata := NewRunMetadata(runID, labels, kubeconfig, config, iterations)
+	if !resumedStartTime.IsZero() {
+		metadata.StartTime = resumedStartTime
+	}
+
+	if maxRuntime > 0 {
+		runDeadline = time.Now().Add(maxRuntime)
+	}
+
+	// GC pauses and CPU saturation of the client itself inflate the measured latencies
+	clientRuntime := startRuntimeMonitor()
+
+	if checkpointPath != "" {
+		stopCheckpoints := make(chan struct{})
+		defer close(stopCheckpoints)
+		go checkpointPeriodically(checkpointPath, checkpointInterval, metadata, benchmarkResults, stopCheckpoints)
+	}
+
+	if statsWindow > 0 {
+		stopWindows := make(chan struct{})
+		defer close(stopWindows)
+		go printWindowsPeriodically(statsWindow, benchmarkResults, stopWindows)
+	}
+
+	// Measure the network floor that API latencies can be normalized against
+	if networkBaseline > 0 {
+		floor, err := benchmarkNetworkBaseline(config, networkBaseline, benchmarkResults)
+		if err != nil {
+			fmt.Printf("Warning: unable to measure network baseline: %v\n", err)
+		}
+		metadata.NetworkFloor = floor
+	}
+
+	// Create the clientset
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating Kubernetes client: %v\n", err)
+		return 1
+	}
+
+	// Capture the cluster metadata embedded in every report
+	clusterInfo, err := captureClusterInfo(clientset)
+	if err != nil {
+		fmt.Printf("Warning: unable to capture cluster metadata: %v\n", err)
+	} else {
+		fmt.Printf("Cluster: %s, %d nodes, %d namespaces, %d pods\n",
+			clusterInfo.ServerVersion, clusterInfo.Nodes, clusterInfo.Namespaces, clusterInfo.Pods)
+		metadata.Cluster = &clusterInfo
+	}
+
+	// Results from a degraded cluster would make garbage baselines
+	if healthGate != healthGateOff {
+		if problems := checkClusterHealth(clientset); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Printf("Cluster degraded: %s\n", problem)
+			}
+			if healthGate == healthGateRefuse {
+				fmt.Println("Error: refusing to benchmark a degraded cluster")
+				return 1
+			}
+			fmt.Println("Warning: the results of this run are marked as tainted")
+			metadata.Tainted = true
+			metadata.HealthProblems = problems
+		}
+	}
+
+	hookRunID = runID
+	if err := runHook(hookPreRun, preRunHook, ""); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	// From here on the post-run hook also runs when the run fails, so it can undo the pre-run hook
+	defer func() { runPostRunHook(status) }()
+
+	// Mark the benchmark window on the cluster dashboards
+	var annotator *GrafanaAnnotator
+	if grafanaURL != "" {
+		annotator = NewGrafanaAnnotator(grafanaURL, os.Getenv("GRAFANA_TOKEN"), metadata)
+		if err := annotator.Annotate(metadata.StartTime, fmt.Sprintf("k8s-api-bench run %s started", runID)); err != nil {
+			fmt.Printf("Warning: unable to post Grafana annotation: %v\n", err)
+		}
+	}
+
+	// ServiceAccount tokens are requested for an existing ServiceAccount, never in the temporary
+	// namespace
+	tokenNamespace := seedNamespace
+	if tokenNamespace == "" {
+		tokenNamespace = metav1.NamespaceDefault
+	}
+
+	// Benchmarks writing objects run in a temporary namespace unless a seed namespace is given
+	writeBenchmarks := seedTemplate != "" || len(payloadSizeNames) > 0 || watchLatency || watchThroughputEvents > 0 ||
+		watchers > 0 || admissionNamespace != "" || scaleSubresource || gcDependents > 0 ||
+		finalizerLatency || deletionPropagation || rolloutReplicas > 0 || bulkCreate > 0 || eventFlood > 0 ||
+		len(widths) > 0 || len(cancelPoints) > 0
+	if seedNamespace == "" && writeBenchmarks {
+		seedNamespace, err = createEphemeralNamespace(clientset, runID)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Created benchmark namespace %s\n", seedNamespace)
+		if keepNamespace {
+			defer fmt.Printf("Keeping benchmark namespace %s\n", seedNamespace)
+		} else {
+			defer func(namespace string) {
+				if err := deleteEphemeralNamespace(clientset, namespace); err != nil {
+					fmt.Printf("Error deleting benchmark namespace %s: %v\n", namespace, err)
+				} else {
+					fmt.Printf("Deleted benchmark namespace %s\n", namespace)
+				}
+			}(seedNamespace)
+		}
+	}
+
+	// Populate the cluster with objects rendered from the seed template, so the benchmarks see
+	// realistic data
+	if seedTemplate != "" {
+		fmt.Printf("\n--- Seeding %d copies of %s in namespace %s ---\n", seedCount, seedTemplate, seedNamespace)
+		seeded, err := seedFromTemplate(config, seedTemplate, seedNamespace, "template", seedCount, seedSizeBytes)
+		cleanup := func() {
+			if err := deleteSeededObjects(config, seeded); err != nil {
+				fmt.Printf("Error cleaning up seeded objects: %v\n", err)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error seeding objects: %v\n", err)
+			cleanup()
+			return 1
+		}
+		fmt.Printf("Seeded %d objects\n", len(seeded))
+		if keepSeeded {
+			defer fmt.Printf("Keeping %d seeded objects, delete them with \"k8s-api-bench cleanup -n %s\"\n", len(seeded), seedNamespace)
+		} else {
+			defer cleanup()
+		}
+	}
+
+	if warmup > 0 {
+		warmUp(clientset, config, warmup)
+	}
+
+	// Get namespaces (we need this for later operations)
+	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		fmt.Printf("Error listing namespaces: %v\n", err)
+		return 1
+	}
+
+	// Benchmark listing namespaces
+	if operationSelected("list namespaces") {
+		progress.Plan("list namespaces", iterations)
+		runBenchmark("list namespaces", iterations, func(ctx context.Context) error {
+			_, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			return err
+		}, benchmarkResults)
+	}
+
+	fmt.Println("Available namespaces:")
+	for i, ns := range namespaces.Items {
+		fmt.Printf("%d. %s\n", i+1, ns.Name)
+	}
+
+	// Benchmark operations used for tab completion
+	fmt.Println("\n--- Tab Completion API Operations Benchmark ---")
+
+	// Perform namespace-specific operations for each selected namespace
+	selectedNamespaces := filterNamespaces(namespaces.Items, includeNamespaces, excludeNamespaces)
+	if maxNamespaces > 0 && len(selectedNamespaces) > maxNamespaces {
+		var sizes map[string]int
+		if namespaceSample == sampleLargest {
+			sizes, err = countPodsPerNamespace(clientset)
+			if err != nil {
+				fmt.Printf("Error counting pods per namespace: %v\n", err)
+				return 1
+			}
+		}
+		selectedNamespaces = sampleNamespaces(selectedNamespaces, maxNamespaces, namespaceSample, sizes)
+	}
+	fmt.Printf("Benchmarking %d of %d namespaces\n", len(selectedNamespaces), len(namespaces.Items))
+
+	// Plan the iterations of the standard operations for the progress reports
+	for _, op := range namespacedOperations {
+		if !operationSelected(op.name) {
+			continue
+		}
+		for _, ns := range selectedNamespaces {
+			progress.Plan(namespacedOperation(op.name, ns.Name), iterations)
+		}
+		if clusterWideLists {
+			progress.Plan(clusterWideOperation(op.name), iterations)
+		}
+	}
+	for _, name := range []string{"list API resources", "list all API resources", "list Custom Resource Definitions", "list PriorityClasses", "list StorageClasses"} {
+		if operationSelected(name) {
+			progress.Plan(name, iterations)
+		}
+	}
+	fmt.Printf("Planned %d iterations of the standard operations\n", progress.Total())
+
+	// Benchmark up to namespaceParallelism namespaces at the same time
+	semaphore := make(chan struct{}, namespaceParallelism)
+	var wg sync.WaitGroup
+	for _, ns := range selectedNamespaces {
+		nsName := ns.Name
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			benchmarkNamespace(clientset, nsName, iterations, benchmarkResults)
+		}()
+	}
+	wg.Wait()
+	benchmarkResults.AddNamespaceAggregates()
+
+	if clusterWideLists {
+		benchmarkClusterWide(clientset, iterations, benchmarkResults)
+	}
+
+	if len(sweepLimits) > 0 && benchmarkResults.withinBudget("limit sweep benchmark") {
+		benchmarkLimitSweep(clientset, sweepResourceNames, sweepLimits, iterations, benchmarkResults)
+	}
+
+	if len(ramp) > 0 && benchmarkResults.withinBudget("concurrency ramp benchmark") {
+		benchmarkRamp(config, metav1.NamespaceDefault, ramp, rampRequests, benchmarkResults)
+	}
+
+	if len(listRepresentations) > 0 && benchmarkResults.withinBudget("list representations benchmark") {
+		benchmarkListRepresentations(clientset, listRepresentations, iterations, benchmarkResults)
+	}
+
+	if len(managedFieldsResources) > 0 && benchmarkResults.withinBudget("managedFields benchmark") {
+		benchmarkManagedFields(clientset, managedFieldsResources, iterations, benchmarkResults)
+	}
+
+	if watchLatency && benchmarkResults.withinBudget("watch latency benchmark") {
+		benchmarkWatchLatency(clientset, seedNamespace, iterations, benchmarkResults)
+	}
+
+	if watchThroughputEvents > 0 && benchmarkResults.withinBudget("watch throughput benchmark") {
+		benchmarkWatchThroughput(clientset, seedNamespace, watchThroughputEvents, benchmarkResults)
+	}
+
+	if watchers > 0 && benchmarkResults.withinBudget("many watchers benchmark") {
+		benchmarkManyWatchers(config, clientset, seedNamespace, watchers, iterations, benchmarkResults)
+	}
+
+	if crdConversion && benchmarkResults.withinBudget("CRD conversion benchmark") {
+		benchmarkCRDConversion(config, iterations, benchmarkResults)
+	}
+
+	if crdRegistration && benchmarkResults.withinBudget("CRD registration benchmark") {
+		benchmarkCRDRegistration(config, iterations, benchmarkResults)
+	}
+
+	if discoveryCache && benchmarkResults.withinBudget("discovery cache benchmark") {
+		benchmarkDiscoveryCache(config, iterations, benchmarkResults)
+	}
+
+	if apiResourcesBenchmark && benchmarkResults.withinBudget("kubectl api-resources benchmark") {
+		benchmarkAPIResources(config, iterations, benchmarkResults)
+	}
+
+	if len(kubectlCompletionResources) > 0 && benchmarkResults.withinBudget("kubectl completion benchmark") {
+		benchmarkKubectlCompletion(config, clientset, kubectlCompletionResources, completionNamespace, iterations, benchmarkResults)
+	}
+
+	if getAllNamespace != "" && benchmarkResults.withinBudget("kubectl get all benchmark") {
+		benchmarkGetAll(clientset, getAllNamespace, iterations, benchmarkResults)
+	}
+
+	if discoveryStages && benchmarkResults.withinBudget("discovery stages benchmark") {
+		benchmarkDiscoveryStages(clientset, iterations, benchmarkResults)
+	}
+
+	if admissionNamespace != "" && benchmarkResults.withinBudget("admission benchmark") {
+		benchmarkAdmission(clientset, admissionNamespace, seedNamespace, admissionLabelSet, iterations, benchmarkResults)
+	}
+
+	if tokenServiceAccount != "" && benchmarkResults.withinBudget("tokens benchmark") {
+		benchmarkTokens(clientset, tokenNamespace, tokenServiceAccount, iterations, benchmarkResults)
+	}
+
+	if csrBenchmark && benchmarkResults.withinBudget("CSR lifecycle benchmark") {
+		benchmarkCSRLifecycle(clientset, iterations, benchmarkResults)
+	}
+
+	if aggregatedAPIs && benchmarkResults.withinBudget("aggregated APIs benchmark") {
+		benchmarkAggregatedAPIs(config, clientset, iterations, benchmarkResults)
+	}
+
+	if scaleSubresource && benchmarkResults.withinBudget("scale subresource benchmark") {
+		benchmarkScaleSubresource(clientset, seedNamespace, iterations, benchmarkResults)
+	}
+
+	if kubeletProxyPath != "" && benchmarkResults.withinBudget("kubelet proxy benchmark") {
+		benchmarkKubeletProxy(clientset, kubeletProxyPath, kubeletProxyNodes, iterations, benchmarkResults)
+	}
+
+	if (apiProxy != "" || httpProxy != "") && benchmarkResults.withinBudget("proxy comparison benchmark") {
+		proxiedConfig, err := newProxiedConfig(config, apiProxy, httpProxy)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		proxiedClientset, err := kubernetes.NewForConfig(proxiedConfig)
+		if err != nil {
+			fmt.Printf("Error creating proxied Kubernetes client: %v\n", err)
+			return 1
+		}
+		benchmarkProxyComparison(clientset, proxiedClientset, iterations, benchmarkResults)
+	}
+
+	if len(kubectlCompareResources) > 0 && benchmarkResults.withinBudget("kubectl comparison benchmark") {
+		benchmarkKubectlComparison(clientset, kubectlPath, kubeconfig, kubectlCompareResources, iterations, benchmarkResults)
+	}
+
+	if len(endpoints) > 0 && benchmarkResults.withinBudget("endpoints benchmark") {
+		benchmarkEndpoints(config, endpoints, iterations, benchmarkResults)
+	}
+
+	if identities := parseIdentities(impersonateList); len(identities) > 0 && benchmarkResults.withinBudget("identities benchmark") {
+		benchmarkIdentities(clientset, config, identities, impersonateNamespace, iterations, benchmarkResults)
+	}
+
+	if tenants > 0 && benchmarkResults.withinBudget("tenant load benchmark") {
+		benchmarkTenants(config, tenants, parseIdentities(tenantUsers), tenantNamespace, iterations, benchmarkResults)
+	}
+
+	if gcDependents > 0 && benchmarkResults.withinBudget("garbage collection benchmark") {
+		benchmarkGarbageCollection(clientset, seedNamespace, gcDependents, iterations, benchmarkResults)
+	}
+
+	if finalizerLatency && benchmarkResults.withinBudget("finalizers benchmark") {
+		benchmarkFinalizers(clientset, seedNamespace, iterations, benchmarkResults)
+	}
+
+	if deletionPropagation && benchmarkResults.withinBudget("deletion propagation benchmark") {
+		benchmarkDeletionPropagation(clientset, seedNamespace, iterations, benchmarkResults)
+	}
+
+	if rolloutReplicas > 0 && benchmarkResults.withinBudget("rollout benchmark") {
+		benchmarkRollout(clientset, seedNamespace, int32(rolloutReplicas), iterations, benchmarkResults)
+	}
+
+	if bulkCreate > 0 && benchmarkResults.withinBudget("bulk create benchmark") {
+		benchmarkBulkCreate(clientset, seedNamespace, bulkCreate, bulkCreateWorkers, benchmarkResults)
+	}
+
+	if eventFlood > 0 && benchmarkResults.withinBudget("event flood benchmark") {
+		benchmarkEventFlood(clientset, seedNamespace, eventFlood, iterations, benchmarkResults)
+	}
+
+	if len(widths) > 0 && benchmarkResults.withinBudget("fan-out benchmark") {
+		benchmarkFanOut(clientset, seedNamespace, fanOutObjects, widths, iterations, benchmarkResults)
+	}
+
+	if len(cancelPoints) > 0 && benchmarkResults.withinBudget("cancellation benchmark") {
+		benchmarkCancellation(clientset, seedNamespace, cancelObjects, cancelPoints, iterations, benchmarkResults)
+	}
+
+	if len(payloadSizeNames) > 0 && benchmarkResults.withinBudget("payload sizes benchmark") {
+		benchmarkPayloadSizes(clientset, seedNamespace, payloadSizeNames, payloadObjects, iterations, benchmarkResults)
+	}
+
+	if len(gvrs) > 0 && benchmarkResults.withinBudget("dynamic resource lists benchmark") {
+		benchmarkGVRs(config, gvrs, iterations, benchmarkResults)
+	}
+
+	if len(rawOperations) > 0 && benchmarkResults.withinBudget("raw operations benchmark") {
+		benchmarkRawOperations(clientset, rawOperations, iterations, benchmarkResults)
+	}
+
+	// Non-namespace specific operations
+	fmt.Println("\n--- Non-namespace specific operations ---")
 
 	// List API resources
-	runBenchmark("list API resources", iterations, func() error {
-		return listAPIResources(clientset)
-	}, benchmarkResults)
+	if operationSelected("list API resources") {
+		runBenchmark("list API resources", iterations, func(context.Context) error {
+			return listAPIResources(clientset)
+		}, benchmarkResults)
+	}
 
 	// List all API resources
-	runBenchmark("list all API resources", iterations, func() error {
-		return listAllAPIResources(clientset)
-	}, benchmarkResults)
+	if operationSelected("list all API resources") {
+		runBenchmark("list all API resources", iterations, func(context.Context) error {
+			return listAllAPIResources(clientset)
+		}, benchmarkResults)
+	}
 
 	// List Custom Resource Definitions
-	runBenchmark("list Custom Resource Definitions", iterations, func() error {
-		return listCRDs(config)
-	}, benchmarkResults)
+	if operationSelected("list Custom Resource Definitions") {
+		runBenchmark("list Custom Resource Definitions", iterations, func(ctx context.Context) error {
+			return listCRDs(ctx, config, benchmarkResults)
+		}, benchmarkResults)
+	}
+
+	// List PriorityClasses
+	if operationSelected("list PriorityClasses") {
+		runBenchmark("list PriorityClasses", iterations, func(ctx context.Context) error {
+			return listPriorityClasses(ctx, clientset, benchmarkResults)
+		}, benchmarkResults)
+	}
+
+	// List StorageClasses
+	if operationSelected("list StorageClasses") {
+		runBenchmark("list StorageClasses", iterations, func(ctx context.Context) error {
+			return listStorageClasses(ctx, clientset, benchmarkResults)
+		}, benchmarkResults)
+	}
 
 	fmt.Println("\nBenchmarking complete!")
 
+	if checkpointPath != "" {
+		if err := benchmarkResults.WriteCheckpoint(checkpointPath, metadata); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
+	if annotator != nil {
+		if err := annotator.Annotate(time.Now(), fmt.Sprintf("k8s-api-bench run %s finished", runID)); err != nil {
+			fmt.Printf("Warning: unable to post Grafana annotation: %v\n", err)
+		}
+	}
+
+	credentialRefreshes.SetMetrics(benchmarkResults)
+	throttling.SetMetrics(benchmarkResults)
+	connections.SetMetrics(benchmarkResults)
+	benchmarkResults.Runtime = clientRuntime.Stop()
+
+	if sampler != nil {
+		timings, err := sampler.CrossReference(auditLogPath)
+		if err != nil {
+			fmt.Printf("Warning: unable to cross-reference the audit log: %v\n", err)
+		} else if len(timings) == 0 {
+			fmt.Printf("Warning: none of the %d sampled requests were found in the audit log\n", sampler.Samples())
+		}
+		benchmarkResults.ServerTimings = timings
+	}
+
 	// Print the benchmark statistics
 	benchmarkResults.PrintStats()
+	benchmarkResults.PrintAPIGroupSummaries()
+	benchmarkResults.PrintRamp()
+	benchmarkResults.PrintSaturation()
+	benchmarkResults.PrintPageSizeRecommendations()
+	benchmarkResults.PrintManagedFieldsImpact()
+	benchmarkResults.PrintServerTimings()
+	if summaryTop == 0 {
+		benchmarkResults.PrintSlowestNamespaces()
+		benchmarkResults.PrintWorkerStats()
+	}
+	benchmarkResults.PrintMetrics()
+	benchmarkResults.PrintClientRuntime()
+	benchmarkResults.PrintIncomplete()
+	benchmarkResults.PrintSkipped()
+	benchmarkResults.PrintTimeouts()
+	benchmarkResults.PrintErrors()
+
+	metadata.EndTime = time.Now()
+
+	var regressions map[string]float64
+	if baselinePath != "" {
+		current := NewSummary(metadata, benchmarkResults)
+		printDiff(baseline, current, regressionThreshold)
+		regressions = findRegressions(baseline, current, regressionThreshold)
+	}
+
+	if junitOutput != "" {
+		if err := writeJUnit(junitOutput, NewSummary(metadata, benchmarkResults), regressions); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			fmt.Printf("\nJUnit report written to %s\n", junitOutput)
+		}
+	}
+
+	if tapOutput != "" {
+		if err := writeTAPOutput(tapOutput, NewSummary(metadata, benchmarkResults), regressions); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+
+	if summaryOutput != "" {
+		if err := writeJSONFile(summaryOutput, NewSummary(metadata, benchmarkResults)); err != nil {
+			fmt.Printf("Error writing summary: %v\n", err)
+		}
+	}
+
+	if resultsWebhook != "" {
+		if err := postResults(resultsWebhook, http.Header(webhookHeaders), NewSummary(metadata, benchmarkResults)); err != nil {
+			fmt.Printf("Error sending results to webhook: %v\n", err)
+		} else {
+			fmt.Printf("\nResults posted to %s\n", resultsWebhook)
+		}
+	}
+
+	if resultsNamespace != "" {
+		name, err := persistSummary(clientset, resultsNamespace, NewSummary(metadata, benchmarkResults))
+		if err != nil {
+			fmt.Printf("Error persisting results: %v\n", err)
+		} else {
+			fmt.Printf("\nResults written to ConfigMap %s/%s\n", resultsNamespace, name)
+		}
+	}
+
+	if outDir != "" {
+		runDir, err := WriteArtifacts(outDir, metadata, benchmarkResults)
+		if err != nil {
+			fmt.Printf("Error writing artifacts: %v\n", err)
+			return 1
+		}
+		fmt.Printf("\nArtifacts written to %s\n", runDir)
+	}
+
+	if strict && (len(benchmarkResults.Errors) > 0 || len(regressions) > 0) {
+		fmt.Printf("\nFailing the run: %d error classes, %d regressions\n", len(benchmarkResults.Errors), len(regressions))
+		return exitBenchmarkFailed
+	}
+	return 0
 }
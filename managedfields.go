@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// metadataOnlyObjectAccept requests a single object as PartialObjectMetadata, as the metadata
+// client does
+const metadataOnlyObjectAccept = "application/json;as=PartialObjectMetadata;g=meta.k8s.io;v=v1"
+
+// ManagedFieldsImpact compares the size and decode time of a list or get response with and
+// without managedFields, and with the metadata client
+type ManagedFieldsImpact struct {
+	Operation string `json:"operation"`
+	Bytes     int64  `json:"bytes"`
+	// StrippedBytes is the size of the response re-serialized without managedFields
+	StrippedBytes      int64   `json:"stripped_bytes"`
+	MetadataOnlyBytes  int64   `json:"metadata_only_bytes"`
+	DecodeMs           float64 `json:"decode_ms"`
+	StrippedDecodeMs   float64 `json:"stripped_decode_ms"`
+	StripMs            float64 `json:"strip_ms"`
+	MetadataDecodeMs   float64 `json:"metadata_only_decode_ms"`
+	ManagedFieldsShare float64 `json:"managed_fields_share"`
+}
+
+// stripManagedFieldsJSON removes metadata.managedFields from a JSON object or from every item of
+// a JSON list, returning the re-serialized body
+func stripManagedFieldsJSON(body []byte) ([]byte, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+	strip := func(object map[string]interface{}) {
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "managedFields")
+		}
+	}
+	strip(object)
+	if items, ok := object["items"].([]interface{}); ok {
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok {
+				strip(item)
+			}
+		}
+	}
+	return json.Marshal(object)
+}
+
+// stripManagedFields clears the managedFields of a decoded object or of every item of a decoded
+// list, as informer transforms do to save memory
+func stripManagedFields(obj runtime.Object) error {
+	if !meta.IsListType(obj) {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		accessor.SetManagedFields(nil)
+		return nil
+	}
+	return meta.EachListItem(obj, func(item runtime.Object) error {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		accessor.SetManagedFields(nil)
+		return nil
+	})
+}
+
+// measureManagedFields fetches the response of req and records under name the time to decode
+// it into a new object from newObject as is, to strip its managedFields after decoding, and to
+// decode it had the server omitted managedFields. The size of the response with and without
+// managedFields is recorded under name and "<name> (without managedFields)".
+func measureManagedFields(ctx context.Context, req *rest.Request, newObject func() runtime.Object, name string, results *BenchmarkResults) error {
+	decoder := scheme.Codecs.UniversalDeserializer()
+	startTime := time.Now()
+	body, err := req.DoRaw(ctx)
+	if err != nil {
+		return err
+	}
+	results.Add(name+" (network)", time.Since(startTime))
+	stripped, err := stripManagedFieldsJSON(body)
+	if err != nil {
+		return fmt.Errorf("error stripping managedFields: %v", err)
+	}
+
+	obj := newObject()
+	startTime = time.Now()
+	if err := runtime.DecodeInto(decoder, body, obj); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	results.Add(name+" (decode)", time.Since(startTime))
+
+	startTime = time.Now()
+	if err := stripManagedFields(obj); err != nil {
+		return fmt.Errorf("error stripping managedFields: %v", err)
+	}
+	results.Add(name+" (strip managedFields)", time.Since(startTime))
+
+	startTime = time.Now()
+	if err := runtime.DecodeInto(decoder, stripped, newObject()); err != nil {
+		return fmt.Errorf("error decoding response without managedFields: %v", err)
+	}
+	results.Add(name+" (decode without managedFields)", time.Since(startTime))
+
+	results.AddSize(name, len(body))
+	results.AddSize(name+" (without managedFields)", len(stripped))
+	return nil
+}
+
+// managedFieldsImpact summarizes the measurements of an operation and of its metadata-only
+// variant, false if there are none
+func managedFieldsImpact(name, metadataName string, stats map[string]map[string]time.Duration, br *BenchmarkResults) (ManagedFieldsImpact, bool) {
+	bytes, ok := br.AvgSize(name)
+	if !ok {
+		return ManagedFieldsImpact{}, false
+	}
+	strippedBytes, _ := br.AvgSize(name + " (without managedFields)")
+	metadataBytes, _ := br.AvgSize(metadataName)
+	impact := ManagedFieldsImpact{
+		Operation:         name,
+		Bytes:             bytes,
+		StrippedBytes:     strippedBytes,
+		MetadataOnlyBytes: metadataBytes,
+		DecodeMs:          durationMs(stats[name+" (decode)"]["median"]),
+		StrippedDecodeMs:  durationMs(stats[name+" (decode without managedFields)"]["median"]),
+		StripMs:           durationMs(stats[name+" (strip managedFields)"]["median"]),
+		MetadataDecodeMs:  durationMs(stats[metadataName+" (decode)"]["median"]),
+	}
+	if bytes > 0 {
+		impact.ManagedFieldsShare = float64(bytes-strippedBytes) / float64(bytes)
+	}
+	return impact, true
+}
+
+// benchmarkManagedFields lists every resource cluster-wide and gets its first object, measuring
+// how much managedFields add to the response size and decode time compared with stripping them
+// client-side and with the metadata client. Operations are recorded as "list <resource>
+// (managedFields)" and "get <resource> (managedFields)", plus their metadata-only variants.
+func benchmarkManagedFields(clientset *kubernetes.Clientset, resources []string, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- managedFields impact ---")
+
+	var measured [][2]string
+	for _, resource := range resources {
+		lr := listableResources[resource]
+		listName := fmt.Sprintf("list %s (managedFields)", resource)
+		listMetadataName := fmt.Sprintf("list %s (managedFields, metadata-only)", resource)
+		runBenchmark(listName, iterations, func(ctx context.Context) error {
+			req := lr.client(clientset).Get().
+				Resource(resource).
+				VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec)
+			return measureManagedFields(ctx, req, lr.newList, listName, results)
+		}, results)
+		runBenchmark(listMetadataName, iterations, func(ctx context.Context) error {
+			return listAs(ctx, clientset, resource, metadataOnlyRepresentation, listMetadataName, results)
+		}, results)
+		measured = append(measured, [2]string{listName, listMetadataName})
+
+		sample := firstListItem(clientset, resource)
+		if sample == nil {
+			fmt.Printf("No %s to get, skipping get %s\n", resource, resource)
+			continue
+		}
+		accessor, err := meta.Accessor(sample)
+		if err != nil {
+			continue
+		}
+		newObject := func() runtime.Object {
+			return reflect.New(reflect.TypeOf(sample).Elem()).Interface().(runtime.Object)
+		}
+		get := func() *rest.Request {
+			return lr.client(clientset).Get().
+				Namespace(accessor.GetNamespace()).
+				Resource(resource).
+				Name(accessor.GetName())
+		}
+		getName := fmt.Sprintf("get %s (managedFields)", resource)
+		getMetadataName := fmt.Sprintf("get %s (managedFields, metadata-only)", resource)
+		runBenchmark(getName, iterations, func(ctx context.Context) error {
+			return measureManagedFields(ctx, get(), newObject, getName, results)
+		}, results)
+		runBenchmark(getMetadataName, iterations, func(ctx context.Context) error {
+			return fetchAndDecode(ctx, get().SetHeader("Accept", metadataOnlyObjectAccept),
+				metadataOnlyRepresentation.decoder, &metav1.PartialObjectMetadata{}, getMetadataName, results)
+		}, results)
+		measured = append(measured, [2]string{getName, getMetadataName})
+	}
+
+	stats := results.CalculateStats()
+	for _, names := range measured {
+		if impact, ok := managedFieldsImpact(names[0], names[1], stats, results); ok {
+			results.ManagedFields = replaceRestored(results.ManagedFields, impact, func(i ManagedFieldsImpact) string { return i.Operation })
+		}
+	}
+}
+
+// firstListItem returns the first object of the resource across all namespaces, nil if there
+// is none
+func firstListItem(clientset *kubernetes.Clientset, resource string) runtime.Object {
+	lr := listableResources[resource]
+	list := lr.newList()
+	err := lr.client(clientset).Get().
+		Resource(resource).
+		VersionedParams(&metav1.ListOptions{Limit: 1}, scheme.ParameterCodec).
+		Do(context.TODO()).
+		Into(list)
+	if err != nil {
+		return nil
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil || len(items) == 0 {
+		return nil
+	}
+	return items[0]
+}
+
+// PrintManagedFieldsImpact prints how much managedFields add to the size and decode time of
+// the responses, and what the metadata client saves, if it was measured
+func (br *BenchmarkResults) PrintManagedFieldsImpact() {
+	if len(br.ManagedFields) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- managedFields Impact ---")
+	table := NewTable("Operation", "Size", "Without managedFields", "managedFields Share", "Metadata-only",
+		"Decode", "Decode Without", "Strip", "Metadata-only Decode")
+	for _, impact := range br.ManagedFields {
+		table.AddRow(
+			impact.Operation,
+			formatBytes(impact.Bytes),
+			formatBytes(impact.StrippedBytes),
+			fmt.Sprintf("%.0f%%", impact.ManagedFieldsShare*100),
+			formatBytes(impact.MetadataOnlyBytes),
+			formatMs(impact.DecodeMs),
+			formatMs(impact.StrippedDecodeMs),
+			formatMs(impact.StripMs),
+			formatMs(impact.MetadataDecodeMs),
+		)
+	}
+	table.Render(os.Stdout)
+}
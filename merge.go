@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// loadRun reads the samples and metadata of a run. The path may either be a run directory
+// written by --out-dir or a histograms.json, digests.json or samples.json file inside such a
+// directory. A directory's histograms or digests are read in preference to its raw samples.
+func loadRun(path string) (*BenchmarkResults, RunMetadata, error) {
+	var metadata RunMetadata
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, metadata, err
+	}
+	dir := filepath.Dir(path)
+	samplesPath := path
+	if info.IsDir() {
+		dir = path
+		for _, name := range []string{"histograms.json", "digests.json", "samples.json"} {
+			samplesPath = filepath.Join(path, name)
+			if _, err := os.Stat(samplesPath); err == nil {
+				break
+			}
+		}
+	}
+
+	results := NewBenchmarkResults()
+	switch filepath.Base(samplesPath) {
+	case "histograms.json":
+		if err := readJSONFile(samplesPath, &results.Histograms); err != nil {
+			return nil, metadata, err
+		}
+	case "digests.json":
+		if err := readJSONFile(samplesPath, &results.Digests); err != nil {
+			return nil, metadata, err
+		}
+	default:
+		var samples map[string][]float64
+		if err := readJSONFile(samplesPath, &samples); err != nil {
+			return nil, metadata, err
+		}
+		for op, values := range samples {
+			for _, ms := range values {
+				results.Add(op, time.Duration(ms*float64(time.Millisecond)))
+			}
+		}
+	}
+
+	// Metadata is optional, e.g. for sample files produced by other tools
+	if err := readJSONFile(filepath.Join(dir, "metadata.json"), &metadata); err != nil && !os.IsNotExist(err) {
+		return nil, metadata, err
+	}
+
+	// Errors are only part of the summary, which is optional as well
+	var summary Summary
+	if err := readJSONFile(filepath.Join(dir, "summary.json"), &summary); err != nil && !os.IsNotExist(err) {
+		return nil, metadata, err
+	}
+
+	for _, errorSummary := range summary.Errors {
+		errorSummary := errorSummary
+		results.Errors[errorKey(errorSummary.Operation, errorSummary.Class)] = &errorSummary
+	}
+	return results, metadata, nil
+}
+
+// readJSONFile decodes the JSON content of path into v
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding %s: %v", path, err)
+	}
+	return nil
+}
+
+// Merge adds all samples and errors of other to the results
+func (br *BenchmarkResults) Merge(other *BenchmarkResults) {
+	for op, histogram := range other.Histograms {
+		if _, ok := br.Histograms[op]; !ok {
+			br.Histograms[op] = NewHistogram()
+		}
+		br.Histograms[op].Merge(histogram)
+	}
+	for op, digest := range other.Digests {
+		if _, ok := br.Digests[op]; !ok {
+			br.Digests[op] = NewTDigest()
+		}
+		br.Digests[op].Merge(digest)
+	}
+	for op, durations := range other.Results {
+		br.Results[op] = append(br.Results[op], durations...)
+	}
+
+	for key, otherSummary := range other.Errors {
+		summary, ok := br.Errors[key]
+		if !ok {
+			copied := *otherSummary
+			br.Errors[key] = &copied
+			continue
+		}
+		summary.Count += otherSummary.Count
+		if otherSummary.FirstOccurrence.Before(summary.FirstOccurrence) {
+			summary.FirstOccurrence = otherSummary.FirstOccurrence
+			summary.Message = otherSummary.Message
+		}
+		if otherSummary.LastOccurrence.After(summary.LastOccurrence) {
+			summary.LastOccurrence = otherSummary.LastOccurrence
+		}
+	}
+}
+
+// mergeMetadata combines the metadata of several runs, spanning the time range of all of them and
+// keeping only the labels shared by every run
+func mergeMetadata(all []RunMetadata) RunMetadata {
+	merged := RunMetadata{}
+	for i, metadata := range all {
+		if i == 0 {
+			merged = metadata
+			merged.Labels = maps.Clone(metadata.Labels)
+			continue
+		}
+		merged.Iterations += metadata.Iterations
+		if metadata.RunID != merged.RunID {
+			merged.RunID = "merged"
+		}
+		for key, value := range merged.Labels {
+			if metadata.Labels[key] != value {
+				delete(merged.Labels, key)
+			}
+		}
+		if metadata.Server != merged.Server {
+			merged.Server = "multiple"
+		}
+		if !metadata.StartTime.IsZero() && (merged.StartTime.IsZero() || metadata.StartTime.Before(merged.StartTime)) {
+			merged.StartTime = metadata.StartTime
+		}
+		if metadata.EndTime.After(merged.EndTime) {
+			merged.EndTime = metadata.EndTime
+		}
+	}
+	return merged
+}
+
+// runMerge implements the merge subcommand, which aggregates the samples of several runs (or
+// shards of a distributed run) into a single statistics report
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	output := fs.String("o", "", "Write the merged summary JSON to this file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s merge [-o summary.json] <run-dir|histograms.json|digests.json|samples.json>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 1
+	}
+
+	merged := NewBenchmarkResults()
+	var allMetadata []RunMetadata
+	for _, path := range fs.Args() {
+		results, metadata, err := loadRun(path)
+		if err != nil {
+			fmt.Printf("Error loading %s: %v\n", path, err)
+			return 1
+		}
+		merged.Merge(results)
+		allMetadata = append(allMetadata, metadata)
+	}
+
+	fmt.Printf("Merged %d result files\n", fs.NArg())
+	merged.PrintStats()
+	merged.PrintErrors()
+
+	if *output != "" {
+		if err := writeJSONFile(*output, NewSummary(mergeMetadata(allMetadata), merged)); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("\nMerged summary written to %s\n", *output)
+	}
+	return 0
+}
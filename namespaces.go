@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// filterNamespaces returns the namespaces matching include and not matching exclude. A nil
+// regular expression matches every namespace for include and none for exclude.
+func filterNamespaces(namespaces []corev1.Namespace, include, exclude *regexp.Regexp) []corev1.Namespace {
+	var selected []corev1.Namespace
+	for _, ns := range namespaces {
+		if include != nil && !include.MatchString(ns.Name) {
+			continue
+		}
+		if exclude != nil && exclude.MatchString(ns.Name) {
+			continue
+		}
+		selected = append(selected, ns)
+	}
+	return selected
+}
+
+// compileOptionalRegexp compiles the expression, returning nil for an empty expression
+func compileOptionalRegexp(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// Namespace sampling strategies for --namespace-sample
+const (
+	sampleFirst   = "first"
+	sampleRandom  = "random"
+	sampleLargest = "largest"
+)
+
+// validNamespaceSample reports whether strategy is a known namespace sampling strategy
+func validNamespaceSample(strategy string) bool {
+	switch strategy {
+	case sampleFirst, sampleRandom, sampleLargest:
+		return true
+	}
+	return false
+}
+
+// countPodsPerNamespace returns the number of pods in every namespace using a single
+// cluster-wide list
+func countPodsPerNamespace(clientset *kubernetes.Clientset) (map[string]int, error) {
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, pod := range pods.Items {
+		counts[pod.Namespace]++
+	}
+	return counts, nil
+}
+
+// sampleNamespaces picks at most max namespaces using the given strategy. Sizes are only used by
+// the "largest" strategy. A max of 0 selects all namespaces.
+func sampleNamespaces(namespaces []corev1.Namespace, max int, strategy string, sizes map[string]int) []corev1.Namespace {
+	if max <= 0 || len(namespaces) <= max {
+		return namespaces
+	}
+
+	sampled := append([]corev1.Namespace(nil), namespaces...)
+	switch strategy {
+	case sampleRandom:
+		rand.Shuffle(len(sampled), func(i, j int) {
+			sampled[i], sampled[j] = sampled[j], sampled[i]
+		})
+	case sampleLargest:
+		sort.SliceStable(sampled, func(i, j int) bool {
+			return sizes[sampled[i].Name] > sizes[sampled[j].Name]
+		})
+	}
+	sampled = sampled[:max]
+
+	// Benchmark the sample in name order for readable output
+	sort.Slice(sampled, func(i, j int) bool {
+		return sampled[i].Name < sampled[j].Name
+	})
+	return sampled
+}
+
+// slowestNamespacesShown is the number of namespaces listed per operation in the ranking
+const slowestNamespacesShown = 5
+
+// NamespaceLatency is the median latency of an operation in a single namespace
+type NamespaceLatency struct {
+	Namespace string  `json:"namespace"`
+	MedianMs  float64 `json:"median_ms"`
+}
+
+// combinedOperation returns the name of the aggregate of an operation over all namespaces
+func combinedOperation(operation string) string {
+	return operation + " (all namespaces combined)"
+}
+
+// TrackNamespace remembers that the operation was benchmarked in the namespace, so that it can
+// be aggregated and ranked across namespaces
+func (br *BenchmarkResults) TrackNamespace(operation, namespace string) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	if br.Namespaces[operation] == nil {
+		br.Namespaces[operation] = make(map[string]bool)
+	}
+	br.Namespaces[operation][namespace] = true
+}
+
+// AddNamespaceAggregates adds an operation per namespaced operation combining the samples of all
+// namespaces it was benchmarked in, replacing aggregates restored from a checkpoint
+func (br *BenchmarkResults) AddNamespaceAggregates() {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	for operation, namespaces := range br.Namespaces {
+		combined := NewHistogram()
+		delete(br.Results, combinedOperation(operation))
+		for namespace := range namespaces {
+			op := namespacedOperation(operation, namespace)
+			if histogram, ok := br.Histograms[op]; ok {
+				combined.Merge(histogram)
+			}
+			if durations, ok := br.Results[op]; ok {
+				br.Results[combinedOperation(operation)] = append(br.Results[combinedOperation(operation)], durations...)
+			}
+		}
+		if streamingStats {
+			combined := NewTDigest()
+			for namespace := range namespaces {
+				if digest, ok := br.Digests[namespacedOperation(operation, namespace)]; ok {
+					combined.Merge(digest)
+				}
+			}
+			br.Digests[combinedOperation(operation)] = combined
+			continue
+		}
+		br.Histograms[combinedOperation(operation)] = combined
+	}
+}
+
+// SlowestNamespaces ranks, per namespaced operation, the namespaces by median latency
+func (br *BenchmarkResults) SlowestNamespaces() map[string][]NamespaceLatency {
+	stats := br.CalculateStats()
+
+	rankings := make(map[string][]NamespaceLatency)
+	for operation, namespaces := range br.Namespaces {
+		var ranking []NamespaceLatency
+		for namespace := range namespaces {
+			stat, ok := stats[namespacedOperation(operation, namespace)]
+			if !ok {
+				continue
+			}
+			ranking = append(ranking, NamespaceLatency{Namespace: namespace, MedianMs: durationMs(stat["median"])})
+		}
+		sort.Slice(ranking, func(i, j int) bool {
+			if ranking[i].MedianMs != ranking[j].MedianMs {
+				return ranking[i].MedianMs > ranking[j].MedianMs
+			}
+			return ranking[i].Namespace < ranking[j].Namespace
+		})
+		rankings[operation] = ranking
+	}
+	return rankings
+}
+
+// PrintSlowestNamespaces prints the slowest namespaces of every namespaced operation
+func (br *BenchmarkResults) PrintSlowestNamespaces() {
+	rankings := br.SlowestNamespaces()
+	if len(rankings) == 0 {
+		return
+	}
+
+	operations := make([]string, 0, len(rankings))
+	for operation := range rankings {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	fmt.Println("\n--- Slowest Namespaces ---")
+	table := NewTable("Operation", "Rank", "Namespace", "Median")
+	for _, operation := range operations {
+		for i, entry := range rankings[operation] {
+			if i == slowestNamespacesShown {
+				break
+			}
+			table.AddRow(operation, fmt.Sprintf("%d", i+1), entry.Namespace, formatMs(entry.MedianMs))
+		}
+	}
+	table.Render(os.Stdout)
+}
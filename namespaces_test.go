@@ -0,0 +1,64 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSampleNamespaces(t *testing.T) {
+	namespaceList := func(names ...string) []corev1.Namespace {
+		namespaces := make([]corev1.Namespace, len(names))
+		for i, name := range names {
+			namespaces[i] = corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		}
+		return namespaces
+	}
+	all := namespaceList("default", "kube-system", "monitoring", "apps", "batch")
+	sizes := map[string]int{"default": 3, "kube-system": 20, "monitoring": 12, "apps": 40, "batch": 1}
+
+	tests := []struct {
+		name     string
+		max      int
+		strategy string
+		want     []string
+	}{
+		{name: "no cap", max: 0, strategy: sampleFirst, want: []string{"default", "kube-system", "monitoring", "apps", "batch"}},
+		{name: "cap above the count", max: 10, strategy: sampleLargest, want: []string{"default", "kube-system", "monitoring", "apps", "batch"}},
+		{name: "first", max: 2, strategy: sampleFirst, want: []string{"default", "kube-system"}},
+		{name: "largest in name order", max: 3, strategy: sampleLargest, want: []string{"apps", "kube-system", "monitoring"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, namespace := range sampleNamespaces(all, tt.max, tt.strategy, sizes) {
+				got = append(got, namespace.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sampleNamespaces(%d, %s) = %v, want %v", tt.max, tt.strategy, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("random", func(t *testing.T) {
+		sampled := sampleNamespaces(all, 3, sampleRandom, nil)
+		if len(sampled) != 3 {
+			t.Fatalf("sampled %d namespaces, want 3", len(sampled))
+		}
+		seen := make(map[string]bool)
+		for i, namespace := range sampled {
+			if !slices.ContainsFunc(all, func(n corev1.Namespace) bool { return n.Name == namespace.Name }) {
+				t.Errorf("sampled unknown namespace %s", namespace.Name)
+			}
+			if seen[namespace.Name] {
+				t.Errorf("sampled namespace %s twice", namespace.Name)
+			}
+			seen[namespace.Name] = true
+			if i > 0 && sampled[i-1].Name > namespace.Name {
+				t.Errorf("sample not in name order: %s before %s", sampled[i-1].Name, namespace.Name)
+			}
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// NetworkFloor holds the median raw TCP connect and TLS handshake times to the apiserver in
+// milliseconds, the lower bound for any API request latency
+type NetworkFloor struct {
+	TCPConnectMs   float64 `json:"tcp_connect_ms"`
+	TLSHandshakeMs float64 `json:"tls_handshake_ms,omitempty"`
+}
+
+// apiserverAddress returns the host:port of the apiserver and whether it is served over TLS
+func apiserverAddress(config *rest.Config) (string, bool, error) {
+	host := config.Host
+	if host == "" {
+		return "", false, fmt.Errorf("no apiserver host configured")
+	}
+	u, err := url.Parse(host)
+	if err != nil || u.Host == "" {
+		// Hosts without a scheme default to HTTPS, as in client-go
+		u, err = url.Parse("https://" + host)
+		if err != nil {
+			return "", false, fmt.Errorf("error parsing apiserver host %q: %v", host, err)
+		}
+	}
+	useTLS := u.Scheme != "http"
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if !useTLS {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// benchmarkNetworkBaseline measures raw TCP connect and TLS handshake times to the apiserver,
+// bypassing any proxy, and returns their medians as the network floor of the run
+func benchmarkNetworkBaseline(config *rest.Config, iterations int, results *BenchmarkResults) (*NetworkFloor, error) {
+	fmt.Printf("\n--- Network baseline ---\n")
+
+	address, useTLS, err := apiserverAddress(config)
+	if err != nil {
+		return nil, err
+	}
+	var tlsConfig *tls.Config
+	if useTLS {
+		tlsConfig, err = rest.TLSConfigFor(config)
+		if err != nil {
+			return nil, fmt.Errorf("error building TLS config: %v", err)
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" && !tlsConfig.InsecureSkipVerify {
+			host, _, _ := net.SplitHostPort(address)
+			tlsConfig.ServerName = host
+		}
+	}
+
+	connectName := "network baseline (tcp connect)"
+	handshakeName := "network baseline (tls handshake)"
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	for i := 1; i <= iterations; i++ {
+		startTime := time.Now()
+		conn, err := dialer.DialContext(context.TODO(), "tcp", address)
+		recordIteration(connectName, i, iterations, startTime, time.Since(startTime), err, results)
+		if err != nil {
+			continue
+		}
+		if useTLS {
+			tlsConn := tls.Client(conn, tlsConfig.Clone())
+			startTime = time.Now()
+			err = tlsConn.HandshakeContext(context.TODO())
+			recordIteration(handshakeName, i, iterations, startTime, time.Since(startTime), err, results)
+		}
+		conn.Close()
+	}
+
+	stats := results.CalculateStats()
+	connectStats, ok := stats[connectName]
+	if !ok {
+		return nil, fmt.Errorf("no successful TCP connection to %s", address)
+	}
+	floor := &NetworkFloor{TCPConnectMs: durationMs(connectStats["median"])}
+	if handshakeStats, ok := stats[handshakeName]; ok {
+		floor.TLSHandshakeMs = durationMs(handshakeStats["median"])
+	}
+	fmt.Printf("Network floor to %s: TCP connect %.3f ms, TLS handshake %.3f ms\n", address, floor.TCPConnectMs, floor.TLSHandshakeMs)
+	return floor, nil
+}
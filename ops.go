@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// catalogOperation is a benchmark operation of the core suite that can be run on its own, e.g.
+// from the interactive mode
+type catalogOperation struct {
+	name string
+
+	// namespaced operations run in a single namespace or, given metav1.NamespaceAll, cluster-wide
+	namespaced bool
+
+	// groupVersion and resource of the requests, for discovery the requested paths
+	groupVersion string
+	resource     string
+
+	// verbs are the RBAC verbs the operation requires on the resource
+	verbs []string
+
+	// tags group operations for selection, e.g. with --operations=tag:discovery
+	tags []string
+
+	run func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error
+}
+
+// operationCatalog returns all operations that can be run on their own
+func operationCatalog() []catalogOperation {
+	catalog := []catalogOperation{
+		{
+			name:         "list namespaces",
+			groupVersion: "v1",
+			resource:     "namespaces",
+			verbs:        []string{"list"},
+			tags:         []string{"list", "completion"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				_, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+				return err
+			},
+		},
+		{
+			name:         "list API resources",
+			groupVersion: "-",
+			resource:     "/api, /apis",
+			verbs:        []string{"get"},
+			tags:         []string{"discovery", "completion"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				return listAPIResources(clientset)
+			},
+		},
+		{
+			name:         "list all API resources",
+			groupVersion: "-",
+			resource:     "/api, /apis/*",
+			verbs:        []string{"get"},
+			tags:         []string{"discovery"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				return listAllAPIResources(clientset)
+			},
+		},
+		{
+			name:         "list Custom Resource Definitions",
+			groupVersion: "apiextensions.k8s.io/v1",
+			resource:     "customresourcedefinitions",
+			verbs:        []string{"list"},
+			tags:         []string{"list", "crd"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				return listCRDs(ctx, config, results)
+			},
+		},
+		{
+			name:         "list PriorityClasses",
+			groupVersion: "scheduling.k8s.io/v1",
+			resource:     "priorityclasses",
+			verbs:        []string{"list"},
+			tags:         []string{"list"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				return listPriorityClasses(ctx, clientset, results)
+			},
+		},
+		{
+			name:         "list StorageClasses",
+			groupVersion: "storage.k8s.io/v1",
+			resource:     "storageclasses",
+			verbs:        []string{"list"},
+			tags:         []string{"list"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				return listStorageClasses(ctx, clientset, results)
+			},
+		},
+	}
+	for _, op := range namespacedOperations {
+		catalog = append(catalog, catalogOperation{
+			name:         op.name,
+			namespaced:   true,
+			groupVersion: op.groupVersion,
+			resource:     op.resource,
+			verbs:        []string{"list"},
+			tags:         []string{"list", "completion"},
+			run: func(ctx context.Context, clientset *kubernetes.Clientset, config *rest.Config, namespace, name string, results *BenchmarkResults) error {
+				return op.list(ctx, clientset, namespace, name, results)
+			},
+		})
+	}
+	return catalog
+}
+
+// runListOps implements the list-ops subcommand, printing the operation catalogue
+func runListOps(args []string) int {
+	fs := flag.NewFlagSet("list-ops", flag.ExitOnError)
+	tag := fs.String("tag", "", "Only print operations with this tag")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s list-ops [-tag tag]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	table := NewTable("Operation", "Scope", "Group/Version", "Resource", "Verbs", "Tags")
+	for _, op := range operationCatalog() {
+		if *tag != "" && !op.hasTag(*tag) {
+			continue
+		}
+		scope := "cluster"
+		if op.namespaced {
+			scope = "namespaced"
+		}
+		table.AddRow(op.name, scope, op.groupVersion, op.resource, strings.Join(op.verbs, ","), strings.Join(op.tags, ","))
+	}
+	table.Render(os.Stdout)
+	return 0
+}
+
+// hasTag reports whether the operation carries the tag
+func (op catalogOperation) hasTag(tag string) bool {
+	for _, t := range op.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
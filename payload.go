@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxPayloadSize keeps seeded ConfigMaps below the 1 MiB object size limit of the apiserver
+const maxPayloadSize = 1000 * 1024
+
+// parseSize parses a size such as "512", "1KB" or "2MB" into bytes
+func parseSize(value string) (int, error) {
+	units := []struct {
+		suffix     string
+		multiplier int
+	}{
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
+	}
+
+	upper := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := 1
+	for _, unit := range units {
+		if strings.HasSuffix(upper, unit.suffix) {
+			upper = strings.TrimSuffix(upper, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	number, err := strconv.Atoi(strings.TrimSpace(upper))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return number * multiplier, nil
+}
+
+// parsePayloadSizes parses a comma-separated list of payload sizes
+func parsePayloadSizes(value string) ([]string, error) {
+	var sizes []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		size, err := parseSize(field)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxPayloadSize {
+			return nil, fmt.Errorf("size %q exceeds the maximum of %dKB", field, maxPayloadSize/1024)
+		}
+		sizes = append(sizes, field)
+	}
+	return sizes, nil
+}
+
+// benchmarkPayloadSizes seeds ConfigMaps of every payload size into the namespace and measures
+// GET and LIST latency per size. The seeded objects are deleted afterwards.
+func benchmarkPayloadSizes(clientset *kubernetes.Clientset, namespace string, sizes []string, objects, iterations int, results *BenchmarkResults) {
+	fmt.Printf("\n--- Payload size benchmark in namespace %s ---\n", namespace)
+
+	for _, sizeName := range sizes {
+		size, _ := parseSize(sizeName)
+		seedSet := "payload-" + strings.ToLower(sizeName)
+
+		fmt.Printf("Seeding %d ConfigMaps of %s\n", objects, sizeName)
+		names, err := seedConfigMaps(clientset, namespace, seedSet, objects, size)
+		if err == nil {
+			configMaps := clientset.CoreV1().ConfigMaps(namespace)
+
+			runBenchmark(fmt.Sprintf("get ConfigMap (%s)", sizeName), iterations, func(ctx context.Context) error {
+				_, err := configMaps.Get(ctx, names[0], metav1.GetOptions{})
+				return err
+			}, results)
+
+			runBenchmark(fmt.Sprintf("list ConfigMaps (%d x %s)", objects, sizeName), iterations, func(ctx context.Context) error {
+				_, err := configMaps.List(ctx, metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+				return err
+			}, results)
+		} else {
+			fmt.Printf("Error: %v\n", err)
+		}
+
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}
+}
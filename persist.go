@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// resultLabel marks the ConfigMaps holding run summaries, so they can be listed with kubectl
+	resultLabel = "k8s-api-bench/result"
+
+	// runIDAnnotation holds the unmodified run ID of a persisted summary
+	runIDAnnotation = "k8s-api-bench/run-id"
+
+	// maxConfigMapSize is the size limit of a ConfigMap enforced by the apiserver
+	maxConfigMapSize = 1 << 20
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// resultConfigMapName derives a valid object name from the run ID
+func resultConfigMapName(runID string) string {
+	name := "k8s-api-bench-" + invalidNameChars.ReplaceAllString(strings.ToLower(runID), "-")
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.TrimRight(name, ".-")
+}
+
+// persistSummary writes the run summary as summary.json into a ConfigMap named after the run in
+// the given namespace, replacing the ConfigMap of an earlier attempt of the same run
+func persistSummary(clientset *kubernetes.Clientset, namespace string, summary Summary) (string, error) {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding summary: %v", err)
+	}
+	if len(data) > maxConfigMapSize {
+		return "", fmt.Errorf("summary of %s exceeds the ConfigMap size limit of %s", formatBytes(int64(len(data))), formatBytes(maxConfigMapSize))
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: resultConfigMapName(summary.Metadata.RunID),
+			Labels: map[string]string{
+				createdByLabel: createdByValue,
+				resultLabel:    "true",
+			},
+			Annotations: map[string]string{runIDAnnotation: summary.Metadata.RunID},
+		},
+		Data: map[string]string{"summary.json": string(data)},
+	}
+
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	_, err = configMaps.Create(context.TODO(), configMap, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return "", fmt.Errorf("error writing result ConfigMap: %v", err)
+	}
+	return configMap.Name, nil
+}
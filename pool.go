@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// Connection pool settings of the transport, 0 keeps client-go's defaults of 25 idle
+// connections, no limit of connections and a 90s idle timeout. Over HTTP/2 requests are
+// multiplexed over a single connection, so pooling mostly matters with DISABLE_HTTP2 set.
+var (
+	maxIdleConns    int
+	maxConnsPerHost int
+	idleConnTimeout time.Duration
+)
+
+// tunedConnectionPool reports whether any connection pool setting differs from the defaults
+func tunedConnectionPool() bool {
+	return maxIdleConns > 0 || maxConnsPerHost > 0 || idleConnTimeout > 0
+}
+
+// tuneConnectionPool applies the connection pool settings to the transport client-go creates
+// for the config. It has to be installed before any other wrapper, which would hide the
+// transport.
+func tuneConnectionPool(config *rest.Config) {
+	if !tunedConnectionPool() {
+		return
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		transport, ok := rt.(*http.Transport)
+		if !ok {
+			return rt
+		}
+		// Plain HTTP configs share the default transport, which the hooks use as well
+		if rt == http.DefaultTransport {
+			transport = transport.Clone()
+		}
+		// The apiserver is the only host, so the idle limits apply to it alike
+		if maxIdleConns > 0 {
+			transport.MaxIdleConns = maxIdleConns
+			transport.MaxIdleConnsPerHost = maxIdleConns
+		}
+		if maxConnsPerHost > 0 {
+			transport.MaxConnsPerHost = maxConnsPerHost
+		}
+		if idleConnTimeout > 0 {
+			transport.IdleConnTimeout = idleConnTimeout
+		}
+		return transport
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// profiles are built-in scenarios bundling sensible settings for common use cases. They only
+// contain read-only benchmarks, so they are safe to run against any cluster.
+var profiles = map[string]map[string]interface{}{
+	// quick gives a first impression within a minute or two
+	"quick": {
+		"iterations":     3,
+		"warmup":         1,
+		"operations":     "tag:completion",
+		"max-namespaces": 5,
+	},
+	// completion reproduces what kubectl shell completion requests, the original motivation of
+	// this tool
+	"completion": {
+		"iterations":         10,
+		"warmup":             1,
+		"operations":         "tag:completion",
+		"kubectl-completion": "pods,deployments,services,configmaps,secrets",
+	},
+	// standard is a representative run with enough samples for stable medians
+	"standard": {
+		"iterations":       10,
+		"warmup":           2,
+		"max-namespaces":   20,
+		"namespace-sample": sampleLargest,
+	},
+	// exhaustive runs every read-only benchmark with enough samples for stable tail latencies
+	"exhaustive": {
+		"iterations":         50,
+		"warmup":             5,
+		"limit-sweep":        "50,500,5000,0",
+		"metadata-lists":     "pods,configmaps,secrets",
+		"table-lists":        "pods,configmaps,secrets",
+		"aggregated-apis":    true,
+		"cluster-wide-lists": true,
+	},
+}
+
+// profileSettings returns the settings of the named profile
+func profileSettings(name string) (map[string]interface{}, error) {
+	settings, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, expected one of %v", name, sortedKeys(profiles))
+	}
+
+	// Convert to the value types of decoded scenario files
+	converted := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if n, ok := value.(int); ok {
+			value = float64(n)
+		}
+		converted[key] = value
+	}
+	return converted, nil
+}
+
+// warmUp runs every selected catalogue operation the given number of times without recording
+// anything, so connection setup and cold caches do not skew the first measured iterations.
+// Namespaced operations are run in the default namespace.
+func warmUp(clientset *kubernetes.Clientset, config *rest.Config, rounds int) {
+	fmt.Printf("\n--- Warm-up (%d rounds) ---\n", rounds)
+
+	scratch := NewBenchmarkResults()
+	for _, op := range operationCatalog() {
+		if !operationSelected(op.name) {
+			continue
+		}
+		namespace := metav1.NamespaceAll
+		if op.namespaced {
+			namespace = metav1.NamespaceDefault
+		}
+		for i := 0; i < rounds; i++ {
+			if err := op.run(context.TODO(), clientset, config, namespace, op.name, scratch); err != nil {
+				fmt.Printf("Warm-up of %s failed: %v\n", op.name, err)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum time between two progress reports, 0 to disable them
+var progressInterval = 10 * time.Second
+
+// progress tracks the completion of the iterations planned at the start of the run
+var progress = &progressTracker{planned: make(map[string]int)}
+
+// progressTracker counts the completed iterations of the planned operations and periodically
+// reports the percentage complete and the estimated time remaining. Iterations of operations
+// that are not part of the plan, such as those of optional benchmarks, are not counted.
+type progressTracker struct {
+	mu         sync.Mutex
+	planned    map[string]int
+	total      int
+	done       int
+	start      time.Time
+	lastReport time.Time
+}
+
+// Plan adds the iterations of the operation to the plan
+func (p *progressTracker) Plan(operation string, iterations int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.start.IsZero() {
+		p.start = time.Now()
+		p.lastReport = p.start
+	}
+	p.planned[operation] += iterations
+	p.total += iterations
+}
+
+// Total returns the number of planned iterations
+func (p *progressTracker) Total() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.total
+}
+
+// Done counts an iteration of the operation as completed, whether it was measured, failed or
+// skipped, and reports the progress if progressInterval passed since the last report
+func (p *progressTracker) Done(operation string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.planned[operation] <= 0 {
+		return
+	}
+	p.planned[operation]--
+	p.done++
+
+	now := time.Now()
+	if progressInterval <= 0 || now.Sub(p.lastReport) < progressInterval {
+		return
+	}
+	p.lastReport = now
+	elapsed := now.Sub(p.start)
+	remaining := time.Duration(float64(elapsed) / float64(p.done) * float64(p.total-p.done))
+	fmt.Printf("Progress: %.1f%% (%d/%d planned iterations), %v elapsed, about %v remaining\n",
+		float64(p.done)/float64(p.total)*100, p.done, p.total, elapsed.Round(time.Second), remaining.Round(time.Second))
+}
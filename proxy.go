@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// newProxiedConfig returns a copy of config that reaches the apiserver either through a local
+// API proxy such as "kubectl proxy", which handles authentication itself, or through a forward
+// HTTP proxy
+func newProxiedConfig(config *rest.Config, apiProxy, httpProxy string) (*rest.Config, error) {
+	if apiProxy != "" {
+		proxied := rest.AnonymousClientConfig(config)
+		proxied.Host = apiProxy
+		return proxied, nil
+	}
+
+	proxyURL, err := url.Parse(httpProxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	proxied := rest.CopyConfig(config)
+	proxied.Proxy = http.ProxyURL(proxyURL)
+	return proxied, nil
+}
+
+// proxiedOperation is an operation that can be run against either of the compared clients
+type proxiedOperation struct {
+	name string
+	run  func(ctx context.Context, clientset *kubernetes.Clientset, name string) error
+}
+
+// comparedOperations returns the read operations run against each of the compared clients
+func comparedOperations(results *BenchmarkResults) []proxiedOperation {
+	operations := []proxiedOperation{
+		{"list namespaces", func(ctx context.Context, clientset *kubernetes.Clientset, name string) error {
+			_, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			return err
+		}},
+		{"list API resources", func(ctx context.Context, clientset *kubernetes.Clientset, name string) error {
+			return listAPIResources(clientset)
+		}},
+	}
+	for _, op := range namespacedOperations {
+		operations = append(operations, proxiedOperation{clusterWideOperation(op.name), func(ctx context.Context, clientset *kubernetes.Clientset, name string) error {
+			return op.list(ctx, clientset, metav1.NamespaceAll, name, results)
+		}})
+	}
+	return operations
+}
+
+// benchmarkProxyComparison runs a set of read operations directly and through the proxy back to
+// back, recording them as "<operation> (direct)" and "<operation> (via proxy)" and reporting the
+// difference of the median latencies as proxy overhead
+func benchmarkProxyComparison(direct, proxied *kubernetes.Clientset, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- Direct vs proxied operations ---")
+
+	operations := comparedOperations(results)
+
+	for _, op := range operations {
+		directName := op.name + " (direct)"
+		proxiedName := op.name + " (via proxy)"
+
+		// Alternate between both paths, so they are measured under the same conditions
+		runInterleaved([]interleavedOperation{
+			{name: directName, run: func(ctx context.Context) error { return op.run(ctx, direct, directName) }},
+			{name: proxiedName, run: func(ctx context.Context) error { return op.run(ctx, proxied, proxiedName) }},
+		}, iterations, results)
+	}
+
+	stats := results.CalculateStats()
+	for _, op := range operations {
+		directStats, ok := stats[op.name+" (direct)"]
+		if !ok {
+			continue
+		}
+		proxiedStats, ok := stats[op.name+" (via proxy)"]
+		if !ok {
+			continue
+		}
+		results.SetMetric(fmt.Sprintf("proxy overhead %s (ms)", op.name), durationMs(proxiedStats["median"]-directStats["median"]))
+	}
+}
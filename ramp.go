@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	rampChartWidth  = 600
+	rampChartHeight = 160
+)
+
+// RampPoint is the latency and throughput of the ramp operation at one concurrency level
+type RampPoint struct {
+	Concurrency int     `json:"concurrency"`
+	Requests    int     `json:"requests"`
+	Errors      int     `json:"errors"`
+	P50Ms       float64 `json:"p50_ms"`
+	P95Ms       float64 `json:"p95_ms"`
+	Throughput  float64 `json:"throughput"`
+}
+
+// rampOperation returns the name under which the ramp operation is recorded at a concurrency level
+func rampOperation(concurrency int) string {
+	return fmt.Sprintf("ramp GET Namespace (concurrency %d)", concurrency)
+}
+
+// rampConfig returns the config of the ramp's client. Under client-go's default rate limit every
+// level would measure the limiter rather than the apiserver, so unless --qps or --burst was set
+// the ramp runs without a client-side rate limit.
+func rampConfig(config *rest.Config) *rest.Config {
+	rampConfig := rest.CopyConfig(config)
+	limitSet := false
+	flag.Visit(func(f *flag.Flag) {
+		limitSet = limitSet || f.Name == "qps" || f.Name == "burst"
+	})
+	if !limitSet {
+		rampConfig.QPS = -1
+	}
+	return rampConfig
+}
+
+// benchmarkRamp issues requests GETs of the namespace at every concurrency level in turn, from
+// as many concurrent workers, and records the latency-vs-concurrency curve: median and p95
+// latency and the achieved throughput per level
+func benchmarkRamp(config *rest.Config, namespace string, levels []int, requests int, results *BenchmarkResults) {
+	fmt.Printf("\n--- Concurrency ramp against namespace %s ---\n", namespace)
+	config = rampConfig(config)
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating ramp client: %v\n", err)
+		return
+	}
+	namespaces := clientset.CoreV1().Namespaces()
+
+	for _, concurrency := range levels {
+		name := rampOperation(concurrency)
+		// Levels restored from a checkpoint keep their point, as the throughput cannot be recomputed
+		if results.ResumedIterations(name) >= requests {
+			continue
+		}
+		elapsed := runConcurrently(name, requests, concurrency, func(ctx context.Context, _ int) error {
+			_, err := namespaces.Get(ctx, namespace, metav1.GetOptions{})
+			return err
+		}, results)
+
+		succeeded := results.Count(name)
+		point := RampPoint{
+			Concurrency: concurrency,
+			Requests:    requests,
+			Errors:      requests - succeeded,
+			Throughput:  float64(succeeded) / elapsed.Seconds(),
+		}
+		if stat, ok := results.CalculateStats()[name]; ok {
+			point.P50Ms = durationMs(stat["median"])
+			point.P95Ms = durationMs(stat["p95"])
+		}
+		fmt.Printf("Concurrency %d: p50 %s, p95 %s, %.1f requests/s\n",
+			concurrency, formatMs(point.P50Ms), formatMs(point.P95Ms), point.Throughput)
+		results.Ramp = replaceRestored(results.Ramp, point, func(p RampPoint) int { return p.Concurrency })
+	}
+
+	if analysis, ok := analyzeSaturation(results.Ramp); ok {
+		if config.QPS > 0 {
+			analysis.ClientQPS = float64(config.QPS)
+		}
+		results.Saturation = analysis
+	}
+}
+
+// rampChart is the latency-vs-concurrency curve rendered as SVG polylines, with the concurrency
+// levels evenly spaced on the x axis
+type rampChart struct {
+	P50        string
+	P95        string
+	Throughput string
+	MaxMs      float64
+	MaxRate    float64
+}
+
+// newRampChart renders the ramp curve, or returns nil if there is none
+func newRampChart(points []RampPoint) *rampChart {
+	if len(points) == 0 {
+		return nil
+	}
+	chart := &rampChart{}
+	for _, point := range points {
+		chart.MaxMs = max(chart.MaxMs, point.P95Ms)
+		chart.MaxRate = max(chart.MaxRate, point.Throughput)
+	}
+
+	var p50, p95, throughput []string
+	for i, point := range points {
+		x := rampChartWidth / 2.0
+		if len(points) > 1 {
+			x = float64(i) / float64(len(points)-1) * rampChartWidth
+		}
+		y := func(value, maxValue float64) float64 {
+			if maxValue == 0 {
+				return rampChartHeight
+			}
+			return rampChartHeight - value/maxValue*rampChartHeight
+		}
+		p50 = append(p50, fmt.Sprintf("%.1f,%.1f", x, y(point.P50Ms, chart.MaxMs)))
+		p95 = append(p95, fmt.Sprintf("%.1f,%.1f", x, y(point.P95Ms, chart.MaxMs)))
+		throughput = append(throughput, fmt.Sprintf("%.1f,%.1f", x, y(point.Throughput, chart.MaxRate)))
+	}
+	chart.P50 = strings.Join(p50, " ")
+	chart.P95 = strings.Join(p95, " ")
+	chart.Throughput = strings.Join(throughput, " ")
+	return chart
+}
+
+// PrintRamp prints the latency-vs-concurrency curve, if a ramp was run
+func (br *BenchmarkResults) PrintRamp() {
+	if len(br.Ramp) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Latency vs Concurrency ---")
+	table := NewTable("Concurrency", "Requests", "Errors", "P50", "P95", "Throughput (req/s)")
+	for _, point := range br.Ramp {
+		table.AddRow(
+			fmt.Sprintf("%d", point.Concurrency),
+			fmt.Sprintf("%d", point.Requests),
+			fmt.Sprintf("%d", point.Errors),
+			formatMs(point.P50Ms),
+			formatMs(point.P95Ms),
+			fmt.Sprintf("%.1f", point.Throughput),
+		)
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// iterationRate is the fixed rate in iterations per second at which runBenchmark issues the
+// iterations of an operation, 0 to run them back to back
+var iterationRate float64
+
+// correctedOperation returns the name under which the latencies of an operation corrected for
+// coordinated omission are recorded
+func correctedOperation(operation string) string {
+	return operation + " (corrected)"
+}
+
+// runAtRate runs the iterations of an operation at iterationRate. Every iteration has an
+// intended start time on a fixed schedule; when an earlier slow response delays it, the wait is
+// part of the latency a client issuing requests at that rate would have seen. Besides the
+// measured latency, the latency from the intended start is therefore recorded under the
+// corrected operation, so that tail latencies under load are not understated.
+func runAtRate(name string, iterations int, f func(ctx context.Context) error, results *BenchmarkResults) {
+	interval := time.Duration(float64(time.Second) / iterationRate)
+	scheduleStart := time.Now()
+	for i := 0; i < iterations; i++ {
+		progress.Done(name)
+
+		// Iterations restored from a checkpoint have already been measured
+		if i+1 <= results.ResumedIterations(name) || results.skipIteration(name) {
+			continue
+		}
+
+		intended := scheduleStart.Add(time.Duration(i) * interval)
+		if wait := time.Until(intended); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		ctx, throttled := withThrottleCounter(context.Background())
+		ctx, connected := withConnectionCounter(ctx)
+		startTime := time.Now()
+		err := f(ctx)
+		endTime := time.Now()
+		recordIteration(name, i+1, iterations, startTime, endTime.Sub(startTime), err, results)
+		results.recordOutcome(name, err)
+		results.AddThrottled(name, time.Duration(throttled.Load()))
+		results.AddConnections(name, connected.Counts())
+		if err == nil {
+			results.Add(correctedOperation(name), endTime.Sub(intended))
+		}
+	}
+}
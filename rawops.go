@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// rawOperation is a custom operation defined in the scenario file as a raw REST request, for
+// endpoints the tool doesn't model such as /logs or custom aggregated routes
+type rawOperation struct {
+	Name   string `json:"name"`
+	Verb   string `json:"verb"`
+	Path   string `json:"path"`
+	Accept string `json:"accept"`
+	Body   string `json:"body"`
+	// ContentType of the body, application/json by default
+	ContentType string `json:"content-type"`
+}
+
+// rawOperationVerbs are the HTTP methods raw operations may use
+var rawOperationVerbs = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// parseRawOperations decodes and validates the raw-operations list of a scenario, defaulting the
+// verb to GET, the Accept and Content-Type headers to application/json and the name to
+// "<verb> <path>"
+func parseRawOperations(value interface{}) ([]rawOperation, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var operations []rawOperation
+	if err := decoder.Decode(&operations); err != nil {
+		return nil, fmt.Errorf("expected a list of operations with name, verb, path, accept, body and content-type: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for i := range operations {
+		op := &operations[i]
+		if !strings.HasPrefix(op.Path, "/") {
+			return nil, fmt.Errorf("path %q of raw operation %d must be absolute", op.Path, i+1)
+		}
+		op.Verb = strings.ToUpper(op.Verb)
+		if op.Verb == "" {
+			op.Verb = http.MethodGet
+		}
+		if !rawOperationVerbs[op.Verb] {
+			return nil, fmt.Errorf("unsupported verb %q of raw operation %d", op.Verb, i+1)
+		}
+		if op.Accept == "" {
+			op.Accept = "application/json"
+		}
+		if op.ContentType == "" {
+			op.ContentType = "application/json"
+		}
+		if op.Name == "" {
+			op.Name = op.Verb + " " + op.Path
+		}
+		if names[op.Name] {
+			return nil, fmt.Errorf("duplicate raw operation %q", op.Name)
+		}
+		names[op.Name] = true
+	}
+	return operations, nil
+}
+
+// benchmarkRawOperations sends every raw operation as a request to the apiserver, recording its
+// latency and response size under the operation's name
+func benchmarkRawOperations(clientset *kubernetes.Clientset, operations []rawOperation, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- Raw REST operations ---")
+
+	for _, op := range operations {
+		u, err := url.Parse(op.Path)
+		if err != nil {
+			fmt.Printf("Error parsing path of %s: %v\n", op.Name, err)
+			continue
+		}
+		runBenchmark(op.Name, iterations, func(ctx context.Context) error {
+			req := clientset.Discovery().RESTClient().Verb(op.Verb).
+				AbsPath(u.Path).
+				SetHeader("Accept", op.Accept)
+			for key, values := range u.Query() {
+				for _, value := range values {
+					req = req.Param(key, value)
+				}
+			}
+			if op.Body != "" {
+				req = req.SetHeader("Content-Type", op.ContentType).Body([]byte(op.Body))
+			}
+			body, err := req.DoRaw(ctx)
+			if err != nil {
+				return err
+			}
+			results.AddSize(op.Name, len(body))
+			return nil
+		}, results)
+	}
+}
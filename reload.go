@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// credentialReloadInterval is how often the kubeconfig and credential files are checked for changes
+const credentialReloadInterval = 30 * time.Second
+
+// reloadCredentials picks up client certificates and tokens that are rotated on disk during the
+// run, so long-lived runs keep working after the original credentials expire
+var reloadCredentials bool
+
+// credentialReloader keeps the credentials of a config in private files that client-go
+// re-reads: client certificate files on every TLS handshake and token files every minute.
+// When the kubeconfig or the files it references change, the files are rewritten from it.
+type credentialReloader struct {
+	kubeconfig string
+	dir        string
+
+	// Kubeconfig and credential files referenced by it, with their last modification time
+	sources map[string]time.Time
+}
+
+// newCredentialReloader moves the client certificate, key and bearer token of config into
+// reloadable files. Credentials from exec plugins and auth providers refresh themselves and
+// are left alone.
+func newCredentialReloader(kubeconfig string, config *rest.Config) (*credentialReloader, error) {
+	dir, err := os.MkdirTemp("", "k8s-api-bench-credentials-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating credentials directory: %v", err)
+	}
+	r := &credentialReloader{kubeconfig: kubeconfig, dir: dir}
+	if err := r.materialize(config); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	hasCert := len(config.CertData) > 0 || config.CertFile != ""
+	hasKey := len(config.KeyData) > 0 || config.KeyFile != ""
+	if hasCert && hasKey {
+		config.CertFile, config.KeyFile = r.path("client.crt"), r.path("client.key")
+		config.CertData, config.KeyData = nil, nil
+	}
+	if config.BearerToken != "" || config.BearerTokenFile != "" {
+		config.BearerTokenFile = r.path("token")
+		config.BearerToken = ""
+	}
+	return r, nil
+}
+
+// path returns the path of a credential file in the private directory
+func (r *credentialReloader) path(name string) string {
+	return filepath.Join(r.dir, name)
+}
+
+// materialize writes the credentials of config into the private directory and remembers the
+// files they were read from
+func (r *credentialReloader) materialize(config *rest.Config) error {
+	r.sources = map[string]time.Time{r.kubeconfig: modTime(r.kubeconfig)}
+	credentials := []struct {
+		name string
+		data []byte
+		file string
+	}{
+		{"client.crt", config.CertData, config.CertFile},
+		{"client.key", config.KeyData, config.KeyFile},
+		{"token", []byte(config.BearerToken), config.BearerTokenFile},
+	}
+	for _, credential := range credentials {
+		data := credential.data
+		if len(data) == 0 && credential.file != "" {
+			r.sources[credential.file] = modTime(credential.file)
+			var err error
+			if data, err = os.ReadFile(credential.file); err != nil {
+				return fmt.Errorf("error reading %s: %v", credential.file, err)
+			}
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if err := writeFileAtomically(r.path(credential.name), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changed reports whether any source file was modified since the credentials were written
+func (r *credentialReloader) changed() bool {
+	for path, written := range r.sources {
+		if !modTime(path).Equal(written) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch rewrites the credential files whenever the kubeconfig or the files it references change,
+// recording every rotation as a timeline event, until stop is closed
+func (r *credentialReloader) Watch(results *BenchmarkResults, stop <-chan struct{}) {
+	defer os.RemoveAll(r.dir)
+
+	ticker := time.NewTicker(credentialReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		if !r.changed() {
+			continue
+		}
+
+		config, err := clientcmd.BuildConfigFromFlags("", r.kubeconfig)
+		if err == nil {
+			err = r.materialize(config)
+		}
+		if err != nil {
+			// Keep the previous credentials and retry once the files change again
+			for path := range r.sources {
+				r.sources[path] = modTime(path)
+			}
+			results.AddEvent(fmt.Sprintf("credential rotation failed: %v", err))
+			continue
+		}
+		results.AddEvent(fmt.Sprintf("client credentials reloaded from %s", r.kubeconfig))
+	}
+}
+
+// modTime returns the modification time of a file, or the zero time if it cannot be read
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// writeFileAtomically replaces a file by renaming a temporary file over it, so readers never
+// see a partially written file
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing %s: %v", filepath.Base(path), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %v", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %v", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+const replHelp = `Commands:
+  <operation> [-n namespace] [-i iterations]  run an operation, "-n all" runs namespaced operations cluster-wide
+  ops                                         list the available operations
+  namespace <namespace>                       change the default namespace
+  iterations <n>                              change the default number of iterations
+  stats                                       print the statistics collected in this session
+  reset                                       discard the statistics collected in this session
+  help                                        show this help
+  quit                                        leave the interactive mode
+Press Tab to complete operation names and commands.
+`
+
+// replCommands are the built-in commands of the interactive mode
+var replCommands = []string{"ops", "namespace", "iterations", "stats", "reset", "help", "quit"}
+
+// replSession holds the state of an interactive session
+type replSession struct {
+	clientset  *kubernetes.Clientset
+	config     *rest.Config
+	operations map[string]catalogOperation
+	namespace  string
+	iterations int
+	results    *BenchmarkResults
+}
+
+// parseReplLine splits an input line into the operation name and its -n and -i options
+func parseReplLine(line string, namespace string, iterations int) (string, string, int, error) {
+	fields := strings.Fields(line)
+	var name []string
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "-n", "-i":
+			if i+1 == len(fields) {
+				return "", "", 0, fmt.Errorf("missing value for %s", fields[i])
+			}
+			if fields[i] == "-n" {
+				namespace = fields[i+1]
+			} else {
+				n, err := strconv.Atoi(fields[i+1])
+				if err != nil || n < 1 {
+					return "", "", 0, fmt.Errorf("invalid iterations %q", fields[i+1])
+				}
+				iterations = n
+			}
+			i++
+		default:
+			name = append(name, fields[i])
+		}
+	}
+	return strings.Join(name, " "), namespace, iterations, nil
+}
+
+// complete returns the completions of the input among the operation names and commands
+func (s *replSession) complete(input string) []string {
+	var candidates []string
+	for _, name := range append(sortedKeys(s.operations), replCommands...) {
+		if strings.HasPrefix(name, input) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// commonPrefix returns the longest common prefix of the given strings
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// execute runs a single input line and reports whether the session should end
+func (s *replSession) execute(line string) bool {
+	line = strings.TrimSpace(line)
+	command, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch command {
+	case "":
+	case "quit", "exit":
+		return true
+	case "help":
+		fmt.Print(replHelp)
+	case "ops":
+		for _, name := range sortedKeys(s.operations) {
+			scope := "cluster"
+			if s.operations[name].namespaced {
+				scope = "namespaced"
+			}
+			fmt.Printf("  %-40s %s\n", name, scope)
+		}
+	case "namespace":
+		if argument == "" {
+			fmt.Printf("Namespace: %s\n", s.namespace)
+			break
+		}
+		s.namespace = argument
+	case "iterations":
+		n, err := strconv.Atoi(argument)
+		if err != nil || n < 1 {
+			fmt.Printf("Error: invalid iterations %q\n", argument)
+			break
+		}
+		s.iterations = n
+	case "stats":
+		s.results.PrintStats()
+		s.results.PrintErrors()
+	case "reset":
+		s.results = NewBenchmarkResults()
+		fmt.Println("Statistics discarded")
+	default:
+		name, namespace, iterations, err := parseReplLine(line, s.namespace, s.iterations)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			break
+		}
+		op, ok := s.operations[name]
+		if !ok {
+			fmt.Printf("Unknown operation or command %q, type \"help\" for help\n", name)
+			break
+		}
+
+		recordedName := op.name
+		if op.namespaced {
+			if namespace == "all" {
+				namespace = metav1.NamespaceAll
+				recordedName = clusterWideOperation(op.name)
+			} else {
+				recordedName = namespacedOperation(op.name, namespace)
+			}
+		}
+		runBenchmark(recordedName, iterations, func(ctx context.Context) error {
+			return op.run(ctx, s.clientset, s.config, namespace, recordedName, s.results)
+		}, s.results)
+	}
+	return false
+}
+
+// readLines reads input lines, with line editing and Tab completion when stdin is a terminal,
+// passing each to handle until it returns true
+func (s *replSession) readLines(handle func(string) bool) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if handle(scanner.Text()) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "k8s-api-bench> ")
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' || pos != len(line) {
+			return "", 0, false
+		}
+		candidates := s.complete(line)
+		if len(candidates) == 0 {
+			return "", 0, false
+		}
+		completed := commonPrefix(candidates)
+		if completed == line && len(candidates) > 1 {
+			fmt.Fprintf(terminal, "%s\n", strings.Join(candidates, "\n"))
+		}
+		return completed, len(completed), true
+	}
+
+	for {
+		// Only line editing needs raw mode, benchmark output is printed in the normal mode
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("error switching terminal to raw mode: %v", err)
+		}
+		line, err := terminal.ReadLine()
+		term.Restore(fd, state)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if handle(line) {
+			return nil
+		}
+	}
+}
+
+// runRepl implements the repl subcommand, an interactive mode running operations ad hoc and
+// accumulating their statistics across the session
+func runRepl(args []string) int {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	kubeconfig := fs.String("kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Path to the kubeconfig file")
+	namespace := fs.String("n", metav1.NamespaceDefault, "Default namespace of namespaced operations")
+	iterations := fs.Int("iterations", 1, "Default number of iterations per operation")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s repl [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *iterations < 1 {
+		fmt.Println("Error: iterations must be at least 1")
+		return 1
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		fmt.Printf("Error building kubeconfig: %v\n", err)
+		return 1
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating Kubernetes client: %v\n", err)
+		return 1
+	}
+
+	session := &replSession{
+		clientset:  clientset,
+		config:     config,
+		operations: make(map[string]catalogOperation),
+		namespace:  *namespace,
+		iterations: *iterations,
+		results:    NewBenchmarkResults(),
+	}
+	for _, op := range operationCatalog() {
+		session.operations[op.name] = op
+	}
+
+	fmt.Printf("Connected to %s, type \"help\" for help\n", config.Host)
+	if err := session.readLines(session.execute); err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		return 1
+	}
+	return 0
+}
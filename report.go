@@ -0,0 +1,435 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// RunMetadata describes the environment a benchmark run was executed in
+type RunMetadata struct {
+	RunID       string            `json:"run_id"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ToolVersion string            `json:"tool_version"`
+	GoVersion   string            `json:"go_version"`
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	Hostname    string            `json:"hostname"`
+	Kubeconfig  string            `json:"kubeconfig"`
+	Server      string            `json:"server"`
+	Iterations  int               `json:"iterations"`
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     time.Time         `json:"end_time"`
+
+	// Build describes the build of the tool and the Kubernetes libraries compiled into it
+	Build BuildInfo `json:"build"`
+
+	// Client describes the effective client-side settings that influence latency
+	Client ClientSettings `json:"client"`
+
+	// Cluster describes the benchmarked cluster, if it could be captured
+	Cluster *ClusterInfo `json:"cluster,omitempty"`
+
+	// NetworkFloor holds the raw connection latency to the apiserver, if it was measured
+	NetworkFloor *NetworkFloor `json:"network_floor,omitempty"`
+
+	// Tainted marks runs against a cluster that failed the health gate, whose results should
+	// not be used as a baseline
+	Tainted        bool     `json:"tainted,omitempty"`
+	HealthProblems []string `json:"health_problems,omitempty"`
+}
+
+// ClientSettings holds the client library version and the effective rate limiting and transport
+// options, making results from different machines comparable
+type ClientSettings struct {
+	ClientGoVersion    string        `json:"client_go_version"`
+	QPS                float32       `json:"qps"`
+	Burst              int           `json:"burst"`
+	Timeout            time.Duration `json:"timeout"`
+	UserAgent          string        `json:"user_agent"`
+	ContentType        string        `json:"content_type"`
+	DisableCompression bool          `json:"disable_compression"`
+	HTTP2              bool          `json:"http2"`
+	Insecure           bool          `json:"insecure"`
+	Proxy              bool          `json:"proxy"`
+	// Connection pool settings, 0 for the client-go defaults
+	MaxIdleConns    int           `json:"max_idle_conns,omitempty"`
+	MaxConnsPerHost int           `json:"max_conns_per_host,omitempty"`
+	IdleConnTimeout time.Duration `json:"idle_conn_timeout,omitempty"`
+	// Command of the exec credential plugin or name of the auth provider, if any
+	Credentials string `json:"credentials,omitempty"`
+}
+
+// captureClientSettings records the client settings of the given config
+func captureClientSettings(config *rest.Config) ClientSettings {
+	info, _ := debug.ReadBuildInfo()
+	settings := ClientSettings{
+		ClientGoVersion:    moduleVersion(info, "k8s.io/client-go"),
+		QPS:                config.QPS,
+		Burst:              config.Burst,
+		Timeout:            config.Timeout,
+		UserAgent:          config.UserAgent,
+		ContentType:        config.ContentType,
+		DisableCompression: config.DisableCompression,
+		// client-go disables HTTP/2 when this environment variable is set
+		HTTP2:    os.Getenv("DISABLE_HTTP2") == "",
+		Insecure: config.Insecure,
+		Proxy:    config.Proxy != nil,
+
+		MaxIdleConns:    maxIdleConns,
+		MaxConnsPerHost: maxConnsPerHost,
+		IdleConnTimeout: idleConnTimeout,
+	}
+	if config.ExecProvider != nil {
+		settings.Credentials = "exec " + config.ExecProvider.Command
+	} else if config.AuthProvider != nil {
+		settings.Credentials = "auth-provider " + config.AuthProvider.Name
+	}
+	if settings.UserAgent == "" {
+		settings.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+	if settings.ContentType == "" {
+		settings.ContentType = "application/json"
+	}
+	return settings
+}
+
+// NewRunMetadata captures the client environment of the current run
+func NewRunMetadata(runID string, labels map[string]string, kubeconfig string, config *rest.Config, iterations int) RunMetadata {
+	hostname, _ := os.Hostname()
+	return RunMetadata{
+		RunID:       runID,
+		Labels:      labels,
+		ToolVersion: version,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Hostname:    hostname,
+		Kubeconfig:  kubeconfig,
+		Server:      config.Host,
+		Iterations:  iterations,
+		StartTime:   time.Now(),
+		Build:       readBuildInfo(),
+		Client:      captureClientSettings(config),
+	}
+}
+
+// OperationSummary holds the statistics of a single operation in milliseconds
+type OperationSummary struct {
+	Operation string  `json:"operation"`
+	Count     int     `json:"count"`
+	MinMs     float64 `json:"min_ms"`
+	MaxMs     float64 `json:"max_ms"`
+	AvgMs     float64 `json:"avg_ms"`
+	MedianMs  float64 `json:"median_ms"`
+	P95Ms     float64 `json:"p95_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+	AvgBytes  int64   `json:"avg_bytes,omitempty"`
+	// ThrottledMs is the time spent backing off from 429 responses
+	ThrottledMs float64 `json:"throttled_ms,omitempty"`
+	// Connections counts the requests sent over new and reused connections
+	Connections *ConnectionCounts `json:"connections,omitempty"`
+}
+
+// Summary is the machine-readable result of a benchmark run
+type Summary struct {
+	Metadata          RunMetadata                   `json:"metadata"`
+	Operations        []OperationSummary            `json:"operations"`
+	SlowestNamespaces map[string][]NamespaceLatency `json:"slowest_namespaces,omitempty"`
+	Workers           map[string][]WorkerStats      `json:"workers,omitempty"`
+	Windows           map[string][]WindowStats      `json:"windows,omitempty"`
+	APIGroups         []APIGroupSummary             `json:"api_groups,omitempty"`
+	Ramp              []RampPoint                   `json:"ramp,omitempty"`
+	Saturation        *SaturationAnalysis           `json:"saturation,omitempty"`
+	PageSizes         []PageSizeRecommendation      `json:"page_sizes,omitempty"`
+	ManagedFields     []ManagedFieldsImpact         `json:"managed_fields,omitempty"`
+	ServerTimings     []ServerTiming                `json:"server_timings,omitempty"`
+	Events            []TimelineEvent               `json:"events,omitempty"`
+	Skipped           []SkippedOperation            `json:"skipped,omitempty"`
+	Incomplete        []IncompleteOperation         `json:"incomplete,omitempty"`
+	Metrics           map[string]float64            `json:"metrics,omitempty"`
+	ClientRuntime     *ClientRuntime                `json:"client_runtime,omitempty"`
+	Timeouts          []TimeoutSummary              `json:"timeouts,omitempty"`
+	Errors            []ErrorSummary                `json:"errors,omitempty"`
+}
+
+// NewSummary builds the summary of the benchmark results
+func NewSummary(metadata RunMetadata, br *BenchmarkResults) Summary {
+	stats := br.CalculateStats()
+	summary := Summary{
+		Metadata:          metadata,
+		SlowestNamespaces: br.SlowestNamespaces(),
+		Workers:           br.WorkerStats(),
+		APIGroups:         br.APIGroupSummaries(),
+		Ramp:              br.Ramp,
+		Saturation:        br.Saturation,
+		PageSizes:         br.PageSizes,
+		ManagedFields:     br.ManagedFields,
+		ServerTimings:     br.ServerTimings,
+		Events:            br.Events,
+		Skipped:           br.SkippedOperations(),
+		Incomplete:        br.IncompleteOperations(),
+		Metrics:           br.Metrics,
+		ClientRuntime:     br.Runtime,
+		Timeouts:          br.TimeoutSummaries(),
+		Errors:            br.ErrorSummaries(),
+	}
+	if statsWindow > 0 {
+		summary.Windows = br.WindowedStats(metadata.StartTime, statsWindow)
+	}
+	for _, op := range sortedOperations(stats) {
+		stat := stats[op]
+		avgBytes, _ := br.AvgSize(op)
+		summary.Operations = append(summary.Operations, OperationSummary{
+			Operation:   op,
+			Count:       br.Count(op),
+			MinMs:       durationMs(stat["min"]),
+			MaxMs:       durationMs(stat["max"]),
+			AvgMs:       durationMs(stat["avg"]),
+			MedianMs:    durationMs(stat["median"]),
+			P95Ms:       durationMs(stat["p95"]),
+			P99Ms:       durationMs(stat["p99"]),
+			AvgBytes:    avgBytes,
+			ThrottledMs: durationMs(br.ThrottledTime(op)),
+		})
+		if counts := br.ConnectionCounts(op); counts.New+counts.Reused > 0 {
+			summary.Operations[len(summary.Operations)-1].Connections = &counts
+		}
+	}
+	return summary
+}
+
+// rawSamples converts the recorded durations to milliseconds, keeping their recording order
+func rawSamples(br *BenchmarkResults) map[string][]float64 {
+	samples := make(map[string][]float64, len(br.Results))
+	for op, durations := range br.Results {
+		for _, d := range durations {
+			samples[op] = append(samples[op], durationMs(d))
+		}
+	}
+	return samples
+}
+
+// WriteArtifacts writes the summary JSON, latency histograms or digests, raw samples if kept,
+// the concurrency ramp curve if any, HTML report and environment metadata into a subdirectory
+// of outDir named after the run and returns the path of that subdirectory
+func WriteArtifacts(outDir string, metadata RunMetadata, br *BenchmarkResults) (string, error) {
+	runDir := filepath.Join(outDir, metadata.RunID)
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating output directory: %v", err)
+	}
+
+	summary := NewSummary(metadata, br)
+	artifacts := map[string]interface{}{
+		"summary.json":  summary,
+		"timeline.json": br.Timeline,
+		"metadata.json": metadata,
+	}
+	if streamingStats {
+		artifacts["digests.json"] = br.Digests
+	} else {
+		artifacts["histograms.json"] = br.Histograms
+	}
+	if keepRawSamples {
+		artifacts["samples.json"] = rawSamples(br)
+	}
+	if len(br.Ramp) > 0 {
+		artifacts["ramp.json"] = br.Ramp
+	}
+	for name, content := range artifacts {
+		if err := writeJSONFile(filepath.Join(runDir, name), content); err != nil {
+			return "", err
+		}
+	}
+
+	if err := writeHTMLReport(filepath.Join(runDir, "report.html"), summary, timelineCharts(br.Timeline)); err != nil {
+		return "", err
+	}
+
+	return runDir, nil
+}
+
+// writeJSONFile writes the content as indented JSON to path
+func writeJSONFile(path string, content interface{}) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %v", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>k8s-api-bench report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+td.num { text-align: right; font-family: monospace; }
+svg.timeline { background: #f8f8f8; border: 1px solid #ccc; }
+</style>
+</head>
+<body>
+<h1>k8s-api-bench report</h1>
+<table>
+<tr><th>Run ID</th><td>{{.Metadata.RunID}}</td></tr>
+{{- range $key, $value := .Metadata.Labels}}
+<tr><th>Label {{$key}}</th><td>{{$value}}</td></tr>
+{{- end}}
+<tr><th>Server</th><td>{{.Metadata.Server}}</td></tr>
+{{- with .Metadata.Cluster}}
+<tr><th>Server version</th><td>{{.ServerVersion}}</td></tr>
+<tr><th>Cluster size</th><td>{{.Nodes}} nodes, {{.Namespaces}} namespaces, {{.Pods}} pods</td></tr>
+{{- end}}
+{{- with .Metadata.NetworkFloor}}
+<tr><th>Network floor</th><td>TCP connect {{printf "%.3f" .TCPConnectMs}} ms, TLS handshake {{printf "%.3f" .TLSHandshakeMs}} ms</td></tr>
+{{- end}}
+{{- if .Metadata.Tainted}}
+<tr><th>Health</th><td>Tainted: {{range $i, $problem := .Metadata.HealthProblems}}{{if $i}}; {{end}}{{$problem}}{{end}}</td></tr>
+{{- end}}
+<tr><th>Start</th><td>{{.Metadata.StartTime}}</td></tr>
+<tr><th>End</th><td>{{.Metadata.EndTime}}</td></tr>
+<tr><th>Iterations</th><td>{{.Metadata.Iterations}}</td></tr>
+<tr><th>Tool version</th><td>{{.Metadata.ToolVersion}} (commit {{.Metadata.Build.Commit}}, built {{.Metadata.Build.BuildDate}}, k8s.io/api {{.Metadata.Build.APIVersion}})</td></tr>
+<tr><th>Client</th><td>{{.Metadata.Hostname}} ({{.Metadata.OS}}/{{.Metadata.Arch}}, {{.Metadata.GoVersion}}, client-go {{.Metadata.Client.ClientGoVersion}})</td></tr>
+{{- with .Metadata.Client}}
+<tr><th>Rate limit</th><td>{{.QPS}} QPS, burst {{.Burst}}</td></tr>
+<tr><th>Transport</th><td>{{.ContentType}}, HTTP/2 {{.HTTP2}}, compression disabled {{.DisableCompression}}, insecure {{.Insecure}}, proxy {{.Proxy}}, timeout {{.Timeout}}</td></tr>
+<tr><th>User agent</th><td>{{.UserAgent}}</td></tr>
+{{- end}}
+{{- with .ClientRuntime}}
+<tr><th>Client runtime</th><td>CPU {{printf "%.2f" .CPUSeconds}} s ({{printf "%.0f" .CPUPercent}}%), GC {{.GCCycles}} cycles, pauses {{printf "%.3f" .GCPauseTotalMs}} ms total, {{printf "%.3f" .GCPauseMaxMs}} ms max, peak heap {{.PeakHeapBytes}} bytes</td></tr>
+{{- end}}
+</table>
+<h2>Benchmark Statistics</h2>
+<table>
+<tr><th>Operation</th><th>Count</th><th>Min (ms)</th><th>Max (ms)</th><th>Avg (ms)</th><th>Median (ms)</th><th>P95 (ms)</th><th>P99 (ms)</th></tr>
+{{- range .Operations}}
+<tr><td>{{.Operation}}</td><td class="num">{{.Count}}</td><td class="num">{{printf "%.1f" .MinMs}}</td><td class="num">{{printf "%.1f" .MaxMs}}</td><td class="num">{{printf "%.1f" .AvgMs}}</td><td class="num">{{printf "%.1f" .MedianMs}}</td><td class="num">{{printf "%.1f" .P95Ms}}</td><td class="num">{{printf "%.1f" .P99Ms}}</td></tr>
+{{- end}}
+</table>
+{{- if .Charts}}
+<h2>Latency Over Time</h2>
+<p>Latency of every successful iteration on a shared wall-clock axis from the first to the last sample.</p>
+<table>
+<tr><th>Operation</th><th>Max (ms)</th><th>Latency</th></tr>
+{{- range .Charts}}
+<tr><td>{{.Operation}}</td><td class="num">{{printf "%.1f" .MaxMs}}</td><td><svg class="timeline" width="600" height="80" viewBox="0 0 600 80"><polyline fill="none" stroke="#3366cc" points="{{.Points}}"/></svg></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Events}}
+<h2>Events</h2>
+<table>
+<tr><th>Time</th><th>Event</th></tr>
+{{- range .Events}}
+<tr><td>{{.Timestamp.Format "15:04:05"}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .RampChart}}
+<h2>Latency vs Concurrency</h2>
+<p>Median (light) and p95 (dark) latency up to {{printf "%.1f" .MaxMs}} ms, and throughput (green) up to {{printf "%.1f" .MaxRate}} requests/s, per concurrency level.</p>
+<svg class="timeline" width="600" height="160" viewBox="0 0 600 160">
+<polyline fill="none" stroke="#99b3e6" points="{{.P50}}"/>
+<polyline fill="none" stroke="#3366cc" points="{{.P95}}"/>
+<polyline fill="none" stroke="#33a02c" points="{{.Throughput}}"/>
+</svg>
+<table>
+<tr><th>Concurrency</th><th>Requests</th><th>Errors</th><th>P50 (ms)</th><th>P95 (ms)</th><th>Throughput (req/s)</th></tr>
+{{- range $.Ramp}}
+<tr><td class="num">{{.Concurrency}}</td><td class="num">{{.Requests}}</td><td class="num">{{.Errors}}</td><td class="num">{{printf "%.1f" .P50Ms}}</td><td class="num">{{printf "%.1f" .P95Ms}}</td><td class="num">{{printf "%.1f" .Throughput}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Incomplete}}
+<h2>Incomplete</h2>
+<p>Cut short because the run time budget was exhausted.</p>
+<table>
+<tr><th>Operation</th><th>Skipped</th></tr>
+{{- range .Incomplete}}
+<tr><td>{{.Operation}}</td><td>{{if .NotStarted}}not started{{else}}{{.SkippedIterations}} iterations{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Errors}}
+<h2>Errors</h2>
+<table>
+<tr><th>Operation</th><th>Class</th><th>Count</th><th>First</th><th>Last</th><th>Message</th></tr>
+{{- range .Errors}}
+<tr><td>{{.Operation}}</td><td>{{.Class}}</td><td class="num">{{.Count}}</td><td>{{.FirstOccurrence.Format "15:04:05"}}</td><td>{{.LastOccurrence.Format "15:04:05"}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlReport is the data rendered into the HTML report
+type htmlReport struct {
+	Summary
+	Charts    []timelineChart
+	RampChart *rampChart
+}
+
+// writeHTMLReport renders the summary and latency timelines as a standalone HTML page
+func writeHTMLReport(path string, summary Summary, charts []timelineChart) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating HTML report: %v", err)
+	}
+	defer file.Close()
+
+	if err := htmlReportTemplate.Execute(file, htmlReport{Summary: summary, Charts: charts, RampChart: newRampChart(summary.Ramp)}); err != nil {
+		return fmt.Errorf("error rendering HTML report: %v", err)
+	}
+	return nil
+}
+
+// labelsFlag is a repeatable key=value command-line flag
+type labelsFlag map[string]string
+
+func (l labelsFlag) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, key := range sortedKeys(l) {
+		pairs = append(pairs, key+"="+l[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (l labelsFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	l[key] = val
+	return nil
+}
+
+// newRunID generates a unique run ID that sorts by start time
+func newRunID(startTime time.Time) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return startTime.Format("20060102-150405")
+	}
+	return fmt.Sprintf("%s-%x", startTime.Format("20060102-150405"), suffix)
+}
+
+// validRunID reports whether a run ID is safe to use as a directory name
+func validRunID(runID string) bool {
+	return runID != "" && runID != "." && runID != ".." && !strings.ContainsAny(runID, `/\`)
+}
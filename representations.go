@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metainternalversionscheme "k8s.io/apimachinery/pkg/apis/meta/internalversion/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// listRepresentation is a form in which the apiserver can return a list
+type listRepresentation struct {
+	// name is used in the operation name, e.g. "list pods (cluster-wide, metadata-only)"
+	name string
+	// accept is the Accept header requesting the representation, empty for full objects
+	accept    string
+	decoder   runtime.Decoder
+	newObject func(lr listableResource) runtime.Object
+}
+
+var (
+	// fullObjectsRepresentation returns the complete objects
+	fullObjectsRepresentation = listRepresentation{
+		name:      "full objects",
+		decoder:   scheme.Codecs.UniversalDeserializer(),
+		newObject: func(lr listableResource) runtime.Object { return lr.newList() },
+	}
+
+	// metadataOnlyRepresentation returns only object metadata, as used by metadata informers
+	metadataOnlyRepresentation = listRepresentation{
+		name:      "metadata-only",
+		accept:    "application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1",
+		decoder:   metainternalversionscheme.Codecs.UniversalDeserializer(),
+		newObject: func(listableResource) runtime.Object { return &metav1.PartialObjectMetadataList{} },
+	}
+
+	// tableRepresentation returns server-side printed rows, as used by kubectl get
+	tableRepresentation = listRepresentation{
+		name:      "table",
+		accept:    "application/json;as=Table;g=meta.k8s.io;v=v1",
+		decoder:   metainternalversionscheme.Codecs.UniversalDeserializer(),
+		newObject: func(listableResource) runtime.Object { return &metav1.Table{} },
+	}
+)
+
+// listAs lists the resource cluster-wide in the given representation
+func listAs(ctx context.Context, clientset *kubernetes.Clientset, resource string, representation listRepresentation, name string, results *BenchmarkResults) error {
+	lr := listableResources[resource]
+	req := lr.client(clientset).Get().
+		Resource(resource).
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec)
+	if representation.accept != "" {
+		req.SetHeader("Accept", representation.accept)
+	}
+	return fetchAndDecode(ctx, req, representation.decoder, representation.newObject(lr), name, results)
+}
+
+// benchmarkListRepresentations lists every resource cluster-wide as full objects and in each of
+// the requested representations, so they can be compared side by side
+func benchmarkListRepresentations(clientset *kubernetes.Clientset, resources map[string][]listRepresentation, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- List representations ---")
+
+	for _, resource := range sortedKeys(resources) {
+		representations := append([]listRepresentation{fullObjectsRepresentation}, resources[resource]...)
+		for _, representation := range representations {
+			name := fmt.Sprintf("list %s (cluster-wide, %s)", resource, representation.name)
+			runBenchmark(name, iterations, func(ctx context.Context) error {
+				return listAs(ctx, clientset, resource, representation, name, results)
+			}, results)
+		}
+	}
+}
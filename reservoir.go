@@ -0,0 +1,22 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// maxSamplesPerOp caps the raw samples and timeline points kept per operation, 0 to keep all
+var maxSamplesPerOp int
+
+// reservoirSlot decides where the seen-th sample of an operation goes when at most
+// maxSamplesPerOp are kept, using reservoir sampling so that the kept samples remain a uniform
+// random sample of all samples. It returns the index of the kept sample to replace, or -1 to
+// append the sample or, once the reservoir is full, len(kept) to drop it.
+func reservoirSlot(seen, kept int) int {
+	if maxSamplesPerOp == 0 || kept < maxSamplesPerOp {
+		return -1
+	}
+	if slot := rand.Intn(seen); slot < kept {
+		return slot
+	}
+	return kept
+}
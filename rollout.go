@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rolloutAnnotation is changed on the pod template to trigger a new rollout
+const rolloutAnnotation = "k8s-api-bench/rollout"
+
+// rolloutTimeout bounds the wait for a rollout, which includes scheduling and starting pods
+const rolloutTimeout = 5 * time.Minute
+
+// rolloutComplete reports whether the Deployment controller has observed the given generation
+// and all replicas are updated and available
+func rolloutComplete(deployment *appsv1.Deployment, generation int64, replicas int32) bool {
+	status := deployment.Status
+	return status.ObservedGeneration >= generation &&
+		status.Replicas == replicas &&
+		status.UpdatedReplicas == replicas &&
+		status.AvailableReplicas == replicas
+}
+
+// waitForRollout waits until the watch reports the rollout of the given generation of the
+// named Deployment as complete and returns the arrival time of that event
+func waitForRollout(watcher watch.Interface, name string, generation int64, replicas int32) (time.Time, error) {
+	timeout := time.After(rolloutTimeout)
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return time.Time{}, fmt.Errorf("watch closed while waiting for rollout of %s", name)
+			}
+			if event.Type == watch.Error {
+				return time.Time{}, fmt.Errorf("watch error: %v", event.Object)
+			}
+			deployment, ok := event.Object.(*appsv1.Deployment)
+			if ok && deployment.Name == name && rolloutComplete(deployment, generation, replicas) {
+				return time.Now(), nil
+			}
+		case <-timeout:
+			return time.Time{}, fmt.Errorf("timed out after %v waiting for rollout of %s", rolloutTimeout, name)
+		}
+	}
+}
+
+// benchmarkRollout seeds a Deployment with the given number of replicas and measures the time
+// until its initial rollout and each subsequent rollout, triggered by patching the pod
+// template, report complete. This covers the Deployment and ReplicaSet controllers as well as
+// scheduling and starting the pods.
+func benchmarkRollout(clientset *kubernetes.Clientset, namespace string, replicas int32, iterations int, results *BenchmarkResults) {
+	const seedSet = "rollout"
+	createName := fmt.Sprintf("Deployment rollout (create, %d replicas)", replicas)
+	updateName := fmt.Sprintf("Deployment rollout (update, %d replicas)", replicas)
+
+	fmt.Printf("\n--- Deployment rollout benchmark in namespace %s ---\n", namespace)
+	deployments := clientset.AppsV1().Deployments(namespace)
+	defer func() {
+		if err := deleteSeededDeployments(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded Deployments: %v\n", err)
+		}
+	}()
+
+	list, err := deployments.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+	if err != nil {
+		fmt.Printf("Error listing Deployments: %v\n", err)
+		return
+	}
+	watcher, err := deployments.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:   seedSelector(seedSet),
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer watcher.Stop()
+
+	startTime := time.Now()
+	deployment, err := seedDeployment(clientset, namespace, seedSet, replicas)
+	if err != nil {
+		recordIteration(createName, 1, 1, startTime, 0, err, results)
+		return
+	}
+	completeTime, err := waitForRollout(watcher, deployment.Name, deployment.Generation, replicas)
+	recordIteration(createName, 1, 1, startTime, completeTime.Sub(startTime), err, results)
+	if err != nil {
+		return
+	}
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(updateName) {
+			continue
+		}
+		patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:"%d"}}}}}`, rolloutAnnotation, i))
+		startTime := time.Now()
+		patched, err := deployments.Patch(context.TODO(), deployment.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			recordIteration(updateName, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(updateName, err)
+			continue
+		}
+		completeTime, err := waitForRollout(watcher, deployment.Name, patched.Generation, replicas)
+		recordIteration(updateName, i+1, iterations, startTime, completeTime.Sub(startTime), err, results)
+		results.recordOutcome(updateName, err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// SaturationAnalysis models the concurrency ramp with the Universal Scalability Law,
+// X(N) = λN / (1 + σ(N-1) + κN(N-1)), where σ is the contention and κ the coherency penalty
+type SaturationAnalysis struct {
+	// KneeConcurrency is the last level before the median latency grew at least as fast as the
+	// concurrency, which by Little's law (N = X·R) is where throughput stopped increasing
+	KneeConcurrency int     `json:"knee_concurrency"`
+	Contention      float64 `json:"contention"`
+	Coherency       float64 `json:"coherency"`
+	// PeakConcurrency is the concurrency with the highest modeled throughput, 0 if the model
+	// predicts no peak because coherency is negligible
+	PeakConcurrency float64 `json:"peak_concurrency,omitempty"`
+	PeakThroughput  float64 `json:"peak_throughput,omitempty"`
+	// ClientQPS is the client-side rate limit the ramp ran under, 0 without one
+	ClientQPS float64 `json:"client_qps,omitempty"`
+}
+
+// rateLimitedShare is the share of the client-side rate limit from which the ramp's throughput
+// is considered capped by the limiter rather than by the apiserver
+const rateLimitedShare = 0.9
+
+// rateLimited reports whether the highest throughput of the ramp came close to the client-side
+// rate limit, and returns that throughput
+func (a *SaturationAnalysis) rateLimited(points []RampPoint) (float64, bool) {
+	var highest float64
+	for _, point := range points {
+		highest = max(highest, point.Throughput)
+	}
+	return highest, a.ClientQPS > 0 && highest >= rateLimitedShare*a.ClientQPS
+}
+
+// rampKnee returns the last concurrency level before the one at which the median latency grew
+// at least as fast as the concurrency, or the highest level if that never happened
+func rampKnee(points []RampPoint) int {
+	for i := 1; i < len(points); i++ {
+		previous, current := points[i-1], points[i]
+		if previous.P50Ms <= 0 {
+			continue
+		}
+		concurrencyGrowth := float64(current.Concurrency) / float64(previous.Concurrency)
+		if current.P50Ms/previous.P50Ms >= concurrencyGrowth {
+			return previous.Concurrency
+		}
+	}
+	return points[len(points)-1].Concurrency
+}
+
+// analyzeSaturation fits the Universal Scalability Law to the ramp by least squares, taking the
+// throughput per worker at the lowest level as the uncontended throughput λ. It needs at least
+// three levels with throughput.
+func analyzeSaturation(points []RampPoint) (*SaturationAnalysis, bool) {
+	var usable []RampPoint
+	for _, point := range points {
+		if point.Throughput > 0 {
+			usable = append(usable, point)
+		}
+	}
+	if len(usable) < 3 {
+		return nil, false
+	}
+	lowest := usable[0]
+	for _, point := range usable {
+		if point.Concurrency < lowest.Concurrency {
+			lowest = point
+		}
+	}
+	lambda := lowest.Throughput / float64(lowest.Concurrency)
+
+	// With the relative capacity C = X/λ, N/C - 1 = σ(N-1) + κN(N-1) is linear in σ and κ
+	var saa, sab, sbb, say, sby float64
+	for _, point := range usable {
+		n := float64(point.Concurrency)
+		a, b := n-1, n*(n-1)
+		y := n*lambda/point.Throughput - 1
+		saa += a * a
+		sab += a * b
+		sbb += b * b
+		say += a * y
+		sby += b * y
+	}
+	det := saa*sbb - sab*sab
+	if det == 0 {
+		return nil, false
+	}
+	sigma := (say*sbb - sby*sab) / det
+	kappa := (saa*sby - sab*say) / det
+	if kappa < 0 {
+		kappa = 0
+		sigma = say / saa
+	}
+	if sigma < 0 {
+		sigma = 0
+		kappa = max(sby/sbb, 0)
+	}
+
+	analysis := &SaturationAnalysis{
+		KneeConcurrency: rampKnee(usable),
+		Contention:      sigma,
+		Coherency:       kappa,
+	}
+	if kappa > 0 && sigma < 1 {
+		n := math.Sqrt((1 - sigma) / kappa)
+		analysis.PeakConcurrency = n
+		analysis.PeakThroughput = lambda * n / (1 + sigma*(n-1) + kappa*n*(n-1))
+	}
+	return analysis, true
+}
+
+// PrintSaturation prints the saturation analysis of the concurrency ramp, if there is one
+func (br *BenchmarkResults) PrintSaturation() {
+	if br.Saturation == nil {
+		return
+	}
+
+	fmt.Println("\n--- Saturation Analysis ---")
+	fmt.Printf("Knee: concurrency %d (beyond it, latency grows at least as fast as concurrency)\n", br.Saturation.KneeConcurrency)
+	fmt.Printf("Universal Scalability Law fit: contention σ=%.4f, coherency κ=%.6f\n", br.Saturation.Contention, br.Saturation.Coherency)
+	if br.Saturation.PeakConcurrency > 0 {
+		fmt.Printf("Estimated maximum useful concurrency: %.0f (%.1f requests/s)\n", br.Saturation.PeakConcurrency, br.Saturation.PeakThroughput)
+	} else {
+		fmt.Println("Estimated maximum useful concurrency: no throughput peak predicted, ramp to higher levels")
+	}
+	if highest, limited := br.Saturation.rateLimited(br.Ramp); limited {
+		fmt.Printf("Warning: the ramp reached %.1f requests/s, close to the client-side rate limit of %.1f set by --qps; the fit reflects the limit rather than the apiserver, raise --qps or leave it unset\n", highest, br.Saturation.ClientQPS)
+	}
+}
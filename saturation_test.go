@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestSaturationRateLimited(t *testing.T) {
+	points := []RampPoint{{Concurrency: 1, Throughput: 40}, {Concurrency: 4, Throughput: 95}, {Concurrency: 16, Throughput: 92}}
+
+	tests := []struct {
+		name      string
+		clientQPS float64
+		want      bool
+	}{
+		{name: "without limit", clientQPS: 0, want: false},
+		{name: "far below the limit", clientQPS: 500, want: false},
+		{name: "close to the limit", clientQPS: 100, want: true},
+		{name: "at the limit", clientQPS: 95, want: true},
+	}
+	for _, tt := range tests {
+		analysis := &SaturationAnalysis{ClientQPS: tt.clientQPS}
+		highest, limited := analysis.rateLimited(points)
+		if highest != 95 || limited != tt.want {
+			t.Errorf("%s: rateLimited() = %v, %v, want 95, %v", tt.name, highest, limited, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// benchmarkScaleSubresource measures GET and PATCH of the /scale subresource of a seeded
+// Deployment, the path HPA and KEDA use constantly. The Deployment has no replicas and the patch
+// keeps it that way, so no pods are created.
+func benchmarkScaleSubresource(clientset *kubernetes.Clientset, namespace string, iterations int, results *BenchmarkResults) {
+	const seedSet = "scale"
+
+	fmt.Printf("\n--- Scale subresource benchmark in namespace %s ---\n", namespace)
+	deployments := clientset.AppsV1().Deployments(namespace)
+	defer func() {
+		if err := deleteSeededDeployments(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded Deployments: %v\n", err)
+		}
+	}()
+
+	deployment, err := seedDeployment(clientset, namespace, seedSet, 0)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	runBenchmark("get Deployment scale", iterations, func(ctx context.Context) error {
+		_, err := deployments.GetScale(ctx, deployment.Name, metav1.GetOptions{})
+		return err
+	}, results)
+
+	patch := []byte(`{"spec":{"replicas":0}}`)
+	runBenchmark("patch Deployment scale", iterations, func(ctx context.Context) error {
+		_, err := deployments.Patch(ctx, deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "scale")
+		return err
+	}, results)
+}
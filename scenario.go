@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// selectedOperations restricts the core suite to the named catalogue operations, nil runs all
+var selectedOperations map[string]bool
+
+// operationSelected reports whether the catalogue operation is part of the run
+func operationSelected(name string) bool {
+	return selectedOperations == nil || selectedOperations[name]
+}
+
+// parseOperations parses a comma-separated list of catalogue operation names and "tag:<tag>"
+// entries selecting all operations with the tag
+func parseOperations(value string) (map[string]bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	catalog := operationCatalog()
+	selected := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		found := false
+		tag, isTag := strings.CutPrefix(entry, "tag:")
+		for _, op := range catalog {
+			if (isTag && op.hasTag(tag)) || (!isTag && op.name == entry) {
+				selected[op.name] = true
+				found = true
+			}
+		}
+		if !found && isTag {
+			return nil, fmt.Errorf("unknown tag %q (see \"k8s-api-bench list-ops\")", tag)
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown operation %q (see \"k8s-api-bench list-ops\")", entry)
+		}
+	}
+	return selected, nil
+}
+
+// loadScenario reads a scenario file, a YAML map of command-line flag names to their values
+func loadScenario(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// scenarioValues converts a scenario value to the flag values to set. Lists are joined with
+// commas, except for repeatable flags, which are set once per element, as are the key=value
+// pairs of maps.
+func scenarioValues(value interface{}, repeatable bool) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []interface{}:
+		var values []string
+		for _, element := range v {
+			elementValues, err := scenarioValues(element, false)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, elementValues...)
+		}
+		if !repeatable {
+			return []string{strings.Join(values, ",")}, nil
+		}
+		return values, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var pairs []string
+		for _, key := range keys {
+			elementValues, err := scenarioValues(v[key], false)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, key+"="+strings.Join(elementValues, ","))
+		}
+		if !repeatable {
+			return []string{strings.Join(pairs, ",")}, nil
+		}
+		return pairs, nil
+	case nil:
+		return nil, fmt.Errorf("missing value")
+	default:
+		return nil, fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// applyScenario sets the flags from the scenario settings, leaving flags that were given on the
+// command line untouched, and returns all problems found
+func applyScenario(fs *flag.FlagSet, settings map[string]interface{}) []error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	var problems []error
+	for _, name := range sortedKeys(settings) {
+		f := fs.Lookup(name)
+		if f == nil {
+			problems = append(problems, fmt.Errorf("unknown setting %q", name))
+			continue
+		}
+		if name == "scenario" {
+			problems = append(problems, fmt.Errorf("scenarios cannot include other scenarios"))
+			continue
+		}
+		if explicit[name] {
+			continue
+		}
+
+		_, repeatable := f.Value.(labelsFlag)
+		if _, ok := f.Value.(headersFlag); ok {
+			repeatable = true
+		}
+		values, err := scenarioValues(settings[name], repeatable)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid value for %q: %v", name, err))
+			continue
+		}
+		for _, value := range values {
+			if err := fs.Set(name, value); err != nil {
+				problems = append(problems, fmt.Errorf("invalid value for %q: %v", name, err))
+			}
+		}
+	}
+	return problems
+}
+
+// validateOnly stops the benchmark after its settings have been validated, without touching the
+// cluster. It is set by the validate subcommand.
+var validateOnly bool
+
+// validateArgs translates the arguments of the validate subcommand into the benchmark arguments
+// loading the scenario file to validate
+func validateArgs(args []string) []string {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	file := fs.String("f", "", "Scenario file to validate")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate -f <scenario.yaml>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *file == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	return []string{os.Args[0], "--scenario=" + *file}
+}
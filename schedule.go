@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression (minute, hour, day of month, month, day
+// of week), each field holding the allowed values as a bit set
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// Day of month and day of week match either, as in cron, when both are restricted
+	domRestricted, dowRestricted bool
+}
+
+// cronField describes the value range of a cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// parseCronField parses a comma-separated list of "*", values, "a-b" ranges and "/step" steps
+func parseCronField(expr string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, field.name)
+			}
+		}
+
+		low, high := field.min, field.max
+		if rangeExpr != "*" {
+			lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			low, err = strconv.Atoi(lowExpr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", lowExpr, field.name)
+			}
+			high = low
+			if isRange {
+				high, err = strconv.Atoi(highExpr)
+				if err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", highExpr, field.name)
+				}
+			} else if hasStep {
+				high = field.max
+			}
+		}
+		if low < field.min || high > field.max || low > high {
+			return 0, fmt.Errorf("%s field out of range %d-%d: %q", field.name, field.min, field.max, part)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// parseCronSchedule parses a standard five-field cron expression such as "0 */6 * * *"
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	var values [5]uint64
+	for i, field := range cronFields {
+		bits, err := parseCronField(fields[i], field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = bits
+	}
+
+	schedule := &cronSchedule{
+		minute:        values[0],
+		hour:          values[1],
+		dom:           values[2],
+		month:         values[3],
+		dow:           values[4],
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+	// Sunday is both 0 and 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	return schedule, nil
+}
+
+// matchesDay reports whether the day of t is scheduled
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Next returns the first scheduled time after t, or the zero time if there is none within
+// the next five years (e.g. for "0 0 31 2 *")
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		switch {
+		case s.month&(1<<int(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case s.hour&(1<<next.Hour()) == 0:
+			// Truncate works in absolute time, which is off the local hour in half-hour offset zones
+			hour := time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			if !hour.After(next) {
+				// The next hour does not exist when the clocks spring forward
+				later := next.Add(time.Hour)
+				hour = time.Date(later.Year(), later.Month(), later.Day(), later.Hour(), 0, 0, 0, later.Location())
+			}
+			next = hour
+		case s.minute&(1<<next.Minute()) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// argsWithout removes a flag and its value from the command-line arguments
+func argsWithout(args []string, name string) []string {
+	var filtered []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		trimmed := strings.TrimLeft(arg, "-")
+		if arg == "--" {
+			return append(filtered, args[i:]...)
+		}
+		if strings.HasPrefix(arg, "-") && trimmed == name {
+			// The value is the next argument, unless the flag is boolean
+			if !isBoolFlag(name) {
+				i++
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "-") && strings.HasPrefix(trimmed, name+"=") {
+			continue
+		}
+		filtered = append(filtered, arg)
+	}
+	return filtered
+}
+
+// isBoolFlag reports whether the named command-line flag is boolean, so that it takes no value
+// as the next argument
+func isBoolFlag(name string) bool {
+	f := flag.CommandLine.Lookup(name)
+	if f == nil {
+		return false
+	}
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// withoutSchedulerFlags removes the flags that only apply to the scheduler from the arguments
+// passed to the runs
+func withoutSchedulerFlags(args []string) []string {
+	for _, name := range []string{"schedule", "control-addr", "summary-output"} {
+		args = argsWithout(args, name)
+	}
+	return args
+}
+
+// scheduler executes the benchmark suite on a cron schedule and on demand, running each run as
+// a child process with the active scenario arguments, so every run exports its results through
+// the configured sinks and starts from a clean state
+type scheduler struct {
+	schedule   *cronSchedule
+	executable string
+
+	// summaryPath is where child runs write their summary, to be served as the latest results
+	summaryPath string
+
+	// token is the bearer token the control API requires, empty for none
+	token string
+
+	// trigger queues an ad-hoc run, holding at most one pending run
+	trigger chan struct{}
+
+	// args are the benchmark arguments of the command line, which every run keeps
+	args []string
+
+	mu sync.Mutex
+	// overlay are the arguments set through the control API, overriding the flags of args with
+	// the same name
+	overlay []string
+	running bool
+	nextRun time.Time
+	lastRun time.Time
+	lastErr string
+	latest  []byte
+}
+
+// newScheduler creates a scheduler running the benchmark with args, minus the scheduler's own flags
+func newScheduler(schedule *cronSchedule, args []string) (*scheduler, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error locating executable: %v", err)
+	}
+	summaryFile, err := os.CreateTemp("", "k8s-api-bench-summary-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("error creating summary file: %v", err)
+	}
+	summaryFile.Close()
+
+	return &scheduler{
+		schedule:    schedule,
+		executable:  executable,
+		summaryPath: summaryFile.Name(),
+		trigger:     make(chan struct{}, 1),
+		args:        withoutSchedulerFlags(args),
+	}, nil
+}
+
+// Run executes scheduled and triggered runs until the context is cancelled
+func (s *scheduler) Run(ctx context.Context) error {
+	defer os.Remove(s.summaryPath)
+
+	for {
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("the schedule never fires")
+		}
+		s.mu.Lock()
+		s.nextRun = next
+		s.mu.Unlock()
+		fmt.Printf("Next scheduled run at %s\n", next.Format(time.RFC3339))
+
+		select {
+		case <-time.After(time.Until(next)):
+		case <-s.trigger:
+			fmt.Println("Starting triggered run")
+		case <-ctx.Done():
+			fmt.Println("Stopping scheduler")
+			return nil
+		}
+		s.runOnce(ctx)
+	}
+}
+
+// runOnce executes a single benchmark run and keeps its summary as the latest results
+func (s *scheduler) runOnce(ctx context.Context) {
+	s.mu.Lock()
+	args := s.runArgs()
+	s.running = true
+	s.lastRun = time.Now()
+	s.mu.Unlock()
+
+	os.Remove(s.summaryPath)
+	cmd := exec.CommandContext(ctx, s.executable, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		fmt.Printf("Scheduled run failed: %v\n", err)
+	}
+	summary, readErr := os.ReadFile(s.summaryPath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.lastErr = ""
+	if err != nil {
+		s.lastErr = err.Error()
+	}
+	if readErr == nil {
+		s.latest = summary
+	}
+}
+
+// Args returns the active scenario arguments: the command-line arguments with the flags of the
+// overlay replaced by the overlay. The caller must hold s.mu.
+func (s *scheduler) Args() []string {
+	args := append([]string(nil), s.args...)
+	for _, arg := range s.overlay {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		args = argsWithout(args, name)
+	}
+	return append(args, s.overlay...)
+}
+
+// runArgs returns the arguments of the next run, the caller must hold s.mu
+func (s *scheduler) runArgs() []string {
+	// Runs can outlive the credentials they started with, so they pick up rotated ones
+	return append(s.Args(), "--summary-output="+s.summaryPath, "--reload-credentials")
+}
+
+// runSchedule executes the benchmark suite on the cron schedule until interrupted, serving the
+// control API on controlAddr if set, and returns the exit status
+func runSchedule(schedule *cronSchedule, args []string, controlAddr string) int {
+	s, err := newScheduler(schedule, args)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	s.token = os.Getenv(controlTokenEnv)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if controlAddr != "" {
+		go func() {
+			if err := s.ServeControlAPI(ctx, controlAddr); err != nil {
+				fmt.Printf("Error serving control API: %v\n", err)
+				stop()
+			}
+		}()
+	}
+
+	if err := s.Run(ctx); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "0 */6 * * *"},
+		{expr: "*/15 0-5,22 1 1-6/2 7"},
+		{expr: "30 9 * * 1-5"},
+		{expr: "0 * * *", wantErr: true},
+		{expr: "0 * * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "0 24 * * *", wantErr: true},
+		{expr: "0 0 0 * *", wantErr: true},
+		{expr: "0 0 * 13 *", wantErr: true},
+		{expr: "0 0 * * 8", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "a * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := parseCronSchedule(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCronSchedule(%q) error = %v, want error %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	load := func(name string) *time.Location {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatalf("loading location %s: %v", name, err)
+		}
+		return location
+	}
+	utc := time.UTC
+	kolkata := load("Asia/Kolkata")
+	adelaide := load("Australia/Adelaide")
+	newYork := load("America/New_York")
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "every six hours",
+			expr: "0 */6 * * *",
+			from: time.Date(2024, 1, 1, 1, 30, 0, 0, utc),
+			want: time.Date(2024, 1, 1, 6, 0, 0, 0, utc),
+		},
+		{
+			name: "strictly after the given time",
+			expr: "0 6 * * *",
+			from: time.Date(2024, 1, 1, 6, 0, 0, 0, utc),
+			want: time.Date(2024, 1, 2, 6, 0, 0, 0, utc),
+		},
+		{
+			name: "half-hour offset zone",
+			expr: "0 9 * * *",
+			from: time.Date(2024, 1, 1, 10, 0, 0, 0, kolkata),
+			want: time.Date(2024, 1, 2, 9, 0, 0, 0, kolkata),
+		},
+		{
+			name: "half-hour offset zone with daylight saving time",
+			expr: "15 2 * * *",
+			from: time.Date(2024, 1, 1, 23, 45, 0, 0, adelaide),
+			want: time.Date(2024, 1, 2, 2, 15, 0, 0, adelaide),
+		},
+		{
+			name: "hour after the spring forward gap",
+			expr: "0 3 * * *",
+			from: time.Date(2024, 3, 10, 0, 0, 0, 0, newYork),
+			want: time.Date(2024, 3, 10, 3, 0, 0, 0, newYork),
+		},
+		{
+			name: "first of the repeated hours when clocks fall back",
+			expr: "30 1 * * *",
+			from: time.Date(2024, 11, 3, 0, 0, 0, 0, newYork),
+			want: time.Date(2024, 11, 3, 5, 30, 0, 0, utc),
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 13 * 5",
+			from: time.Date(2024, 9, 1, 0, 0, 0, 0, utc),
+			want: time.Date(2024, 9, 6, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 12 * * 7",
+			from: time.Date(2024, 9, 2, 0, 0, 0, 0, utc),
+			want: time.Date(2024, 9, 8, 12, 0, 0, 0, utc),
+		},
+		{
+			name: "next month",
+			expr: "0 0 1 * *",
+			from: time.Date(2024, 2, 15, 8, 0, 0, 0, utc),
+			want: time.Date(2024, 3, 1, 0, 0, 0, 0, utc),
+		},
+		{
+			name: "never",
+			expr: "0 0 31 2 *",
+			from: time.Date(2024, 1, 1, 0, 0, 0, 0, utc),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCronSchedule(%q): %v", tt.expr, err)
+			}
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgsWithout(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		flag string
+		want []string
+	}{
+		{
+			name: "value as next argument",
+			args: []string{"--iterations=5", "--schedule", "0 * * * *", "--qps=10"},
+			flag: "schedule",
+			want: []string{"--iterations=5", "--qps=10"},
+		},
+		{
+			name: "value after equals sign",
+			args: []string{"--schedule=0 * * * *", "--iterations=5"},
+			flag: "schedule",
+			want: []string{"--iterations=5"},
+		},
+		{
+			name: "single dash",
+			args: []string{"-schedule", "0 * * * *", "-iterations=5"},
+			flag: "schedule",
+			want: []string{"-iterations=5"},
+		},
+		{
+			name: "flag sharing the prefix",
+			args: []string{"--summary-output-dir=out", "--summary-output=summary.json"},
+			flag: "summary-output",
+			want: []string{"--summary-output-dir=out"},
+		},
+		{
+			name: "last argument without value",
+			args: []string{"--iterations=5", "--schedule"},
+			flag: "schedule",
+			want: []string{"--iterations=5"},
+		},
+		{
+			name: "arguments after the terminator",
+			args: []string{"--schedule=x", "--", "--schedule=y"},
+			flag: "schedule",
+			want: []string{"--", "--schedule=y"},
+		},
+		{
+			name: "absent",
+			args: []string{"--iterations=5"},
+			flag: "schedule",
+			want: []string{"--iterations=5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := argsWithout(tt.args, tt.flag); !slices.Equal(got, tt.want) {
+				t.Errorf("argsWithout(%q, %q) = %q, want %q", tt.args, tt.flag, got, tt.want)
+			}
+		})
+	}
+}
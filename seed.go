@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// createdByLabel marks every object created by the tool, so leftovers can be found and removed
+	createdByLabel = "app.kubernetes.io/created-by"
+	createdByValue = "k8s-api-bench"
+
+	// seedSetLabel groups the objects seeded together for one benchmark
+	seedSetLabel = "k8s-api-bench/seed-set"
+)
+
+// seedLabels returns the labels put on objects of the given seed set
+func seedLabels(seedSet string) map[string]string {
+	return map[string]string{
+		createdByLabel: createdByValue,
+		seedSetLabel:   seedSet,
+	}
+}
+
+// seedSelector returns the label selector matching all objects of the given seed set
+func seedSelector(seedSet string) string {
+	return fmt.Sprintf("%s=%s,%s=%s", createdByLabel, createdByValue, seedSetLabel, seedSet)
+}
+
+// seedConfigMaps creates count ConfigMaps of the given seed set in the namespace, each holding
+// size bytes of data, and returns their names
+func seedConfigMaps(clientset *kubernetes.Clientset, namespace, seedSet string, count, size int) ([]string, error) {
+	data := strings.Repeat("x", size)
+
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+				Labels: seedLabels(seedSet),
+			},
+			Data: map[string]string{"payload": data},
+		}
+		created, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), configMap, metav1.CreateOptions{})
+		if err != nil {
+			return names, fmt.Errorf("error seeding ConfigMap: %v", err)
+		}
+		names = append(names, created.Name)
+	}
+	return names, nil
+}
+
+// deleteSeededConfigMaps removes all ConfigMaps of the given seed set from the namespace
+func deleteSeededConfigMaps(clientset *kubernetes.Clientset, namespace, seedSet string) error {
+	return clientset.CoreV1().ConfigMaps(namespace).DeleteCollection(context.TODO(),
+		metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+}
+
+// seedImage is the container image used by seeded workloads
+const seedImage = "registry.k8s.io/pause:3.10"
+
+// seedDeployment creates a Deployment of the given seed set in the namespace running the pause
+// image with the given number of replicas
+func seedDeployment(clientset *kubernetes.Clientset, namespace, seedSet string, replicas int32) (*appsv1.Deployment, error) {
+	podLabels := seedLabels(seedSet)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "k8s-api-bench-" + seedSet,
+			Labels: seedLabels(seedSet),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "pause", Image: seedImage}},
+				},
+			},
+		},
+	}
+
+	created, err := clientset.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error seeding Deployment: %v", err)
+	}
+	return created, nil
+}
+
+// deleteSeededDeployments removes all Deployments of the given seed set from the namespace
+func deleteSeededDeployments(clientset *kubernetes.Clientset, namespace, seedSet string) error {
+	return clientset.AppsV1().Deployments(namespace).DeleteCollection(context.TODO(),
+		metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+}
+
+// ephemeralNamespaceAnnotation records the run that created an ephemeral benchmark namespace
+const ephemeralNamespaceAnnotation = "k8s-api-bench/run-id"
+
+// createEphemeralNamespace creates a uniquely named namespace for the objects written by the
+// benchmarks of a run, isolating them from tenant namespaces
+func createEphemeralNamespace(clientset *kubernetes.Clientset, runID string) (string, error) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "k8s-api-bench-",
+			Labels:       map[string]string{createdByLabel: createdByValue},
+			Annotations:  map[string]string{ephemeralNamespaceAnnotation: runID},
+		},
+	}
+	created, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error creating benchmark namespace: %v", err)
+	}
+	return created.Name, nil
+}
+
+// deleteEphemeralNamespace deletes a namespace created by createEphemeralNamespace together with
+// everything in it
+func deleteEphemeralNamespace(clientset *kubernetes.Clientset, namespace string) error {
+	return clientset.CoreV1().Namespaces().Delete(context.TODO(), namespace, metav1.DeleteOptions{})
+}
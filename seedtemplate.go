@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// seedTemplateData is passed to seed manifest templates for every rendered copy
+type seedTemplateData struct {
+	// Index of the copy, from 0 to count-1
+	Index int
+
+	// Name is a unique default object name, used if the manifest does not set one
+	Name string
+
+	// Namespace the objects are seeded into
+	Namespace string
+
+	// SeedSet is the value of the seed set label put on every object
+	SeedSet string
+
+	// Payload is a string of the configured seed size, e.g. for ConfigMap data or annotations
+	Payload string
+}
+
+// seededObject identifies an object created from a seed template
+type seededObject struct {
+	resource  schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// renderSeedObjects renders the manifest template count times and decodes the resulting
+// (possibly multi-document) YAML into objects
+func renderSeedObjects(manifest, namespace, seedSet string, count, size int) ([]*unstructured.Unstructured, error) {
+	tmpl, err := template.New("seed").Option("missingkey=error").Parse(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing seed template: %v", err)
+	}
+
+	payload := strings.Repeat("x", size)
+	var objects []*unstructured.Unstructured
+	for i := 0; i < count; i++ {
+		data := seedTemplateData{
+			Index:     i,
+			Name:      fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+			Namespace: namespace,
+			SeedSet:   seedSet,
+			Payload:   payload,
+		}
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("error rendering seed template: %v", err)
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(&rendered, 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("error decoding rendered seed manifest: %v", err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			if obj.GetName() == "" {
+				obj.SetName(data.Name)
+			}
+			labels := obj.GetLabels()
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			for key, value := range seedLabels(seedSet) {
+				labels[key] = value
+			}
+			obj.SetLabels(labels)
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// seedFromTemplate creates count copies of the objects in the Go-templated manifest file in the
+// namespace (for namespaced kinds) and returns the created objects for deletion. Any kind
+// served by the cluster, including custom resources, can be seeded.
+func seedFromTemplate(config *rest.Config, path, namespace, seedSet string, count, size int) ([]seededObject, error) {
+	manifest, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading seed template: %v", err)
+	}
+	objects, err := renderSeedObjects(string(manifest), namespace, seedSet, count, size)
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating discovery client: %v", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %v", err)
+	}
+
+	var seeded []seededObject
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return seeded, fmt.Errorf("error resolving resource of %s: %v", gvk, err)
+		}
+
+		var client dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+		objNamespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			objNamespace = obj.GetNamespace()
+			if objNamespace == "" {
+				objNamespace = namespace
+			}
+			obj.SetNamespace(objNamespace)
+			client = dynamicClient.Resource(mapping.Resource).Namespace(objNamespace)
+		}
+
+		created, err := client.Create(context.TODO(), obj, metav1.CreateOptions{})
+		if err != nil {
+			return seeded, fmt.Errorf("error seeding %s %s: %v", gvk.Kind, obj.GetName(), err)
+		}
+		seeded = append(seeded, seededObject{resource: mapping.Resource, namespace: objNamespace, name: created.GetName()})
+	}
+	return seeded, nil
+}
+
+// deleteSeededObjects removes the objects created from a seed template
+func deleteSeededObjects(config *rest.Config, seeded []seededObject) error {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("error creating dynamic client: %v", err)
+	}
+
+	var errs []error
+	for _, obj := range seeded {
+		err := dynamicClient.Resource(obj.resource).Namespace(obj.namespace).Delete(context.TODO(), obj.name, metav1.DeleteOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error deleting %s %s: %v", obj.resource.Resource, obj.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// auditSampler remembers the audit ID and client-side duration of a random sample of requests,
+// to be matched with the apiserver audit log after the run
+type auditSampler struct {
+	rate float64
+
+	mu      sync.Mutex
+	samples map[string]time.Duration
+}
+
+// sampleAuditIDs installs an audit sampler on config that samples the given fraction of requests
+func sampleAuditIDs(config *rest.Config, rate float64) *auditSampler {
+	sampler := &auditSampler{rate: rate, samples: make(map[string]time.Duration)}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &auditTransport{next: rt, sampler: sampler}
+	})
+	return sampler
+}
+
+// auditTransport records the Audit-Id response header of sampled requests with the time until
+// their response body was closed, which is when the apiserver considers the request complete
+type auditTransport struct {
+	next    http.RoundTripper
+	sampler *auditSampler
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	startTime := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || req.URL.Query().Get("watch") == "true" || rand.Float64() >= t.sampler.rate {
+		return resp, err
+	}
+	auditID := resp.Header.Get("Audit-Id")
+	if auditID == "" {
+		return resp, nil
+	}
+	resp.Body = &auditedBody{ReadCloser: resp.Body, onClose: func() {
+		t.sampler.mu.Lock()
+		defer t.sampler.mu.Unlock()
+		t.sampler.samples[auditID] = time.Since(startTime)
+	}}
+	return resp, nil
+}
+
+// auditedBody calls onClose once the response body is closed
+type auditedBody struct {
+	io.ReadCloser
+	onClose func()
+	once    sync.Once
+}
+
+func (b *auditedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.onClose)
+	return err
+}
+
+// auditEvent holds the fields of an audit.k8s.io/v1 event needed to derive the server-side duration
+type auditEvent struct {
+	AuditID   string `json:"auditID"`
+	Stage     string `json:"stage"`
+	Verb      string `json:"verb"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	RequestReceivedTimestamp metav1.MicroTime `json:"requestReceivedTimestamp"`
+	StageTimestamp           metav1.MicroTime `json:"stageTimestamp"`
+}
+
+// request describes the audited request by verb and resource, e.g. "list pods"
+func (e auditEvent) request() string {
+	if e.ObjectRef == nil || e.ObjectRef.Resource == "" {
+		return e.Verb
+	}
+	resource := e.ObjectRef.Resource
+	if e.ObjectRef.Subresource != "" {
+		resource += "/" + e.ObjectRef.Subresource
+	}
+	return e.Verb + " " + resource
+}
+
+// ServerTiming compares the client-side and server-side durations of the sampled requests of
+// one verb and resource; the gap is the network, load balancer and client overhead
+type ServerTiming struct {
+	Request        string  `json:"request"`
+	Count          int     `json:"count"`
+	ClientMedianMs float64 `json:"client_median_ms"`
+	ServerMedianMs float64 `json:"server_median_ms"`
+	GapMedianMs    float64 `json:"gap_median_ms"`
+	GapP95Ms       float64 `json:"gap_p95_ms"`
+}
+
+// CrossReference matches the sampled requests with their ResponseComplete events in the audit
+// log at path, which must be in the JSON format, and compares the durations per verb and resource
+func (s *auditSampler) CrossReference(path string) ([]ServerTiming, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log: %v", err)
+	}
+	defer file.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type durations struct {
+		client, server, gap []time.Duration
+	}
+	byRequest := make(map[string]*durations)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event auditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Stage != "ResponseComplete" {
+			continue
+		}
+		client, ok := s.samples[event.AuditID]
+		if !ok {
+			continue
+		}
+		server := event.StageTimestamp.Sub(event.RequestReceivedTimestamp.Time)
+
+		request := event.request()
+		if byRequest[request] == nil {
+			byRequest[request] = &durations{}
+		}
+		d := byRequest[request]
+		d.client = append(d.client, client)
+		d.server = append(d.server, server)
+		d.gap = append(d.gap, client-server)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit log: %v", err)
+	}
+
+	var timings []ServerTiming
+	for request, d := range byRequest {
+		gapStats := durationStats(d.gap)
+		timings = append(timings, ServerTiming{
+			Request:        request,
+			Count:          len(d.client),
+			ClientMedianMs: durationMs(durationStats(d.client)["median"]),
+			ServerMedianMs: durationMs(durationStats(d.server)["median"]),
+			GapMedianMs:    durationMs(gapStats["median"]),
+			GapP95Ms:       durationMs(gapStats["p95"]),
+		})
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		return timings[i].Request < timings[j].Request
+	})
+	return timings, nil
+}
+
+// Samples returns the number of sampled requests
+func (s *auditSampler) Samples() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.samples)
+}
+
+// PrintServerTimings prints the client-vs-server latency comparison, if any requests were matched
+func (br *BenchmarkResults) PrintServerTimings() {
+	if len(br.ServerTimings) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Client vs Server Latency ---")
+	table := NewTable("Request", "Count", "Client Median", "Server Median", "Gap Median", "Gap P95")
+	for _, timing := range br.ServerTimings {
+		table.AddRow(
+			timing.Request,
+			fmt.Sprintf("%d", timing.Count),
+			formatMs(timing.ClientMedianMs),
+			formatMs(timing.ServerMedianMs),
+			formatMs(timing.GapMedianMs),
+			formatMs(timing.GapP95Ms),
+		)
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// IterationRecord describes a single completed benchmark iteration
+type IterationRecord struct {
+	RunID      string            `json:"run_id,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Operation  string            `json:"operation"`
+	Iteration  int               `json:"iteration"`
+	DurationMs float64           `json:"duration_ms"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// StreamWriter emits one JSON line per completed iteration as the benchmark runs
+type StreamWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+
+	// Run ID and labels stamped on every record
+	runID  string
+	labels map[string]string
+}
+
+// NewStreamWriter creates a StreamWriter for the given path. "-" writes to stdout and moves all
+// other output to stderr, so that stdout carries nothing but JSON lines.
+func NewStreamWriter(path, runID string, labels map[string]string) (*StreamWriter, error) {
+	sw := &StreamWriter{runID: runID, labels: labels}
+	if path == "-" {
+		sw.encoder = json.NewEncoder(os.Stdout)
+		os.Stdout = os.Stderr
+		return sw, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating stream output file: %v", err)
+	}
+	sw.encoder = json.NewEncoder(file)
+	sw.closer = file
+	return sw, nil
+}
+
+// Write emits the record as a single JSON line. Writing to a nil StreamWriter is a no-op.
+func (sw *StreamWriter) Write(record IterationRecord) {
+	if sw == nil {
+		return
+	}
+
+	record.RunID = sw.runID
+	if len(sw.labels) > 0 {
+		record.Labels = sw.labels
+	}
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if err := sw.encoder.Encode(record); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing stream output: %v\n", err)
+	}
+}
+
+// Close closes the underlying file, if any
+func (sw *StreamWriter) Close() error {
+	if sw == nil || sw.closer == nil {
+		return nil
+	}
+	return sw.closer.Close()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamWriterStdout(t *testing.T) {
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(stdout, stderr *os.File) { os.Stdout, os.Stderr = stdout, stderr }(os.Stdout, os.Stderr)
+	os.Stdout, os.Stderr = stdout, stderr
+
+	sw, err := NewStreamWriter("-", "run-1", map[string]string{"env": "test"})
+	if err != nil {
+		t.Fatalf("NewStreamWriter() error = %v", err)
+	}
+	fmt.Println("Running each benchmark operation for 2 iterations")
+	sw.Write(IterationRecord{Timestamp: time.Now(), Operation: "list pods", Iteration: 1, DurationMs: 1.5})
+	table := NewTable("Operation", "Count")
+	table.AddRow("list pods", "2")
+	table.Render(os.Stdout)
+	sw.Write(IterationRecord{Timestamp: time.Now(), Operation: "list pods", Iteration: 2, Error: "connection refused"})
+
+	streamed, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(strings.NewReader(string(streamed)))
+	for scanner.Scan() {
+		var record IterationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Errorf("stdout line %q is not a JSON record: %v", scanner.Text(), err)
+			continue
+		}
+		if record.RunID != "run-1" || record.Operation != "list pods" {
+			t.Errorf("record = %+v, want run-1 and list pods", record)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("stdout has %d records, want 2", lines)
+	}
+
+	output, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), "Running each benchmark operation") || !strings.Contains(string(output), "list pods") {
+		t.Errorf("stderr = %q, want the human-readable output", output)
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Suite phases: seed suites prepare data, measure suites benchmark it and cleanup suites remove
+// it again. Every phase starts once the suites of the previous phases completed.
+const (
+	phaseSeed    = "seed"
+	phaseMeasure = "measure"
+	phaseCleanup = "cleanup"
+)
+
+// suitePhases orders the phases
+var suitePhases = map[string]int{phaseSeed: 0, phaseMeasure: 1, phaseCleanup: 2}
+
+// suiteCommands are the subcommands suites can run instead of a benchmark
+var suiteCommands = map[string]bool{"cleanup": true, "merge": true, "diff": true}
+
+// suiteDefinition is a named suite of a scenario: flag settings applied on top of the shared
+// ones, its phase and the suites that must complete successfully before it starts. Suites with a
+// command run that subcommand, such as cleanup, instead of a benchmark.
+type suiteDefinition struct {
+	settings  map[string]interface{}
+	phase     string
+	dependsOn []string
+	command   []string
+
+	// rawOperations replace the raw operations of the scenario if set
+	rawOperations []rawOperation
+}
+
+// scenarioSuites removes the named suites from the scenario settings and returns them. Every
+// suite is a map of flag names to values like the scenario itself, plus the optional "phase",
+// "depends-on", "command" and "raw-operations" keys.
+func scenarioSuites(fs *flag.FlagSet, settings map[string]interface{}) (map[string]*suiteDefinition, []error) {
+	value, ok := settings["suites"]
+	if !ok {
+		return nil, nil
+	}
+	delete(settings, "suites")
+
+	definitions, ok := value.(map[string]interface{})
+	if !ok || len(definitions) == 0 {
+		return nil, []error{fmt.Errorf("suites must map suite names to their settings")}
+	}
+	suites := make(map[string]*suiteDefinition, len(definitions))
+	var problems []error
+	for _, name := range sortedKeys(definitions) {
+		suiteSettings, ok := definitions[name].(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Errorf("suite %q must map flag names to values", name))
+			continue
+		}
+		suite := &suiteDefinition{settings: make(map[string]interface{}), phase: phaseMeasure}
+		for setting, value := range suiteSettings {
+			switch {
+			case setting == "phase":
+				phase, ok := value.(string)
+				if _, known := suitePhases[phase]; !ok || !known {
+					problems = append(problems, fmt.Errorf("suite %q has unknown phase %v (expected seed, measure or cleanup)", name, value))
+				}
+				suite.phase = phase
+			case setting == "depends-on":
+				dependencies, err := scenarioValues(value, true)
+				if err != nil {
+					problems = append(problems, fmt.Errorf("invalid depends-on of suite %q: %v", name, err))
+				}
+				suite.dependsOn = dependencies
+			case setting == "command":
+				command, err := scenarioValues(value, true)
+				if err == nil && (len(command) == 0 || !suiteCommands[command[0]]) {
+					err = fmt.Errorf("expected a subcommand and its arguments, e.g. [cleanup, -n, bench]")
+				}
+				if err != nil {
+					problems = append(problems, fmt.Errorf("invalid command of suite %q: %v", name, err))
+				}
+				suite.command = command
+			case setting == "raw-operations":
+				operations, err := parseRawOperations(value)
+				if err != nil {
+					problems = append(problems, fmt.Errorf("invalid raw-operations of suite %q: %v", name, err))
+				}
+				suite.rawOperations = operations
+			case setting == "suite" || setting == "suites" || setting == "scenario":
+				problems = append(problems, fmt.Errorf("suite %q cannot set %q", name, setting))
+			case fs.Lookup(setting) == nil:
+				problems = append(problems, fmt.Errorf("unknown setting %q in suite %q", setting, name))
+			default:
+				suite.settings[setting] = value
+			}
+		}
+		suites[name] = suite
+	}
+
+	for _, name := range sortedKeys(suites) {
+		for _, dependency := range suites[name].dependsOn {
+			if _, ok := suites[dependency]; !ok {
+				problems = append(problems, fmt.Errorf("suite %q depends on unknown suite %q", name, dependency))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		if _, err := orderSuites(sortedKeys(suites), suites); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return suites, problems
+}
+
+// suiteDependencies returns the suites among selected that must complete before the suite
+// starts: its declared dependencies and the suites of earlier phases
+func suiteDependencies(name string, selected []string, suites map[string]*suiteDefinition) []string {
+	dependencies := append([]string(nil), suites[name].dependsOn...)
+	for _, other := range selected {
+		if suitePhases[suites[other].phase] < suitePhases[suites[name].phase] {
+			dependencies = append(dependencies, other)
+		}
+	}
+	return dependencies
+}
+
+// orderSuites sorts the suites so that every suite comes after its dependencies, failing on
+// dependency cycles
+func orderSuites(selected []string, suites map[string]*suiteDefinition) ([]string, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int)
+	var ordered []string
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("suite dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dependency := range suiteDependencies(name, selected, suites) {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, name)
+		return nil
+	}
+	for _, name := range selected {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// parseSuiteSelection returns the suites named in the comma-separated list, or all suites if
+// the list is empty, in the order they have to run. The selection always includes the seed and
+// cleanup suites and the dependencies of the selected suites.
+func parseSuiteSelection(value string, suites map[string]*suiteDefinition) ([]string, error) {
+	if value == "" {
+		return orderSuites(sortedKeys(suites), suites)
+	}
+	selected := make(map[string]bool)
+	var include func(name string)
+	include = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, dependency := range suites[name].dependsOn {
+			include(dependency)
+		}
+	}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := suites[name]; !ok {
+			return nil, fmt.Errorf("unknown suite %q (available: %s)", name, strings.Join(sortedKeys(suites), ", "))
+		}
+		include(name)
+	}
+	for name, suite := range suites {
+		if suite.phase != phaseMeasure {
+			include(name)
+		}
+	}
+	return orderSuites(sortedKeys(selected), suites)
+}
+
+// suiteOutputPath inserts the suite name before the extension of an output path, so parallel
+// suites don't overwrite each other's files
+func suiteOutputPath(path, suite string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + suite + ext
+}
+
+// suiteRun is the outcome of a suite run as a child process
+type suiteRun struct {
+	name     string
+	output   bytes.Buffer
+	duration time.Duration
+	exitCode int
+	err      error
+
+	// done is closed once the suite completed or was skipped
+	done chan struct{}
+}
+
+// runSuites runs the selected suites as child processes with args plus --suite, at most
+// parallelism of them at a time (0 for all at once). A suite starts once its dependencies and
+// the suites of earlier phases completed; if one of them failed, it is skipped unless it is a
+// cleanup suite. The output of every suite is printed as a separate section once all suites
+// completed. Output files named in outputs are suffixed with the suite name. It returns the
+// highest exit status of the suites.
+func runSuites(selected []string, suites map[string]*suiteDefinition, parallelism int, args []string, outputs map[string]string) int {
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Error: unable to find the k8s-api-bench executable: %v\n", err)
+		return 1
+	}
+	args = argsWithout(argsWithout(args, "suites"), "suite-parallelism")
+	if parallelism <= 0 {
+		parallelism = len(selected)
+	}
+
+	fmt.Printf("Running %d suites, %d at a time: %s\n", len(selected), parallelism, strings.Join(selected, ", "))
+	runs := make(map[string]*suiteRun, len(selected))
+	for _, name := range selected {
+		runs[name] = &suiteRun{name: name, done: make(chan struct{})}
+	}
+	semaphore := make(chan struct{}, parallelism)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range selected {
+		run := runs[name]
+		dependencies := suiteDependencies(name, selected, suites)
+		cleanup := suites[name].phase == phaseCleanup
+
+		childArgs := append(append([]string(nil), args...), "--suite="+name, "--label=suite="+name)
+		for _, flagName := range sortedKeys(outputs) {
+			if path := outputs[flagName]; path != "" && path != "-" {
+				childArgs = append(childArgs, "--"+flagName+"="+suiteOutputPath(path, name))
+			}
+		}
+		if command := suites[name].command; len(command) > 0 {
+			childArgs = command
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(run.done)
+
+			for _, dependency := range dependencies {
+				<-runs[dependency].done
+				if runs[dependency].err != nil && !cleanup && run.err == nil {
+					run.err = fmt.Errorf("skipped, %s did not complete", dependency)
+				}
+			}
+			if run.err != nil {
+				return
+			}
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			cmd := exec.Command(executable, childArgs...)
+			cmd.Stdout = &run.output
+			cmd.Stderr = &run.output
+			start := time.Now()
+			run.err = cmd.Run()
+			run.duration = time.Since(start)
+			var exitErr *exec.ExitError
+			switch {
+			case errors.As(run.err, &exitErr):
+				run.exitCode = exitErr.ExitCode()
+			case run.err != nil:
+				run.exitCode = 1
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Printf("Suite %s finished in %v\n", name, run.duration.Round(time.Millisecond))
+		}()
+	}
+	wg.Wait()
+
+	exitCode := 0
+	for _, name := range selected {
+		run := runs[name]
+		exitCode = max(exitCode, run.exitCode)
+		if run.output.Len() == 0 {
+			continue
+		}
+		fmt.Printf("\n=== Suite: %s ===\n", run.name)
+		os.Stdout.Write(run.output.Bytes())
+	}
+
+	fmt.Println("\n--- Suites ---")
+	table := NewTable("Suite", "Phase", "Duration", "Result")
+	for _, name := range selected {
+		run := runs[name]
+		result := Cell{Text: "ok"}
+		if run.err != nil {
+			result = Cell{Text: run.err.Error(), Color: colorRed}
+		}
+		table.AddCells(Cell{Text: run.name}, Cell{Text: suites[name].phase}, Cell{Text: formatDuration(run.duration)}, result)
+	}
+	table.Render(os.Stdout)
+	return exitCode
+}
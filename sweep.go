@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// listableResource describes a resource that can be listed cluster-wide by the limit sweep and
+// the metadata-only list benchmark
+type listableResource struct {
+	client  func(clientset *kubernetes.Clientset) rest.Interface
+	newList func() runtime.Object
+}
+
+// coreClient returns the REST client of the core/v1 API group
+func coreClient(clientset *kubernetes.Clientset) rest.Interface {
+	return clientset.CoreV1().RESTClient()
+}
+
+// appsClient returns the REST client of the apps/v1 API group
+func appsClient(clientset *kubernetes.Clientset) rest.Interface {
+	return clientset.AppsV1().RESTClient()
+}
+
+// listableResources are the resources supported by --limit-sweep-resources and --metadata-lists
+var listableResources = map[string]listableResource{
+	"pods":        {coreClient, func() runtime.Object { return &corev1.PodList{} }},
+	"services":    {coreClient, func() runtime.Object { return &corev1.ServiceList{} }},
+	"configmaps":  {coreClient, func() runtime.Object { return &corev1.ConfigMapList{} }},
+	"secrets":     {coreClient, func() runtime.Object { return &corev1.SecretList{} }},
+	"deployments": {appsClient, func() runtime.Object { return &appsv1.DeploymentList{} }},
+}
+
+// parseLimits parses a comma-separated list of page sizes, where 0 means unlimited
+func parseLimits(value string) ([]int64, error) {
+	var limits []int64
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if field == "unlimited" {
+			limits = append(limits, 0)
+			continue
+		}
+		limit, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit %q", field)
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// parseListableResources parses a comma-separated list of listable resources
+func parseListableResources(value string) ([]string, error) {
+	var resources []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := listableResources[field]; !ok {
+			return nil, fmt.Errorf("unsupported resource %q", field)
+		}
+		resources = append(resources, field)
+	}
+	return resources, nil
+}
+
+// describeLimit returns a human-readable page size
+func describeLimit(limit int64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(limit, 10)
+}
+
+// paginatedListOperation returns the name under which a paginated list is recorded
+func paginatedListOperation(resource string, limit int64) string {
+	return fmt.Sprintf("paginated list %s (limit=%s)", resource, describeLimit(limit))
+}
+
+// paginatedList lists all objects of the resource across all namespaces in pages of the given
+// size, following continue tokens until the list is complete. It returns the latency of the
+// first page, the number of pages and the number of items.
+func paginatedList(ctx context.Context, clientset *kubernetes.Clientset, resource string, limit int64) (time.Duration, int, int, error) {
+	lr := listableResources[resource]
+	client := lr.client(clientset)
+
+	var firstPage time.Duration
+	pages, items := 0, 0
+	continueToken := ""
+	for {
+		startTime := time.Now()
+		list := lr.newList()
+		err := client.Get().
+			Resource(resource).
+			VersionedParams(&metav1.ListOptions{Limit: limit, Continue: continueToken}, scheme.ParameterCodec).
+			Do(ctx).
+			Into(list)
+		if err != nil {
+			return 0, pages, items, err
+		}
+		if pages == 0 {
+			firstPage = time.Since(startTime)
+		}
+		pages++
+		items += meta.LenList(list)
+
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return 0, pages, items, err
+		}
+		continueToken = listMeta.GetContinue()
+		if continueToken == "" {
+			return firstPage, pages, items, nil
+		}
+	}
+}
+
+// pageSizeTolerance is how much slower than the fastest page size, in total latency, the
+// recommended page size may be; smaller pages are preferred within it, as they return the first
+// items sooner and bound the memory used by the apiserver and the client per request
+const pageSizeTolerance = 1.1
+
+// PageSizeRecommendation is the recommended page size for listing a resource on the measured
+// cluster, with the medians it was chosen by
+type PageSizeRecommendation struct {
+	Resource string `json:"resource"`
+	// Limit is the recommended page size, 0 for unlimited
+	Limit             int64   `json:"limit"`
+	Pages             int     `json:"pages"`
+	FirstPageMedianMs float64 `json:"first_page_median_ms"`
+	TotalMedianMs     float64 `json:"total_median_ms"`
+	// FastestTotalMs is the median total latency of the fastest page size
+	FastestTotalMs float64 `json:"fastest_total_ms"`
+}
+
+// recommendPageSize picks the smallest page size whose median total latency is within
+// pageSizeTolerance of the fastest one
+func recommendPageSize(resource string, limits []int64, pages map[int64]int, stats map[string]map[string]time.Duration) (PageSizeRecommendation, bool) {
+	// Unlimited is the largest page size
+	effective := func(limit int64) int64 {
+		if limit == 0 {
+			return math.MaxInt64
+		}
+		return limit
+	}
+	sorted := append([]int64(nil), limits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return effective(sorted[i]) < effective(sorted[j])
+	})
+
+	fastest := time.Duration(-1)
+	for _, limit := range sorted {
+		if stat, ok := stats[paginatedListOperation(resource, limit)]; ok && (fastest < 0 || stat["median"] < fastest) {
+			fastest = stat["median"]
+		}
+	}
+	if fastest < 0 {
+		return PageSizeRecommendation{}, false
+	}
+
+	for _, limit := range sorted {
+		name := paginatedListOperation(resource, limit)
+		stat, ok := stats[name]
+		if !ok || float64(stat["median"]) > float64(fastest)*pageSizeTolerance {
+			continue
+		}
+		return PageSizeRecommendation{
+			Resource:          resource,
+			Limit:             limit,
+			Pages:             pages[limit],
+			FirstPageMedianMs: durationMs(stats[name+" first page"]["median"]),
+			TotalMedianMs:     durationMs(stat["median"]),
+			FastestTotalMs:    durationMs(fastest),
+		}, true
+	}
+	return PageSizeRecommendation{}, false
+}
+
+// benchmarkLimitSweep lists every resource cluster-wide with each page size, recording the total
+// time to fetch all pages and, separately, the latency of the first page, then recommends a page
+// size per resource
+func benchmarkLimitSweep(clientset *kubernetes.Clientset, resources []string, limits []int64, iterations int, results *BenchmarkResults) {
+	fmt.Println("\n--- List limit sweep ---")
+
+	pages := make(map[string]map[int64]int)
+	for _, resource := range resources {
+		pages[resource] = make(map[int64]int)
+		for _, limit := range limits {
+			name := paginatedListOperation(resource, limit)
+			runBenchmark(name, iterations, func(ctx context.Context) error {
+				firstPage, pageCount, items, err := paginatedList(ctx, clientset, resource, limit)
+				if err != nil {
+					return err
+				}
+				results.Add(name+" first page", firstPage)
+				pages[resource][limit] = pageCount
+				fmt.Printf("Fetched %d %s in %d pages\n", items, resource, pageCount)
+				return nil
+			}, results)
+		}
+	}
+
+	stats := results.CalculateStats()
+	for _, resource := range resources {
+		if recommendation, ok := recommendPageSize(resource, limits, pages[resource], stats); ok {
+			results.PageSizes = replaceRestored(results.PageSizes, recommendation, func(r PageSizeRecommendation) string { return r.Resource })
+		}
+	}
+}
+
+// PrintPageSizeRecommendations prints the recommended page size per resource of the limit sweep
+func (br *BenchmarkResults) PrintPageSizeRecommendations() {
+	if len(br.PageSizes) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Page Size Recommendations ---")
+	fmt.Printf("Smallest page size within %.0f%% of the fastest total list latency\n", (pageSizeTolerance-1)*100)
+	table := NewTable("Resource", "Limit", "Pages", "First Page", "Total", "Fastest Total")
+	for _, recommendation := range br.PageSizes {
+		table.AddRow(
+			recommendation.Resource,
+			describeLimit(recommendation.Limit),
+			fmt.Sprintf("%d", recommendation.Pages),
+			formatMs(recommendation.FirstPageMedianMs),
+			formatMs(recommendation.TotalMedianMs),
+			formatMs(recommendation.FastestTotalMs),
+		)
+	}
+	table.Render(os.Stdout)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int64
+		wantErr bool
+	}{
+		{value: "50,500,5000", want: []int64{50, 500, 5000}},
+		{value: " 100 , unlimited ,", want: []int64{100, 0}},
+		{value: "0", want: []int64{0}},
+		{value: "", want: nil},
+		{value: "-1", wantErr: true},
+		{value: "100,abc", wantErr: true},
+		{value: "1.5", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseLimits(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLimits(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseLimits(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRecommendPageSize(t *testing.T) {
+	// medians returns the stats of the paginated lists of pods with the given median totals
+	medians := func(totals map[int64]time.Duration) map[string]map[string]time.Duration {
+		stats := make(map[string]map[string]time.Duration)
+		for limit, total := range totals {
+			name := paginatedListOperation("pods", limit)
+			stats[name] = map[string]time.Duration{"median": total}
+			stats[name+" first page"] = map[string]time.Duration{"median": total / 10}
+		}
+		return stats
+	}
+	limits := []int64{0, 5000, 500, 50}
+	pages := map[int64]int{0: 1, 5000: 2, 500: 20, 50: 200}
+
+	tests := []struct {
+		name   string
+		totals map[int64]time.Duration
+		want   int64
+		wantOK bool
+	}{
+		{
+			name:   "smallest within the tolerance of the fastest",
+			totals: map[int64]time.Duration{0: 100 * time.Millisecond, 5000: 105 * time.Millisecond, 500: 108 * time.Millisecond, 50: 300 * time.Millisecond},
+			want:   500,
+			wantOK: true,
+		},
+		{
+			name:   "unlimited is the largest",
+			totals: map[int64]time.Duration{0: 100 * time.Millisecond, 5000: 200 * time.Millisecond, 500: 300 * time.Millisecond, 50: 400 * time.Millisecond},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "smallest is the fastest",
+			totals: map[int64]time.Duration{0: 300 * time.Millisecond, 50: 100 * time.Millisecond},
+			want:   50,
+			wantOK: true,
+		},
+		{
+			name:   "no measurements",
+			totals: map[int64]time.Duration{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := recommendPageSize("pods", limits, pages, medians(tt.totals))
+			if ok != tt.wantOK {
+				t.Fatalf("recommendPageSize() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Limit != tt.want || got.Pages != pages[tt.want] {
+				t.Errorf("recommendPageSize() = limit %d in %d pages, want limit %d in %d pages", got.Limit, got.Pages, tt.want, pages[tt.want])
+			}
+			if got.TotalMedianMs != durationMs(tt.totals[tt.want]) || got.FirstPageMedianMs != durationMs(tt.totals[tt.want]/10) {
+				t.Errorf("recommendPageSize() medians = %v, %v, want %v, %v", got.TotalMedianMs, got.FirstPageMedianMs,
+					durationMs(tt.totals[tt.want]), durationMs(tt.totals[tt.want]/10))
+			}
+		})
+	}
+}
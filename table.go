@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	// colorDefault is the default foreground, as long as the other colors
+	colorDefault = "\033[39m"
+)
+
+// colorEnabled controls whether tables are rendered with ANSI colors. It is disabled by
+// --no-color, the NO_COLOR environment variable or when stdout is not a terminal.
+var colorEnabled = os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+
+// slowThreshold is the P95 latency above which an operation is highlighted as slow
+var slowThreshold = 100 * time.Millisecond
+
+// minOpColWidth is the narrowest the first column is truncated to when fitting the terminal
+const minOpColWidth = 16
+
+// Cell is a single table cell with an optional ANSI color
+type Cell struct {
+	Text  string
+	Color string
+}
+
+// Table renders aligned text tables on a tabwriter. The first column is left aligned and
+// truncated to fit the terminal width, all other columns are right aligned.
+type Table struct {
+	headers []string
+	rows    [][]Cell
+}
+
+// NewTable creates a table with the given column headers
+func NewTable(headers ...string) *Table {
+	return &Table{headers: headers}
+}
+
+// AddRow appends a row of uncolored cells
+func (t *Table) AddRow(texts ...string) {
+	cells := make([]Cell, len(texts))
+	for i, text := range texts {
+		cells[i] = Cell{Text: text}
+	}
+	t.rows = append(t.rows, cells)
+}
+
+// AddCells appends a row of cells
+func (t *Table) AddCells(cells ...Cell) {
+	t.rows = append(t.rows, cells)
+}
+
+// terminalWidth returns the width of the terminal attached to stdout, or 0 if there is none
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// truncate shortens text to width runes, marking the cut with "..."
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// ansiEscape matches the ANSI color sequences, which take no space on the terminal
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// visibleWidth returns the number of runes of text as shown on the terminal
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(text, ""))
+}
+
+// Render writes the table to w
+func (t *Table) Render(w io.Writer) {
+	firstWidth := utf8.RuneCountInString(t.headers[0])
+	for _, row := range t.rows {
+		if len(row) > 0 {
+			firstWidth = max(firstWidth, utf8.RuneCountInString(row[0].Text))
+		}
+	}
+	lines := t.format(firstWidth)
+
+	// Shrink the first column when the table is wider than the terminal
+	if termWidth := terminalWidth(); termWidth > 0 {
+		if overflow := visibleWidth(lines[0]) - termWidth; overflow > 0 {
+			lines = t.format(max(minOpColWidth, firstWidth-overflow))
+		}
+	}
+
+	// The separator follows the column boundaries of the header
+	separator := []rune(ansiEscape.ReplaceAllString(lines[0], ""))
+	for i, r := range separator {
+		if r == '|' {
+			separator[i] = '+'
+		} else {
+			separator[i] = '-'
+		}
+	}
+	lines = append([]string{lines[0], string(separator)}, lines[1:]...)
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+}
+
+// format aligns the header and rows with a tabwriter, the first column truncated and padded to
+// width and all other columns right aligned, and returns the lines
+func (t *Table) format(width int) []string {
+	// The colors count towards the cell width of the tabwriter, so all cells of a column with
+	// colors get color sequences of the same length
+	colored := make([]bool, len(t.headers))
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i < len(colored) && colorEnabled && cell.Color != "" {
+				colored[i] = true
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 0, ' ', tabwriter.AlignRight)
+	writeRow := func(row []Cell) {
+		for i := range t.headers {
+			var cell Cell
+			if i < len(row) {
+				cell = row[i]
+			}
+			text := cell.Text
+			if i == 0 {
+				text = truncate(text, width)
+				text += strings.Repeat(" ", width-utf8.RuneCountInString(text))
+			}
+			if colored[i] {
+				color := cell.Color
+				if color == "" {
+					color = colorDefault
+				}
+				text = color + text + colorReset
+			}
+			if i > 0 {
+				text = " " + text
+			}
+			if i < len(t.headers)-1 {
+				text += " |"
+			}
+			fmt.Fprint(tw, text, "\t")
+		}
+		fmt.Fprintln(tw)
+	}
+
+	headerCells := make([]Cell, len(t.headers))
+	for i, header := range t.headers {
+		headerCells[i] = Cell{Text: header}
+	}
+	writeRow(headerCells)
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+	tw.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return lines
+}
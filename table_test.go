@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableRender(t *testing.T) {
+	newTable := func() *Table {
+		table := NewTable("Operation", "Count", "Median")
+		table.AddRow("list pods", "10", "1.2 ms")
+		table.AddCells(Cell{Text: "get ConfigMap", Color: colorRed}, Cell{Text: "100"}, Cell{Text: "12.3 ms", Color: colorGreen})
+		table.AddRow("list nodes", "5")
+		return table
+	}
+
+	tests := []struct {
+		name  string
+		color bool
+		want  string
+	}{
+		{
+			name: "plain",
+			want: `Operation     | Count |  Median
+--------------+-------+--------
+list pods     |    10 |  1.2 ms
+get ConfigMap |   100 | 12.3 ms
+list nodes    |     5 |
+`,
+		},
+		{
+			name:  "colored",
+			color: true,
+			want: "\x1b[39mOperation    \x1b[0m | Count |  \x1b[39mMedian\x1b[0m\n" +
+				"--------------+-------+--------\n" +
+				"\x1b[39mlist pods    \x1b[0m |    10 |  \x1b[39m1.2 ms\x1b[0m\n" +
+				"\x1b[31mget ConfigMap\x1b[0m |   100 | \x1b[32m12.3 ms\x1b[0m\n" +
+				"\x1b[39mlist nodes   \x1b[0m |     5 |        \x1b[39m\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(enabled bool) { colorEnabled = enabled }(colorEnabled)
+			colorEnabled = tt.color
+
+			var out strings.Builder
+			newTable().Render(&out)
+			if got := out.String(); got != tt.want {
+				t.Errorf("Render() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{text: "list pods", width: 20, want: "list pods"},
+		{text: "list pods", width: 9, want: "list pods"},
+		{text: "list pods [default]", width: 10, want: "list po..."},
+		{text: "ünïcödé", width: 6, want: "ünï..."},
+		{text: "list", width: 2, want: "li"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.text, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
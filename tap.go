@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// writeTAP writes every operation of the summary as a TAP version 13 test point, with its
+// latencies or failure reasons as YAML diagnostics. Operations with failed iterations or a
+// regression against the baseline are "not ok".
+func writeTAP(w io.Writer, summary Summary, regressions map[string]float64) error {
+	results := operationResults(summary, regressions)
+
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+	for i, result := range results {
+		status := "ok"
+		if result.failure != "" {
+			status = "not ok"
+		}
+		// A # would start a directive such as SKIP or TODO
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, strings.ReplaceAll(result.name, "#", `\#`))
+
+		b.WriteString("  ---\n")
+		if result.failure != "" {
+			fmt.Fprintf(&b, "  message: %s\n", strconv.Quote(result.failure))
+		}
+		if len(result.details) > 0 {
+			b.WriteString("  errors:\n")
+			for _, detail := range result.details {
+				fmt.Fprintf(&b, "    - %s\n", strconv.Quote(detail))
+			}
+		}
+		if result.medianMs > 0 {
+			fmt.Fprintf(&b, "  median_ms: %.3f\n", result.medianMs)
+			fmt.Fprintf(&b, "  p95_ms: %.3f\n", result.p95Ms)
+		}
+		b.WriteString("  ...\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTAPOutput writes the TAP report to path, or to stdout if path is "-"
+func writeTAPOutput(path string, summary Summary, regressions map[string]float64) error {
+	if path == "-" {
+		return writeTAP(os.Stdout, summary, regressions)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating TAP report: %v", err)
+	}
+	defer file.Close()
+	if err := writeTAP(file, summary, regressions); err != nil {
+		return fmt.Errorf("error writing TAP report: %v", err)
+	}
+	return nil
+}
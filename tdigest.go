@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+)
+
+// streamingStats estimates percentiles with t-digests instead of histograms and skips the
+// per-iteration timeline, so memory stays constant however long the run is
+var streamingStats bool
+
+const (
+	// tdigestCompression bounds the number of centroids of a t-digest to roughly this value
+	tdigestCompression = 200
+
+	// tdigestBufferSize is the number of recorded durations collected before they are merged
+	// into the centroids
+	tdigestBufferSize = 5 * tdigestCompression
+)
+
+// Centroid is the mean of a cluster of adjacent durations in a t-digest
+type Centroid struct {
+	Mean   float64 `json:"mean"`
+	Weight float64 `json:"weight"`
+}
+
+// TDigest is a merging t-digest, which estimates quantiles from a bounded number of centroids.
+// Centroids near the tails hold few durations, so high percentiles stay accurate.
+type TDigest struct {
+	Centroids []Centroid    `json:"centroids"`
+	Total     int64         `json:"total"`
+	Sum       time.Duration `json:"sum"`
+	Min       time.Duration `json:"min"`
+	Max       time.Duration `json:"max"`
+
+	// Durations recorded since the last compression, as weight 1 centroids
+	buffer []Centroid
+}
+
+// NewTDigest creates an empty t-digest
+func NewTDigest() *TDigest {
+	return &TDigest{}
+}
+
+// Record adds a duration to the digest
+func (t *TDigest) Record(d time.Duration) {
+	d = max(d, 0)
+	if t.Total == 0 || d < t.Min {
+		t.Min = d
+	}
+	if d > t.Max {
+		t.Max = d
+	}
+	t.Total++
+	t.Sum += d
+
+	t.buffer = append(t.buffer, Centroid{Mean: float64(d), Weight: 1})
+	if len(t.buffer) >= tdigestBufferSize {
+		t.compress()
+	}
+}
+
+// Merge adds all durations recorded by other to the digest
+func (t *TDigest) Merge(other *TDigest) {
+	if other.Total == 0 {
+		return
+	}
+	if t.Total == 0 || other.Min < t.Min {
+		t.Min = other.Min
+	}
+	if other.Max > t.Max {
+		t.Max = other.Max
+	}
+	t.Total += other.Total
+	t.Sum += other.Sum
+
+	t.buffer = append(t.buffer, other.Centroids...)
+	t.buffer = append(t.buffer, other.buffer...)
+	t.compress()
+}
+
+// tdigestScale is the k1 scale function of the t-digest, which maps quantiles to a range of
+// compression/2 and is steepest near the tails, where centroids thus stay small
+func tdigestScale(q float64) float64 {
+	return tdigestCompression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// compress merges the buffered durations into the centroids. Adjacent centroids are combined as
+// long as the combined centroid spans at most 1 on the scale, which bounds the number of
+// centroids to about the compression.
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.Centroids, t.buffer...)
+	t.buffer = nil
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Mean < all[j].Mean
+	})
+
+	var total float64
+	for _, c := range all {
+		total += c.Weight
+	}
+
+	merged := []Centroid{all[0]}
+	cumulative := 0.0
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+		qLeft := cumulative / total
+		qRight := (cumulative + last.Weight + c.Weight) / total
+		if tdigestScale(qRight)-tdigestScale(qLeft) <= 1 {
+			last.Mean += (c.Mean - last.Mean) * c.Weight / (last.Weight + c.Weight)
+			last.Weight += c.Weight
+			continue
+		}
+		cumulative += last.Weight
+		merged = append(merged, c)
+	}
+	t.Centroids = merged
+}
+
+// MarshalJSON encodes the digest after merging the buffered durations into the centroids
+func (t *TDigest) MarshalJSON() ([]byte, error) {
+	t.compress()
+	type encoded TDigest
+	return json.Marshal((*encoded)(t))
+}
+
+// Quantile returns the estimated duration below or at which the fraction q of the recorded
+// durations lie, interpolating between the centers of adjacent centroids
+func (t *TDigest) Quantile(q float64) time.Duration {
+	t.compress()
+	if t.Total == 0 {
+		return 0
+	}
+
+	// The minimum and maximum anchor the interpolation at both ends
+	target := q * float64(t.Total)
+	prevRank, prevMean := 0.0, float64(t.Min)
+	cumulative := 0.0
+	for _, c := range t.Centroids {
+		center := cumulative + c.Weight/2
+		if target <= center {
+			if center == prevRank {
+				return time.Duration(c.Mean)
+			}
+			return time.Duration(prevMean + (c.Mean-prevMean)*(target-prevRank)/(center-prevRank))
+		}
+		prevRank, prevMean = center, c.Mean
+		cumulative += c.Weight
+	}
+	if cumulative == prevRank {
+		return t.Max
+	}
+	return time.Duration(prevMean + (float64(t.Max)-prevMean)*(target-prevRank)/(cumulative-prevRank))
+}
+
+// Stats returns the same statistics as durationStats, estimated from the digest
+func (t *TDigest) Stats() map[string]time.Duration {
+	return map[string]time.Duration{
+		"min":    t.Min,
+		"max":    t.Max,
+		"avg":    t.Sum / time.Duration(t.Total),
+		"median": t.Quantile(0.5),
+		"p95":    t.Quantile(0.95),
+		"p99":    t.Quantile(0.99),
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTDigestQuantile(t *testing.T) {
+	uniform := NewTDigest()
+	for i := 1; i <= 10000; i++ {
+		uniform.Record(time.Duration(i) * time.Microsecond)
+	}
+
+	// Exponentially distributed latencies with a long tail, from a fixed seed
+	random := rand.New(rand.NewSource(1))
+	exponential := NewTDigest()
+	for i := 0; i < 50000; i++ {
+		exponential.Record(time.Duration(random.ExpFloat64() * float64(time.Millisecond)))
+	}
+	exponentialQuantile := func(q float64) time.Duration {
+		return time.Duration(-math.Log(1-q) * float64(time.Millisecond))
+	}
+
+	tests := []struct {
+		name   string
+		digest *TDigest
+		q      float64
+		want   time.Duration
+		// tolerance is the allowed relative error
+		tolerance float64
+	}{
+		{name: "uniform median", digest: uniform, q: 0.5, want: 5000 * time.Microsecond, tolerance: 0.01},
+		{name: "uniform p95", digest: uniform, q: 0.95, want: 9500 * time.Microsecond, tolerance: 0.01},
+		{name: "uniform p99", digest: uniform, q: 0.99, want: 9900 * time.Microsecond, tolerance: 0.01},
+		{name: "uniform min", digest: uniform, q: 0, want: time.Microsecond, tolerance: 0},
+		{name: "uniform max", digest: uniform, q: 1, want: 10000 * time.Microsecond, tolerance: 0},
+		{name: "exponential median", digest: exponential, q: 0.5, want: exponentialQuantile(0.5), tolerance: 0.03},
+		{name: "exponential p99", digest: exponential, q: 0.99, want: exponentialQuantile(0.99), tolerance: 0.05},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.digest.Quantile(tt.q)
+			if math.Abs(float64(got-tt.want)) > float64(tt.want)*tt.tolerance {
+				t.Errorf("Quantile(%v) = %v, want %v within %.0f%%", tt.q, got, tt.want, tt.tolerance*100)
+			}
+		})
+	}
+}
+
+func TestTDigestCompression(t *testing.T) {
+	digest := NewTDigest()
+	for i := 0; i < 100000; i++ {
+		digest.Record(time.Duration(i))
+	}
+	digest.compress()
+	if len(digest.Centroids) > 2*tdigestCompression {
+		t.Errorf("%d centroids, want at most %d", len(digest.Centroids), 2*tdigestCompression)
+	}
+	var weight float64
+	for _, centroid := range digest.Centroids {
+		weight += centroid.Weight
+	}
+	if int64(weight) != digest.Total {
+		t.Errorf("centroids weigh %v, want the total of %d", weight, digest.Total)
+	}
+}
+
+func TestTDigestMergeAndJSON(t *testing.T) {
+	first, second := NewTDigest(), NewTDigest()
+	for i := 1; i <= 500; i++ {
+		first.Record(time.Duration(i) * time.Millisecond)
+		second.Record(time.Duration(500+i) * time.Millisecond)
+	}
+	first.Merge(second)
+	if first.Total != 1000 || first.Min != time.Millisecond || first.Max != 1000*time.Millisecond {
+		t.Fatalf("Total, Min, Max = %d, %v, %v, want 1000, 1ms, 1s", first.Total, first.Min, first.Max)
+	}
+
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("encoding digest: %v", err)
+	}
+	decoded := NewTDigest()
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("decoding digest: %v", err)
+	}
+	for _, q := range []float64{0.5, 0.95, 0.99} {
+		if got, want := decoded.Quantile(q), first.Quantile(q); got != want {
+			t.Errorf("decoded Quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+}
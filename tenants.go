@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// tenantOperation returns the name of an operation run by the given tenant
+func tenantOperation(operation string, tenant int) string {
+	return fmt.Sprintf("%s [tenant %d]", operation, tenant)
+}
+
+// newTenantConfig returns a copy of config for an independent client with its own connections
+// and rate limiter, impersonating the user unless it is empty
+func newTenantConfig(config *rest.Config, user string) *rest.Config {
+	tenantConfig := rest.CopyConfig(config)
+	if user != "" {
+		tenantConfig.Impersonate = rest.ImpersonationConfig{UserName: user}
+	}
+	// A custom dialer keeps client-go from sharing the cached transport between the tenants
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	tenantConfig.Dial = dialer.DialContext
+	return tenantConfig
+}
+
+// tenantResult is the outcome of one tenant of the tenant load
+type tenantResult struct {
+	user     string
+	elapsed  time.Duration
+	requests int
+}
+
+// jainFairness returns Jain's fairness index of the values, from 1/n when one value gets
+// everything to 1 when all are equal
+func jainFairness(values []float64) float64 {
+	var sum, sumSquares float64
+	for _, value := range values {
+		sum += value
+		sumSquares += value * value
+	}
+	if sumSquares == 0 {
+		return 0
+	}
+	return sum * sum / (float64(len(values)) * sumSquares)
+}
+
+// benchmarkTenants simulates multi-tenant pressure: count independent clients, each impersonating
+// the next of users in turn if any are given, run the namespaced list operations in namespace
+// concurrently. Operations are recorded per tenant as "<operation> [tenant N]". The per-tenant
+// latency and throughput are printed side by side, and Jain's fairness index of the throughput
+// is recorded as metric, exposing tenants the apiserver serves worse than others.
+func benchmarkTenants(config *rest.Config, count int, users []string, namespace string, iterations int, results *BenchmarkResults) {
+	fmt.Printf("\n--- Tenant load: %d tenants in namespace %s ---\n", count, namespace)
+
+	tenants := make([]tenantResult, count)
+	clientsets := make([]*kubernetes.Clientset, count)
+	for i := range tenants {
+		if len(users) > 0 {
+			tenants[i].user = users[i%len(users)]
+		}
+		clientset, err := kubernetes.NewForConfig(newTenantConfig(config, tenants[i].user))
+		if err != nil {
+			fmt.Printf("Error creating client of tenant %d: %v\n", i+1, err)
+			return
+		}
+		clientsets[i] = clientset
+	}
+
+	var wg sync.WaitGroup
+	for i := range tenants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tenant := i + 1
+			startTime := time.Now()
+			for iteration := 0; iteration < iterations; iteration++ {
+				for _, op := range namespacedOperations {
+					name := tenantOperation(op.name, tenant)
+					measureTime(name, iteration+1, iterations, func(ctx context.Context) error {
+						return op.list(ctx, clientsets[i], namespace, name, results)
+					}, results)
+				}
+			}
+			tenants[i].elapsed = time.Since(startTime)
+		}()
+	}
+	wg.Wait()
+
+	stats := results.CalculateStats()
+	fmt.Println("\n--- Tenant Fairness ---")
+	table := NewTable("Tenant", "User", "Requests", "Slowest Median", "Slowest P95", "Throughput")
+	throughputs := make([]float64, 0, count)
+	for i, tenant := range tenants {
+		// The slowest operation of a tenant shows the effect of unfair treatment the most
+		var median, p95 time.Duration
+		for _, op := range namespacedOperations {
+			name := tenantOperation(op.name, i+1)
+			tenant.requests += results.Count(name)
+			if stat, ok := stats[name]; ok {
+				median = max(median, stat["median"])
+				p95 = max(p95, stat["p95"])
+			}
+		}
+		throughput := float64(tenant.requests) / tenant.elapsed.Seconds()
+		throughputs = append(throughputs, throughput)
+		user := tenant.user
+		if user == "" {
+			user = kubeconfigIdentity
+		}
+		table.AddRow(fmt.Sprintf("%d", i+1), user, fmt.Sprintf("%d", tenant.requests),
+			formatMs(durationMs(median)), formatMs(durationMs(p95)),
+			fmt.Sprintf("%.1f req/s", throughput))
+	}
+	table.Render(os.Stdout)
+
+	fairness := jainFairness(throughputs)
+	fmt.Printf("Fairness index of the tenant throughput: %.3f (1 is perfectly fair)\n", fairness)
+	results.SetMetric("tenant fairness index", fairness)
+}
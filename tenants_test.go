@@ -0,0 +1,25 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJainFairness(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{name: "equal", values: []float64{10, 10, 10, 10}, want: 1},
+		{name: "one gets everything", values: []float64{40, 0, 0, 0}, want: 0.25},
+		{name: "half", values: []float64{10, 10, 0, 0}, want: 0.5},
+		{name: "skewed", values: []float64{1, 2, 3}, want: 36.0 / 42},
+		{name: "nothing", values: []float64{0, 0}, want: 0},
+	}
+	for _, tt := range tests {
+		if got := jainFairness(tt.values); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("jainFairness(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// throttling observes the 429 responses of the apiserver during the run
+var throttling = &throttleTracker{}
+
+// throttleTracker accumulates the time the apiserver asked clients to back off with 429
+// responses. client-go honors their Retry-After header by waiting that long before retrying
+// the request, up to 10 times, so the accumulated Retry-After is the time spent throttled.
+type throttleTracker struct {
+	waited    atomic.Int64
+	responses atomic.Int64
+}
+
+// throttledKey is the context key of the time the requests of an iteration spent backing off
+type throttledKey struct{}
+
+// withThrottleCounter returns a context whose requests add the time they spend backing off from
+// 429 responses to the returned counter, so that it is attributed to the iteration that sent
+// them even while other operations run concurrently
+func withThrottleCounter(ctx context.Context) (context.Context, *atomic.Int64) {
+	var waited atomic.Int64
+	return context.WithValue(ctx, throttledKey{}, &waited), &waited
+}
+
+// trackThrottling installs the tracker on the config's transport
+func trackThrottling(config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &throttleTransport{next: rt, tracker: throttling}
+	})
+}
+
+// Waited returns the total time spent backing off so far
+func (t *throttleTracker) Waited() time.Duration {
+	return time.Duration(t.waited.Load())
+}
+
+// throttleTransport records the Retry-After of every 429 response, run-wide and on the counter
+// of the request's context, if any
+type throttleTransport struct {
+	next    http.RoundTripper
+	tracker *throttleTracker
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		t.tracker.responses.Add(1)
+		// client-go only waits for Retry-After given in seconds
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			waited := int64(time.Duration(seconds) * time.Second)
+			t.tracker.waited.Add(waited)
+			if counter, ok := req.Context().Value(throttledKey{}).(*atomic.Int64); ok {
+				counter.Add(waited)
+			}
+		}
+	}
+	return resp, err
+}
+
+// AddThrottled records time the operation spent backing off from 429 responses
+func (br *BenchmarkResults) AddThrottled(operation string, waited time.Duration) {
+	if waited <= 0 {
+		return
+	}
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	br.Throttled[operation] += waited
+}
+
+// ThrottledTime returns the total time the operation spent backing off from 429 responses
+func (br *BenchmarkResults) ThrottledTime(operation string) time.Duration {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	return br.Throttled[operation]
+}
+
+// SetMetrics records the number of 429 responses, if there were any
+func (t *throttleTracker) SetMetrics(results *BenchmarkResults) {
+	if responses := t.responses.Load(); responses > 0 {
+		results.SetMetric("throttled responses (429)", float64(responses))
+		results.SetMetric("throttled time (s)", t.Waited().Seconds())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestThrottleTransportAttribution(t *testing.T) {
+	// Requests to /throttled are rejected with a Retry-After of 2 seconds, all others succeed
+	transport := &throttleTransport{tracker: &throttleTracker{}, next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		if req.URL.Path == "/throttled" {
+			recorder.Header().Set("Retry-After", "2")
+			recorder.WriteHeader(http.StatusTooManyRequests)
+		}
+		return recorder.Result(), nil
+	})}
+
+	const requests = 10
+	throttledCtx, throttled := withThrottleCounter(context.Background())
+	acceptedCtx, accepted := withThrottleCounter(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		for _, request := range []struct {
+			ctx  context.Context
+			path string
+		}{{throttledCtx, "/throttled"}, {acceptedCtx, "/accepted"}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodGet, request.path, nil).WithContext(request.ctx)
+				if _, err := transport.RoundTrip(req); err != nil {
+					t.Errorf("RoundTrip() error = %v", err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if got, want := time.Duration(throttled.Load()), requests*2*time.Second; got != want {
+		t.Errorf("throttled requests waited %v, want %v", got, want)
+	}
+	if got := time.Duration(accepted.Load()); got != 0 {
+		t.Errorf("accepted requests waited %v, want 0", got)
+	}
+	if got, want := transport.tracker.Waited(), requests*2*time.Second; got != want {
+		t.Errorf("run-wide wait = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	timelineChartWidth  = 600
+	timelineChartHeight = 80
+)
+
+// TimelinePoint is a single successful iteration of an operation on the wall clock
+type TimelinePoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	DurationMs float64   `json:"duration_ms"`
+}
+
+// AddTimelinePoint records when an iteration of the operation started and how long it took,
+// unless streamingStats bounds memory
+func (br *BenchmarkResults) AddTimelinePoint(operation string, startTime time.Time, duration time.Duration) {
+	if streamingStats {
+		return
+	}
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	point := TimelinePoint{Timestamp: startTime, DurationMs: durationMs(duration)}
+	br.timelineSeen[operation]++
+	switch slot := reservoirSlot(br.timelineSeen[operation], len(br.Timeline[operation])); {
+	case slot < 0:
+		br.Timeline[operation] = append(br.Timeline[operation], point)
+	case slot < len(br.Timeline[operation]):
+		br.Timeline[operation][slot] = point
+	}
+}
+
+// TimelineEvent is something that happened during the run and may explain latency changes
+type TimelineEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+}
+
+// AddEvent records and prints an event at the current time
+func (br *BenchmarkResults) AddEvent(message string) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	event := TimelineEvent{Timestamp: time.Now(), Message: message}
+	br.Events = append(br.Events, event)
+	fmt.Printf("Event at %s: %s\n", event.Timestamp.Format("15:04:05"), message)
+}
+
+// timelineChart is the latency of an operation over the run, rendered as an SVG polyline
+type timelineChart struct {
+	Operation string
+	Points    string
+	MaxMs     float64
+}
+
+// timelineCharts renders the timeline of every operation on a shared time axis, so slow periods
+// line up across operations
+func timelineCharts(timeline map[string][]TimelinePoint) []timelineChart {
+	var start, end time.Time
+	for _, points := range timeline {
+		for _, point := range points {
+			if start.IsZero() || point.Timestamp.Before(start) {
+				start = point.Timestamp
+			}
+			if point.Timestamp.After(end) {
+				end = point.Timestamp
+			}
+		}
+	}
+	span := end.Sub(start)
+	if span <= 0 {
+		span = time.Second
+	}
+
+	var charts []timelineChart
+	for _, op := range sortedKeys(timeline) {
+		points := append([]TimelinePoint(nil), timeline[op]...)
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Timestamp.Before(points[j].Timestamp)
+		})
+
+		maxMs := 0.0
+		for _, point := range points {
+			maxMs = max(maxMs, point.DurationMs)
+		}
+		if maxMs == 0 {
+			continue
+		}
+
+		coordinates := make([]string, 0, len(points))
+		for _, point := range points {
+			x := float64(point.Timestamp.Sub(start)) / float64(span) * timelineChartWidth
+			y := timelineChartHeight - point.DurationMs/maxMs*timelineChartHeight
+			coordinates = append(coordinates, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		charts = append(charts, timelineChart{Operation: op, Points: strings.Join(coordinates, " "), MaxMs: maxMs})
+	}
+	return charts
+}
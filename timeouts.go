@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// requestTimeout is the deadline of every request, 0 for none
+var requestTimeout time.Duration
+
+// slowTimeoutRatio is the share of the deadline above which the P95 of the completed iterations
+// of an operation with timeouts marks it as consistently slow rather than occasionally hanging
+const slowTimeoutRatio = 0.5
+
+// Timeout patterns of an operation
+const (
+	timeoutPatternSlow  = "consistently slow"
+	timeoutPatternHangs = "occasionally hangs"
+)
+
+// TimeoutSummary describes the iterations of an operation that ran into a deadline: how many,
+// after how long, and whether the operation is consistently slow or occasionally hangs
+type TimeoutSummary struct {
+	Operation  string  `json:"operation"`
+	Timeouts   int     `json:"timeouts"`
+	Iterations int     `json:"iterations"`
+	MinMs      float64 `json:"min_elapsed_ms"`
+	MedianMs   float64 `json:"median_elapsed_ms"`
+	MaxMs      float64 `json:"max_elapsed_ms"`
+	// CompletedP95Ms is the P95 latency of the iterations that completed, 0 if none did
+	CompletedP95Ms float64 `json:"completed_p95_ms"`
+	Pattern        string  `json:"pattern"`
+}
+
+// isTimeout reports whether the error is a request running into a client-side deadline or a
+// timeout of the network or the apiserver
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// AddTimeout records the elapsed time of an iteration of the operation if its error is a
+// timeout
+func (br *BenchmarkResults) AddTimeout(operation string, err error, elapsed time.Duration) {
+	if err == nil || !isTimeout(err) {
+		return
+	}
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	histogram, ok := br.Timeouts[operation]
+	if !ok {
+		histogram = NewHistogram()
+		br.Timeouts[operation] = histogram
+	}
+	histogram.Record(elapsed)
+}
+
+// TimeoutSummaries returns the timeouts of every operation that had any, ordered by operation
+func (br *BenchmarkResults) TimeoutSummaries() []TimeoutSummary {
+	if len(br.Timeouts) == 0 {
+		return nil
+	}
+	stats := br.CalculateStats()
+	failures := make(map[string]int)
+	for _, summary := range br.ErrorSummaries() {
+		failures[summary.Operation] += summary.Count
+	}
+
+	summaries := make([]TimeoutSummary, 0, len(br.Timeouts))
+	for operation, histogram := range br.Timeouts {
+		elapsed := histogram.Stats()
+		summary := TimeoutSummary{
+			Operation:      operation,
+			Timeouts:       int(histogram.Total),
+			Iterations:     br.Count(operation) + failures[operation],
+			MinMs:          durationMs(elapsed["min"]),
+			MedianMs:       durationMs(elapsed["median"]),
+			MaxMs:          durationMs(elapsed["max"]),
+			CompletedP95Ms: durationMs(stats[operation]["p95"]),
+		}
+
+		// Without a configured deadline, the timeouts of the network or apiserver are the reference
+		deadline := requestTimeout
+		if deadline == 0 {
+			deadline = elapsed["median"]
+		}
+		summary.Pattern = timeoutPatternHangs
+		if summary.CompletedP95Ms == 0 || summary.CompletedP95Ms >= durationMs(deadline)*slowTimeoutRatio {
+			summary.Pattern = timeoutPatternSlow
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Operation < summaries[j].Operation
+	})
+	return summaries
+}
+
+// PrintTimeouts prints the iterations that ran into a deadline per operation, if there were any
+func (br *BenchmarkResults) PrintTimeouts() {
+	summaries := br.TimeoutSummaries()
+	if len(summaries) == 0 {
+		return
+	}
+
+	fmt.Println("\n--- Timeouts ---")
+	if requestTimeout > 0 {
+		fmt.Printf("Request timeout: %v\n", requestTimeout)
+	}
+	table := NewTable("Operation", "Timeouts", "Min Elapsed", "Median Elapsed", "Max Elapsed", "Completed P95", "Pattern")
+	for _, summary := range summaries {
+		completed := "-"
+		if summary.CompletedP95Ms > 0 {
+			completed = formatMs(summary.CompletedP95Ms)
+		}
+		table.AddCells(
+			Cell{Text: summary.Operation, Color: colorRed},
+			Cell{Text: fmt.Sprintf("%d/%d", summary.Timeouts, summary.Iterations)},
+			Cell{Text: formatMs(summary.MinMs)},
+			Cell{Text: formatMs(summary.MedianMs)},
+			Cell{Text: formatMs(summary.MaxMs)},
+			Cell{Text: completed},
+			Cell{Text: summary.Pattern})
+	}
+	table.Render(os.Stdout)
+}
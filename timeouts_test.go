@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("list pods: %w", context.DeadlineExceeded), want: true},
+		{name: "network timeout", err: &net.OpError{Op: "dial", Err: &net.DNSError{IsTimeout: true}}, want: true},
+		{name: "apiserver timeout", err: apierrors.NewTimeoutError("request timed out", 1), want: true},
+		{name: "apiserver server timeout", err: apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "list", 1), want: true},
+		{name: "cancelled", err: context.Canceled},
+		{name: "not found", err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web")},
+		{name: "other", err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		if got := isTimeout(tt.err); got != tt.want {
+			t.Errorf("isTimeout(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// tokenExpirationSeconds is the lifetime requested for benchmark tokens, the minimum allowed
+const tokenExpirationSeconds = 600
+
+// benchmarkTokens measures issuing ServiceAccount tokens through the TokenRequest API and
+// validating them through the TokenReview API, the paths used by every workload with
+// projected service account tokens
+func benchmarkTokens(clientset *kubernetes.Clientset, namespace, serviceAccount string, iterations int, results *BenchmarkResults) {
+	fmt.Printf("\n--- Token benchmarks for ServiceAccount %s/%s ---\n", namespace, serviceAccount)
+
+	var token string
+	runBenchmark("create ServiceAccount token", iterations, func(ctx context.Context) error {
+		expiration := int64(tokenExpirationSeconds)
+		tokenRequest := &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expiration},
+		}
+		created, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccount, tokenRequest, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+		token = created.Status.Token
+		return nil
+	}, results)
+
+	if token == "" {
+		fmt.Println("No token was issued, skipping TokenReview benchmark")
+		return
+	}
+
+	runBenchmark("review ServiceAccount token", iterations, func(ctx context.Context) error {
+		review := &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}
+		reviewed, err := clientset.AuthenticationV1().TokenReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+		if !reviewed.Status.Authenticated {
+			return fmt.Errorf("token was not authenticated: %s", reviewed.Status.Error)
+		}
+		return nil
+	}, results)
+}
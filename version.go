@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// commit and buildDate are set at build time via -ldflags, falling back to the VCS information
+// embedded by the Go toolchain
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// BuildInfo describes the build of the tool and the Kubernetes libraries compiled into it
+type BuildInfo struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit"`
+	BuildDate       string `json:"build_date"`
+	GoVersion       string `json:"go_version"`
+	ClientGoVersion string `json:"client_go_version"`
+	APIVersion      string `json:"api_version"`
+}
+
+// moduleVersion returns the version of a dependency compiled into the binary
+func moduleVersion(info *debug.BuildInfo, path string) string {
+	if info != nil {
+		for _, dep := range info.Deps {
+			if dep.Path == path {
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+}
+
+// buildSetting returns a setting recorded by the Go toolchain, such as vcs.revision
+func buildSetting(info *debug.BuildInfo, key string) string {
+	if info != nil {
+		for _, setting := range info.Settings {
+			if setting.Key == key {
+				return setting.Value
+			}
+		}
+	}
+	return ""
+}
+
+// readBuildInfo collects the build information of the running binary
+func readBuildInfo() BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	build := BuildInfo{
+		Version:         version,
+		Commit:          commit,
+		BuildDate:       buildDate,
+		GoVersion:       runtime.Version(),
+		ClientGoVersion: moduleVersion(info, "k8s.io/client-go"),
+		APIVersion:      moduleVersion(info, "k8s.io/api"),
+	}
+	if build.Commit == "" {
+		build.Commit = buildSetting(info, "vcs.revision")
+		if buildSetting(info, "vcs.modified") == "true" {
+			build.Commit += "-dirty"
+		}
+	}
+	if build.BuildDate == "" {
+		build.BuildDate = buildSetting(info, "vcs.time")
+	}
+	if build.Commit == "" {
+		build.Commit = "unknown"
+	}
+	if build.BuildDate == "" {
+		build.BuildDate = "unknown"
+	}
+	return build
+}
+
+// runVersion implements the version subcommand
+func runVersion(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s version\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	build := readBuildInfo()
+	fmt.Printf("Version:    %s\n", build.Version)
+	fmt.Printf("Commit:     %s\n", build.Commit)
+	fmt.Printf("Build date: %s\n", build.BuildDate)
+	fmt.Printf("Go:         %s\n", build.GoVersion)
+	fmt.Printf("client-go:  %s\n", build.ClientGoVersion)
+	fmt.Printf("k8s.io/api: %s\n", build.APIVersion)
+	return 0
+}
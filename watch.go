@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// watchEventTimeout is how long to wait for an expected watch event before giving up
+const watchEventTimeout = 30 * time.Second
+
+// waitForEvent waits until the watch delivers an event of the given type for the named object
+func waitForEvent(watcher watch.Interface, eventType watch.EventType, name string) (time.Time, error) {
+	timeout := time.After(watchEventTimeout)
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return time.Time{}, fmt.Errorf("watch closed while waiting for %s event of %s", eventType, name)
+			}
+			if event.Type == watch.Error {
+				return time.Time{}, fmt.Errorf("watch error: %v", event.Object)
+			}
+			object, ok := event.Object.(metav1.Object)
+			if ok && event.Type == eventType && object.GetName() == name {
+				return time.Now(), nil
+			}
+		case <-timeout:
+			return time.Time{}, fmt.Errorf("timed out after %v waiting for %s event of %s", watchEventTimeout, eventType, name)
+		}
+	}
+}
+
+// benchmarkWatchLatency creates ConfigMaps while watching them and measures the time from
+// Create() returning until the ADDED event arrives on the watch
+func benchmarkWatchLatency(clientset *kubernetes.Clientset, namespace string, iterations int, results *BenchmarkResults) {
+	const name = "create-to-watch-event latency"
+	const seedSet = "watch-latency"
+
+	fmt.Printf("\n--- Watch latency benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	// Start watching from the current state, so only newly created objects produce events
+	list, err := configMaps.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+	if err != nil {
+		fmt.Printf("Error listing ConfigMaps: %v\n", err)
+		return
+	}
+	watcher, err := configMaps.Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector:   seedSelector(seedSet),
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer watcher.Stop()
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(name) {
+			continue
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+				Labels: seedLabels(seedSet),
+			},
+		}
+
+		startTime := time.Now()
+		_, err := configMaps.Create(context.TODO(), configMap, metav1.CreateOptions{})
+		createdTime := time.Now()
+		if err != nil {
+			recordIteration(name, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(name, err)
+			continue
+		}
+
+		eventTime, err := waitForEvent(watcher, watch.Added, configMap.Name)
+		recordIteration(name, i+1, iterations, createdTime, eventTime.Sub(createdTime), err, results)
+		results.recordOutcome(name, err)
+	}
+}
+
+// benchmarkWatchThroughput patches a seeded ConfigMap events times as fast as the client allows
+// while consuming the resulting MODIFIED events on a watch. Every event's delivery lag (time from
+// the patch returning until the event arrives) is recorded, together with the overall delivered
+// events per second.
+func benchmarkWatchThroughput(clientset *kubernetes.Clientset, namespace string, events int, results *BenchmarkResults) {
+	const lagName = "watch event delivery lag"
+	const seedSet = "watch-throughput"
+
+	fmt.Printf("\n--- Watch throughput benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	names, err := seedConfigMaps(clientset, namespace, seedSet, 1, 0)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	seeded, err := configMaps.Get(context.TODO(), names[0], metav1.GetOptions{})
+	if err != nil {
+		fmt.Printf("Error getting seeded ConfigMap: %v\n", err)
+		return
+	}
+
+	watcher, err := configMaps.Watch(context.TODO(), metav1.ListOptions{
+		FieldSelector:   "metadata.name=" + seeded.Name,
+		ResourceVersion: seeded.ResourceVersion,
+	})
+	if err != nil {
+		fmt.Printf("Error starting watch: %v\n", err)
+		return
+	}
+	defer watcher.Stop()
+
+	// Consume events concurrently, remembering when each counter value arrived
+	received := make(map[string]time.Time, events)
+	done := make(chan error, 1)
+	go func() {
+		timeout := time.After(watchEventTimeout + time.Duration(events)*time.Second)
+		for len(received) < events {
+			select {
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					done <- fmt.Errorf("watch closed after %d of %d events", len(received), events)
+					return
+				}
+				if configMap, ok := event.Object.(*corev1.ConfigMap); ok && event.Type == watch.Modified {
+					received[configMap.Data["counter"]] = time.Now()
+				}
+			case <-timeout:
+				done <- fmt.Errorf("timed out after receiving %d of %d events", len(received), events)
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	fmt.Printf("Generating %d updates...\n", events)
+	committed := make(map[string]time.Time, events)
+	startTime := time.Now()
+	for i := 0; i < events; i++ {
+		counter := fmt.Sprintf("%d", i)
+		patch := []byte(fmt.Sprintf(`{"data":{"counter":%q}}`, counter))
+		_, err := configMaps.Patch(context.TODO(), seeded.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			results.AddError(lagName, err, time.Now())
+			continue
+		}
+		committed[counter] = time.Now()
+	}
+
+	if err := <-done; err != nil {
+		fmt.Printf("Error: %v\n", err)
+		results.AddError(lagName, err, time.Now())
+	}
+
+	var lastReceived time.Time
+	for counter, receivedTime := range received {
+		if commitTime, ok := committed[counter]; ok {
+			results.Add(lagName, max(receivedTime.Sub(commitTime), 0))
+		}
+		if receivedTime.After(lastReceived) {
+			lastReceived = receivedTime
+		}
+	}
+
+	if len(received) > 0 {
+		elapsed := lastReceived.Sub(startTime)
+		throughput := float64(len(received)) / elapsed.Seconds()
+		fmt.Printf("Delivered %d events in %v (%.1f events/s)\n", len(received), elapsed, throughput)
+		results.SetMetric("watch event throughput (events/s)", throughput)
+	}
+}
+
+// watchersPerConnection is the number of watches sharing one client connection in the
+// many-watchers benchmark, staying below the apiserver's HTTP/2 concurrent stream limit
+const watchersPerConnection = 100
+
+// newDedicatedClientset creates a clientset that does not share its connection with any other
+// client. client-go caches transports by configuration, unless a custom dialer is set.
+func newDedicatedClientset(config *rest.Config) (*kubernetes.Clientset, error) {
+	dedicated := rest.CopyConfig(config)
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	dedicated.Dial = dialer.DialContext
+	return kubernetes.NewForConfig(dedicated)
+}
+
+// benchmarkManyWatchers opens the given number of concurrent watches on the seeded ConfigMaps,
+// creates one ConfigMap per iteration and measures the time from Create() returning until each
+// watch delivers the ADDED event, simulating fleets of controllers watching the same resource
+func benchmarkManyWatchers(config *rest.Config, clientset *kubernetes.Clientset, namespace string, watchers, iterations int, results *BenchmarkResults) {
+	name := fmt.Sprintf("watch event latency (%d watchers)", watchers)
+	slowestName := fmt.Sprintf("watch event latency (%d watchers, slowest watcher)", watchers)
+	const seedSet = "many-watchers"
+
+	fmt.Printf("\n--- Many-watchers benchmark in namespace %s ---\n", namespace)
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	defer func() {
+		if err := deleteSeededConfigMaps(clientset, namespace, seedSet); err != nil {
+			fmt.Printf("Error deleting seeded ConfigMaps: %v\n", err)
+		}
+	}()
+
+	list, err := configMaps.List(context.TODO(), metav1.ListOptions{LabelSelector: seedSelector(seedSet)})
+	if err != nil {
+		fmt.Printf("Error listing ConfigMaps: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Opening %d watches...\n", watchers)
+	arrivals := make(chan string, watchers)
+	// Closing done releases the watchers blocked on arrivals once the benchmark returns
+	done := make(chan struct{})
+	defer close(done)
+	var watchClient *kubernetes.Clientset
+	for i := 0; i < watchers; i++ {
+		if i%watchersPerConnection == 0 {
+			watchClient, err = newDedicatedClientset(config)
+			if err != nil {
+				fmt.Printf("Error creating watch client: %v\n", err)
+				return
+			}
+		}
+
+		watcher, err := watchClient.CoreV1().ConfigMaps(namespace).Watch(context.TODO(), metav1.ListOptions{
+			LabelSelector:   seedSelector(seedSet),
+			ResourceVersion: list.ResourceVersion,
+		})
+		if err != nil {
+			fmt.Printf("Error starting watch %d: %v\n", i+1, err)
+			return
+		}
+		defer watcher.Stop()
+
+		go func() {
+			for event := range watcher.ResultChan() {
+				if object, ok := event.Object.(metav1.Object); ok && event.Type == watch.Added {
+					select {
+					case arrivals <- object.GetName():
+					case <-done:
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < iterations; i++ {
+		if results.skipIteration(slowestName) {
+			continue
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("k8s-api-bench-%s-%d", seedSet, i),
+				Labels: seedLabels(seedSet),
+			},
+		}
+
+		startTime := time.Now()
+		if _, err := configMaps.Create(context.TODO(), configMap, metav1.CreateOptions{}); err != nil {
+			recordIteration(slowestName, i+1, iterations, startTime, 0, err, results)
+			results.recordOutcome(slowestName, err)
+			continue
+		}
+		createdTime := time.Now()
+
+		// Collect the arrival of the event on every watch
+		var slowest time.Duration
+		var err error
+		timeout := time.After(watchEventTimeout)
+		for received := 0; received < watchers && err == nil; {
+			select {
+			case arrived := <-arrivals:
+				if arrived != configMap.Name {
+					continue
+				}
+				latency := time.Since(createdTime)
+				results.Add(name, latency)
+				slowest = max(slowest, latency)
+				received++
+			case <-timeout:
+				err = fmt.Errorf("timed out after %v with %d of %d watches notified", watchEventTimeout, received, watchers)
+			}
+		}
+		recordIteration(slowestName, i+1, iterations, createdTime, slowest, err, results)
+		results.recordOutcome(slowestName, err)
+	}
+}
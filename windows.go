@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// statsWindow is the length of the time windows percentiles are additionally calculated for, 0
+// to only calculate run-wide statistics
+var statsWindow time.Duration
+
+// WindowStats are the statistics of an operation over the iterations started in one time window
+type WindowStats struct {
+	Start    time.Time `json:"start"`
+	Count    int       `json:"count"`
+	MedianMs float64   `json:"median_ms"`
+	P95Ms    float64   `json:"p95_ms"`
+	MaxMs    float64   `json:"max_ms"`
+}
+
+// windowHistograms buckets the timeline points of every operation started between from and to
+// into windows of the given length aligned to from
+func (br *BenchmarkResults) windowHistograms(from, to time.Time, window time.Duration) map[string]map[int]*Histogram {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	windows := make(map[string]map[int]*Histogram)
+	for operation, points := range br.Timeline {
+		for _, point := range points {
+			if point.Timestamp.Before(from) || !point.Timestamp.Before(to) {
+				continue
+			}
+			index := int(point.Timestamp.Sub(from) / window)
+			if windows[operation] == nil {
+				windows[operation] = make(map[int]*Histogram)
+			}
+			if windows[operation][index] == nil {
+				windows[operation][index] = NewHistogram()
+			}
+			windows[operation][index].Record(time.Duration(point.DurationMs * float64(time.Millisecond)))
+		}
+	}
+	return windows
+}
+
+// newWindowStats summarizes the histogram of the window starting at start
+func newWindowStats(start time.Time, histogram *Histogram) WindowStats {
+	stats := histogram.Stats()
+	return WindowStats{
+		Start:    start,
+		Count:    int(histogram.Total),
+		MedianMs: durationMs(stats["median"]),
+		P95Ms:    durationMs(stats["p95"]),
+		MaxMs:    durationMs(stats["max"]),
+	}
+}
+
+// WindowedStats calculates, per operation, the statistics of every window of the given length
+// since start in which iterations of the operation started, in time order
+func (br *BenchmarkResults) WindowedStats(start time.Time, window time.Duration) map[string][]WindowStats {
+	windowed := make(map[string][]WindowStats)
+	for operation, windows := range br.windowHistograms(start, time.Now().Add(window), window) {
+		for _, index := range sortedIndexes(windows) {
+			windowStart := start.Add(time.Duration(index) * window)
+			windowed[operation] = append(windowed[operation], newWindowStats(windowStart, windows[index]))
+		}
+	}
+	return windowed
+}
+
+// sortedIndexes returns the keys of a window map in ascending order
+func sortedIndexes(windows map[int]*Histogram) []int {
+	indexes := make([]int, 0, len(windows))
+	for index := range windows {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// printWindowsPeriodically prints the statistics of the window that just ended every window
+// until stop is closed, so degradation partway through a long run shows while it happens
+func printWindowsPeriodically(window time.Duration, br *BenchmarkResults, stop <-chan struct{}) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case end := <-ticker.C:
+			start := end.Add(-window)
+			windows := br.windowHistograms(start, end, window)
+			if len(windows) == 0 {
+				continue
+			}
+
+			fmt.Printf("\n--- Window %s - %s ---\n", start.Format("15:04:05"), end.Format("15:04:05"))
+			table := NewTable("Operation", "Count", "Median", "P95", "Max")
+			for _, operation := range sortedKeys(windows) {
+				stats := newWindowStats(start, windows[operation][0])
+				table.AddRow(operation, fmt.Sprintf("%d", stats.Count), formatMs(stats.MedianMs),
+					formatMs(stats.P95Ms), formatMs(stats.MaxMs))
+			}
+			table.Render(os.Stdout)
+		case <-stop:
+			return
+		}
+	}
+}